@@ -0,0 +1,123 @@
+// Command catalog exports and imports a store's menu as CSV or JSON, the
+// same files the /api/stores/:id/catalog endpoints take.
+//
+//	catalog export -store 1 -format csv -out menu.csv
+//	catalog import -store 1 -dry-run menu.csv
+package main
+
+import (
+	"coffee/internal/config"
+	"coffee/internal/delivery/events"
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	mongov1 "coffee/internal/repositories/mongo/v1"
+	v1 "coffee/internal/repositories/postgres/v1"
+	redisv1 "coffee/internal/repositories/redis/v1"
+	"coffee/internal/services"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cli is who the audit log shows behind changes made from here.
+var cli = &model.Auth{Id: "catalog-cli", Role: entity.RoleAdmin}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	flags := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	storeID := flags.Int("store", 0, "store id")
+	format := flags.String("format", "", "csv or json, by default from the file name")
+	out := flags.String("out", "", "file to export to, stdout by default")
+	dryRun := flags.Bool("dry-run", false, "check the file and report what would change")
+	flags.Parse(os.Args[2:])
+
+	viper := config.NewViper()
+	log := config.NewLogger(viper)
+	db := config.NewDB(viper, log)
+	mongo := config.NewMongo(viper, log)
+	redis := config.NewRedis(viper)
+	defer func() {
+		db.Close()
+		mongo.Disconnect(context.TODO())
+		redis.Close()
+	}()
+
+	validate := config.NewValidator()
+	stream := redisv1.NewEventStream(redis, viper.GetString("events.stream"), viper.GetInt64("events.stream_max_len"), log)
+	bus := events.NewStreamBus(events.NewBus(1, log), stream, viper, log)
+	audit := services.NewAuditService(mongov1.NewAuditRepo(mongo.Database(viper.GetString("db.mongo.database")), log), validate, log)
+	catalog := services.NewCatalogService(v1.NewCatalogRepo(db, log), v1.NewStoreRepo(db, log), bus, audit, validate, log)
+
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "export":
+		w := io.Writer(os.Stdout)
+		if *out != "" {
+			file, err := os.Create(*out)
+			if err != nil {
+				fail(err)
+			}
+			defer file.Close()
+			w = file
+		}
+
+		request := &model.ExportCatalogRequest{Format: formatOf(*format, *out)}
+		if err := catalog.Export(ctx, cli, *storeID, request, w); err != nil {
+			fail(err)
+		}
+	case "import":
+		if flags.NArg() != 1 {
+			usage()
+		}
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fail(err)
+		}
+		defer file.Close()
+
+		request := &model.ImportCatalogRequest{Format: formatOf(*format, flags.Arg(0)), DryRun: *dryRun}
+		result, err := catalog.Import(ctx, cli, *storeID, request, file)
+		if err != nil {
+			fail(err)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(result)
+		if len(result.Errors) > 0 {
+			os.Exit(1)
+		}
+	default:
+		usage()
+	}
+}
+
+// formatOf is the format asked for, else the one the file name ends in.
+func formatOf(format, name string) string {
+	if format != "" {
+		return format
+	}
+	if strings.EqualFold(filepath.Ext(name), ".csv") {
+		return "csv"
+	}
+	return "json"
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: catalog export -store ID [-format csv|json] [-out FILE]")
+	fmt.Fprintln(os.Stderr, "       catalog import -store ID [-format csv|json] [-dry-run] FILE")
+	os.Exit(2)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
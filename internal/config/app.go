@@ -1,9 +1,22 @@
 package config
 
 import (
+	"coffee/internal/delivery/events"
+	"coffee/internal/delivery/outbox"
+	"coffee/internal/delivery/printing"
+	"coffee/internal/delivery/rest/handler"
 	"coffee/internal/delivery/rest/middleware"
 	"coffee/internal/delivery/rest/route"
+	"coffee/internal/delivery/scheduler"
+	"coffee/internal/delivery/webhook"
+	"coffee/internal/entity"
+	localv1 "coffee/internal/repositories/local/v1"
+	mongov1 "coffee/internal/repositories/mongo/v1"
+	v1 "coffee/internal/repositories/postgres/v1"
+	redisv1 "coffee/internal/repositories/redis/v1"
+	"coffee/internal/services"
 	"coffee/internal/utils"
+	"context"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/jmoiron/sqlx"
@@ -23,7 +36,83 @@ type BoostrapConfig struct {
 }
 
 func Boostrap(config *BoostrapConfig) {
+	validate := NewValidator()
 	tokenUtil := utils.NewTokenUtil(config.Viper, config.Redis)
+	reporting := config.Mongo.Database(config.Viper.GetString("db.mongo.database"))
+
+	reportRepo := v1.NewReportRepo(config.DB, config.Log)
+	orderRepo := v1.NewOrderRepo(config.DB, config.Log)
+	paymentRepo := v1.NewPaymentRepo(config.DB, config.Log)
+	storeRepo := v1.NewStoreRepo(config.DB, config.Log)
+	shiftRepo := v1.NewShiftRepo(config.DB, config.Log)
+	userRepo := v1.NewUserRepo(config.DB, config.Log)
+	scheduleRepo := v1.NewScheduleRepo(config.DB, config.Log)
+	timeEntryRepo := v1.NewTimeEntryRepo(config.DB, config.Log)
+	menuRepo := v1.NewMenuRepo(config.DB, config.Log)
+	customerRepo := v1.NewCustomerRepo(config.DB, config.Log)
+	stampRepo := v1.NewStampRepo(config.DB, config.Log)
+	pickupRepo := v1.NewPickupRepo(config.DB, config.Log)
+	stationRepo := v1.NewStationRepo(config.DB, config.Log)
+	printerRepo := v1.NewPrinterRepo(config.DB, config.Log)
+	webhookRepo := v1.NewWebhookRepo(config.DB, config.Log)
+	outboxRepo := v1.NewOutboxRepo(config.DB, config.Log)
+	catalogRepo := v1.NewCatalogRepo(config.DB, config.Log)
+	categoryRepo := v1.NewCategoryRepo(config.DB, config.Log)
+	fileStorage := localv1.NewFileStorage(config.Viper.GetString("media.root"), config.Log)
+	cartRepo := redisv1.NewCartRepo(config.Redis, config.Viper.GetDuration("guest.cart_ttl"), config.Log)
+	printQueue := redisv1.NewPrintQueue(config.Redis, config.Log)
+	eventStream := redisv1.NewEventStream(config.Redis, config.Viper.GetString("events.stream"), config.Viper.GetInt64("events.stream_max_len"), config.Log)
+	projectionRepo := mongov1.NewOrderProjectionRepo(reporting, config.Log)
+	auditRepo := mongov1.NewAuditRepo(reporting, config.Log)
+
+	eventBus := events.NewStreamBus(events.NewBus(config.Viper.GetInt("events.async_workers"), config.Log), eventStream, config.Viper, config.Log)
+
+	auditService := services.NewAuditService(auditRepo, validate, config.Log)
+	projectionService := services.NewProjectionService(orderRepo, paymentRepo, storeRepo, projectionRepo, validate, config.Viper, config.Log)
+	reportService := services.NewReportService(reportRepo, projectionRepo, validate, config.Viper, config.Log)
+	loyaltyService := services.NewLoyaltyService(customerRepo, config.Viper, config.Log)
+	stampService := services.NewStampService(stampRepo, orderRepo, auditService, validate, config.Log)
+	customerService := services.NewCustomerService(customerRepo, loyaltyService, stampService, validate, config.Log)
+	storeHoursService := services.NewStoreHoursService(storeRepo, auditService, validate, config.Viper, config.Log)
+	webhookService := services.NewWebhookService(webhookRepo, auditService, validate, config.Log)
+	printerService := services.NewPrinterService(printerRepo, printQueue, stationRepo, auditService, validate, config.Log)
+	pickupService := services.NewPickupService(pickupRepo, orderRepo, storeRepo, storeHoursService, outboxRepo, auditService, validate, config.Viper, config.Log)
+	priceService := services.NewPriceService(menuRepo, eventBus, auditService, validate, config.Log)
+	categoryService := services.NewCategoryService(categoryRepo, storeRepo, eventBus, auditService, validate, config.Log)
+	catalogService := services.NewCatalogService(catalogRepo, storeRepo, eventBus, auditService, validate, config.Log)
+	imageService := services.NewImageService(menuRepo, fileStorage, eventBus, auditService, config.Viper, config.Log)
+	menuService := services.NewMenuService(menuRepo, storeRepo, categoryRepo, storeHoursService, eventBus, auditService, validate, config.Log)
+	orderService := services.NewOrderService(orderRepo, menuRepo, menuService, storeRepo, stationRepo, storeHoursService, pickupService, loyaltyService, stampService, projectionService, outboxRepo, validate, config.Viper, config.Log)
+	stationService := services.NewStationService(stationRepo, orderRepo, auditService, validate, config.Log)
+	receiptService := services.NewReceiptService(orderRepo, paymentRepo, storeRepo, stationRepo, storeHoursService, config.Viper, config.Log)
+	cartService := services.NewCartService(cartRepo, storeRepo, orderService, validate, config.Log)
+	shiftService := services.NewShiftService(shiftRepo, validate, config.Log)
+	paymentService := services.NewPaymentService(paymentRepo, orderRepo, shiftRepo, outboxRepo, validate, config.Log)
+	scheduleService := services.NewScheduleService(scheduleRepo, timeEntryRepo, userRepo, validate, config.Viper, config.Log)
+	timeClockService := services.NewTimeClockService(storeRepo, userRepo, scheduleRepo, timeEntryRepo, validate, config.Log)
+
+	reportHandler := handler.NewReportHandler(reportService, config.Log)
+	orderHandler := handler.NewOrderHandler(orderService, config.Log)
+	projectionHandler := handler.NewProjectionHandler(projectionService, config.Log)
+	shiftHandler := handler.NewShiftHandler(shiftService, config.Log)
+	paymentHandler := handler.NewPaymentHandler(paymentService, config.Log)
+	scheduleHandler := handler.NewScheduleHandler(scheduleService, config.Log)
+	timeClockHandler := handler.NewTimeClockHandler(timeClockService, config.Log)
+	customerHandler := handler.NewCustomerHandler(customerService, config.Log)
+	stampHandler := handler.NewStampHandler(stampService, config.Log)
+	menuHandler := handler.NewMenuHandler(menuService, config.Log)
+	cartHandler := handler.NewCartHandler(cartService, config.Log)
+	pickupHandler := handler.NewPickupHandler(pickupService, config.Log)
+	storeHoursHandler := handler.NewStoreHoursHandler(storeHoursService, config.Log)
+	stationHandler := handler.NewStationHandler(stationService, config.Log)
+	receiptHandler := handler.NewReceiptHandler(receiptService, config.Log)
+	printerHandler := handler.NewPrinterHandler(printerService, config.Log)
+	webhookHandler := handler.NewWebhookHandler(webhookService, config.Log)
+	auditHandler := handler.NewAuditHandler(auditService, config.Log)
+	priceHandler := handler.NewPriceHandler(priceService, config.Log)
+	catalogHandler := handler.NewCatalogHandler(catalogService, config.Log)
+	categoryHandler := handler.NewCategoryHandler(categoryService, config.Log)
+	imageHandler := handler.NewImageHandler(imageService, config.Log)
 
 	authMiddleware := middleware.NewAuthMiddleware(tokenUtil)
 
@@ -31,7 +120,52 @@ func Boostrap(config *BoostrapConfig) {
 		Viper: config.Viper,
 		App: config.App,
 		AuthMiddleware: authMiddleware,
+		ReportHandler: reportHandler,
+		OrderHandler: orderHandler,
+		ProjectionHandler: projectionHandler,
+		ShiftHandler: shiftHandler,
+		PaymentHandler: paymentHandler,
+		ScheduleHandler: scheduleHandler,
+		TimeClockHandler: timeClockHandler,
+		CustomerHandler: customerHandler,
+		StampHandler: stampHandler,
+		MenuHandler: menuHandler,
+		CartHandler: cartHandler,
+		PickupHandler: pickupHandler,
+		StoreHoursHandler: storeHoursHandler,
+		StationHandler: stationHandler,
+		ReceiptHandler: receiptHandler,
+		PrinterHandler: printerHandler,
+		WebhookHandler: webhookHandler,
+		AuditHandler: auditHandler,
+		PriceHandler: priceHandler,
+		CatalogHandler: catalogHandler,
+		CategoryHandler: categoryHandler,
+		ImageHandler: imageHandler,
 	}
 
 	router.Setup()
-}
\ No newline at end of file
+
+	eventBus.Subscribe(entity.EventOrderCreated, printerService.OrderPlaced)
+	eventBus.Subscribe(entity.EventOrderStatusChanged, printerService.OrderStatusChanged)
+	eventBus.Subscribe(entity.EventOrderCreated, webhookService.Publish)
+	eventBus.Subscribe(entity.EventOrderStatusChanged, webhookService.Publish)
+	eventBus.Subscribe(entity.EventPaymentCaptured, webhookService.Publish)
+	// Every process, prefork children too, takes its share of the events.
+	eventBus.Start(context.Background())
+
+	// With prefork every child would run the jobs too; only the parent does.
+	if !fiber.IsChild() {
+		relay := outbox.NewRelay(outboxRepo, eventStream, config.Viper, config.Log)
+		printWorker := printing.NewWorker(printerRepo, printQueue, receiptService, config.Viper, config.Log)
+		scheduler.Start(context.Background(), config.Log,
+			scheduler.Job{Name: "release-scheduled-orders", Interval: config.Viper.GetDuration("pickup.release_interval"), Run: pickupService.Release},
+			scheduler.Job{Name: "apply-scheduled-prices", Interval: config.Viper.GetDuration("prices.apply_interval"), Run: priceService.ApplyDue},
+			scheduler.Job{Name: "deliver-webhooks", Interval: config.Viper.GetDuration("webhooks.interval"), Run: webhook.NewDispatcher(webhookRepo, config.Viper, config.Log).Run},
+			scheduler.Job{Name: "relay-outbox", Interval: config.Viper.GetDuration("outbox.interval"), Run: relay.Run},
+			scheduler.Job{Name: "purge-outbox", Interval: config.Viper.GetDuration("outbox.purge_interval"), Run: relay.Purge},
+			scheduler.Job{Name: "requeue-print-jobs", Interval: config.Viper.GetDuration("printing.requeue_interval"), Run: printWorker.Requeue},
+		)
+		printWorker.Start(context.Background())
+	}
+}
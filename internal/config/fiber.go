@@ -1,6 +1,8 @@
 package config
 
 import (
+	"coffee/internal/model/apperrors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/spf13/viper"
 )
@@ -10,6 +12,7 @@ func NewFiber(config *viper.Viper) *fiber.App {
 		AppName: config.GetString("app.name"),
 		ErrorHandler: NewErrorHandler(),
 		Prefork: config.GetBool("web.prefork"),
+		BodyLimit: config.GetInt("web.body_limit"),
 	})
 
 	return app
@@ -22,6 +25,13 @@ func NewErrorHandler() fiber.ErrorHandler {
 			code = e.Code
 		}
 
+		if e, ok := err.(*apperrors.Apperrors); ok {
+			return ctx.Status(e.Code).JSON(fiber.Map{
+				"errors": e.Message,
+				"fields": e.Errors,
+			})
+		}
+
 		return ctx.Status(code).JSON(fiber.Map{
 			"errors": err.Error(),
 		})
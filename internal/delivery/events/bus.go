@@ -0,0 +1,92 @@
+// Package events delivers domain events to the modules that subscribe to
+// them. Bus dispatches within the process; StreamBus carries events through
+// the Redis event stream so every process, prefork children included, takes
+// its share.
+package events
+
+import (
+	"coffee/internal/model"
+	"coffee/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Bus is the in-process event bus. Publish dispatches straight away, which is
+// enough when a single process both publishes and subscribes.
+type Bus struct {
+	Log *logrus.Logger
+
+	mu         sync.RWMutex
+	handlers   map[string][]model.EventHandler
+	background map[string][]model.EventHandler
+	slots      chan struct{} // bounds the async handlers running at once
+}
+
+func NewBus(workers int, log *logrus.Logger) *Bus {
+	return &Bus{
+		Log:        log,
+		handlers:   map[string][]model.EventHandler{},
+		background: map[string][]model.EventHandler{},
+		slots:      make(chan struct{}, max(workers, 1)),
+	}
+}
+
+func (b *Bus) Subscribe(name string, handler model.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+func (b *Bus) SubscribeAsync(name string, handler model.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.background[name] = append(b.background[name], handler)
+}
+
+func (b *Bus) Publish(ctx context.Context, storeID int, data model.DomainEvent) error {
+	id, err := utils.RandomToken(16)
+	if err != nil {
+		return err
+	}
+
+	return b.Dispatch(ctx, &model.Event{ID: id, StoreID: storeID, OccurredAt: time.Now(), Data: data})
+}
+
+// Dispatch runs the synchronous subscribers of the event one after another,
+// then starts the asynchronous ones. It returns what the synchronous ones
+// failed with.
+func (b *Bus) Dispatch(ctx context.Context, event *model.Event) error {
+	b.mu.RLock()
+	handlers, background := b.handlers[event.Name()], b.background[event.Name()]
+	b.mu.RUnlock()
+
+	var failed []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			failed = append(failed, err)
+		}
+	}
+
+	// The publisher's request may be over long before these are.
+	detached := context.WithoutCancel(ctx)
+	for _, handler := range background {
+		b.slots <- struct{}{}
+		go func(handler model.EventHandler) {
+			defer func() { <-b.slots }()
+			if err := handler(detached, event); err != nil {
+				b.Log.Warnf("async subscriber of %s event %s failed: %v", event.Name(), event.ID, err)
+			}
+		}(handler)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%s event %s: %w", event.Name(), event.ID, errors.Join(failed...))
+	}
+
+	return nil
+}
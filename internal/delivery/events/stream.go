@@ -0,0 +1,175 @@
+package events
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// StreamBus publishes events to the Redis event stream and dispatches what it
+// reads from the stream to the subscribers of its Bus. Processes reading with
+// the same group share the events, so each event is handled once however
+// many processes run; a process with a group of its own sees every event.
+//
+// An event is acked once its synchronous subscribers succeed. One left
+// unacked, because a subscriber failed or the process died, is taken over
+// by a reader after ClaimAfter and dispatched again, up to MaxDeliveries
+// times; after that it is moved to the dead-letter stream.
+//
+// The outbox publishes the events of an order in order, but readers share
+// the group and a retried event is taken over long after the ones behind it
+// were handled. Subscribers must not rely on seeing the events of an order
+// in the order they happened.
+type StreamBus struct {
+	*Bus
+	Stream        model.EventStream
+	Log           *logrus.Logger
+	Group         string
+	Consumer      string
+	Batch         int
+	Block         time.Duration
+	ClaimAfter    time.Duration
+	MaxDeliveries int64 // dispatches before an event is given up on
+}
+
+func NewStreamBus(bus *Bus, stream model.EventStream, viper *viper.Viper, log *logrus.Logger) *StreamBus {
+	host, _ := os.Hostname()
+	streamBus := &StreamBus{
+		Bus:           bus,
+		Stream:        stream,
+		Log:           log,
+		Group:         viper.GetString("events.group"),
+		Consumer:      fmt.Sprintf("%s-%d", host, os.Getpid()),
+		Batch:         max(viper.GetInt("events.batch"), 1),
+		Block:         viper.GetDuration("events.block"),
+		ClaimAfter:    viper.GetDuration("events.claim_after"),
+		MaxDeliveries: viper.GetInt64("events.max_deliveries"),
+	}
+	if streamBus.Group == "" {
+		streamBus.Group = "coffee"
+	}
+	if streamBus.Block <= 0 {
+		streamBus.Block = 2 * time.Second
+	}
+	if streamBus.ClaimAfter <= 0 {
+		streamBus.ClaimAfter = time.Minute
+	}
+	if streamBus.MaxDeliveries <= 0 {
+		streamBus.MaxDeliveries = 5
+	}
+
+	return streamBus
+}
+
+// Publish appends the event to the stream; it reaches the subscribers when a
+// reader takes it. Events about an order are better written to the outbox
+// with the order, which this can not guarantee.
+func (b *StreamBus) Publish(ctx context.Context, storeID int, data model.DomainEvent) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return b.Stream.Publish(ctx, &entity.OutboxMessage{
+		Event:     data.EventName(),
+		StoreID:   storeID,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	})
+}
+
+// Start reads the stream until ctx is done.
+func (b *StreamBus) Start(ctx context.Context) {
+	go b.run(ctx)
+}
+
+func (b *StreamBus) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := b.Stream.CreateGroup(ctx, b.Group); err != nil {
+			b.Log.Warnf("failed to create event group %s: %v", b.Group, err)
+			time.Sleep(b.Block)
+			continue
+		}
+		break
+	}
+
+	lastClaim := time.Now()
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= b.ClaimAfter/2 {
+			lastClaim = time.Now()
+			stale, err := b.Stream.Claim(ctx, b.Group, b.Consumer, b.ClaimAfter, b.Batch)
+			if err != nil && ctx.Err() == nil {
+				b.Log.Warnf("failed to claim stale events: %v", err)
+			}
+			b.dispatch(ctx, stale)
+		}
+
+		events, err := b.Stream.Read(ctx, b.Group, b.Consumer, b.Batch, b.Block)
+		if err != nil {
+			if ctx.Err() == nil {
+				b.Log.Warnf("failed to read events: %v", err)
+				time.Sleep(b.Block)
+			}
+			continue
+		}
+		b.dispatch(ctx, events)
+	}
+}
+
+func (b *StreamBus) dispatch(ctx context.Context, events []model.StreamEvent) {
+	for _, streamEvent := range events {
+		if streamEvent.Deliveries > b.MaxDeliveries {
+			b.deadLetter(ctx, streamEvent, fmt.Sprintf("not handled after %d deliveries", b.MaxDeliveries))
+			continue
+		}
+
+		event, err := decode(streamEvent)
+		if err != nil {
+			// It will not decode any better next time.
+			b.Log.Warnf("dropping event %s: %v", streamEvent.StreamID, err)
+		} else if err := b.Dispatch(ctx, event); err != nil {
+			b.Log.Warn(err)
+			continue
+		}
+
+		if err := b.Stream.Ack(ctx, b.Group, streamEvent.StreamID); err != nil {
+			b.Log.Warnf("failed to ack event %s: %v", streamEvent.StreamID, err)
+		}
+	}
+}
+
+// deadLetter moves the event off the group's pending list. It stays pending
+// if the dead-letter stream can not take it, and comes back on the next claim.
+func (b *StreamBus) deadLetter(ctx context.Context, streamEvent model.StreamEvent, reason string) {
+	b.Log.Warnf("giving up on event %s (%s): %s", streamEvent.StreamID, streamEvent.Name, reason)
+	if err := b.Stream.DeadLetter(ctx, streamEvent, reason); err != nil {
+		b.Log.Warnf("failed to dead-letter event %s: %v", streamEvent.StreamID, err)
+		return
+	}
+	if err := b.Stream.Ack(ctx, b.Group, streamEvent.StreamID); err != nil {
+		b.Log.Warnf("failed to ack event %s: %v", streamEvent.StreamID, err)
+	}
+}
+
+// decode rebuilds the event. Events from the outbox are known by their outbox
+// id, which the relay keeps when it publishes one twice.
+func decode(streamEvent model.StreamEvent) (*model.Event, error) {
+	data, err := model.DecodeEvent(streamEvent.Name, streamEvent.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	id := streamEvent.StreamID
+	if streamEvent.OutboxID != "" {
+		id = "outbox-" + streamEvent.OutboxID
+	}
+
+	return &model.Event{ID: id, StoreID: streamEvent.StoreID, OccurredAt: streamEvent.CreatedAt, Data: data}, nil
+}
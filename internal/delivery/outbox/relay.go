@@ -0,0 +1,69 @@
+// Package outbox relays events committed to the Postgres outbox to the Redis
+// event stream. Delivery is at least once: a message may be published twice
+// if the relay dies before recording it, never zero times. Consumers dedupe
+// by outbox_id.
+package outbox
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type Relay struct {
+	Outbox    model.OutboxRepository
+	Stream    model.EventStream
+	Log       *logrus.Logger
+	Batch     int
+	Retention time.Duration // how long sent messages are kept
+}
+
+func NewRelay(outbox model.OutboxRepository, stream model.EventStream, viper *viper.Viper, log *logrus.Logger) *Relay {
+	relay := &Relay{
+		Outbox:    outbox,
+		Stream:    stream,
+		Log:       log,
+		Batch:     max(viper.GetInt("outbox.batch"), 1),
+		Retention: viper.GetDuration("outbox.retention"),
+	}
+	if relay.Retention <= 0 {
+		relay.Retention = 72 * time.Hour
+	}
+
+	return relay
+}
+
+// Run publishes pending messages, a batch at a time, until none are left. It
+// is meant to be run by the scheduler.
+func (r *Relay) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		sent, err := r.Outbox.Relay(ctx, r.Batch, func(message *entity.OutboxMessage) error {
+			return r.Stream.Publish(ctx, message)
+		})
+		if err != nil {
+			return err
+		}
+		if sent < r.Batch {
+			return nil
+		}
+	}
+
+	return ctx.Err()
+}
+
+// Purge deletes messages sent longer ago than the retention.
+func (r *Relay) Purge(ctx context.Context) error {
+	purged, err := r.Outbox.PurgeSent(ctx, time.Now().Add(-r.Retention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		r.Log.Infof("purged %d sent outbox messages", purged)
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+// Package printing sends queued print jobs to network printers. Jobs are
+// rendered when they are printed, so a reprint shows the order as it is now.
+package printing
+
+import (
+	"coffee/internal/delivery/receipt"
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// popTimeout bounds how long a worker waits on the queue, so due retries are
+// promoted and shutdown is noticed.
+const popTimeout = 2 * time.Second
+
+type Worker struct {
+	Printers    model.PrinterRepository
+	Queue       model.PrintQueue
+	Receipts    model.ReceiptService
+	Log         *logrus.Logger
+	Workers     int
+	MaxAttempts int
+	RetryAfter  time.Duration // doubled after every failed attempt
+	Timeout     time.Duration // to connect and to write
+	StaleAfter  time.Duration // before a queued or printing job is pushed again
+}
+
+func NewWorker(printers model.PrinterRepository, queue model.PrintQueue, receipts model.ReceiptService, viper *viper.Viper, log *logrus.Logger) *Worker {
+	worker := &Worker{
+		Printers:    printers,
+		Queue:       queue,
+		Receipts:    receipts,
+		Log:         log,
+		Workers:     max(viper.GetInt("printing.workers"), 1),
+		MaxAttempts: max(viper.GetInt("printing.max_attempts"), 1),
+		RetryAfter:  viper.GetDuration("printing.retry_after"),
+		Timeout:     viper.GetDuration("printing.timeout"),
+		StaleAfter:  viper.GetDuration("printing.stale_after"),
+	}
+	if worker.RetryAfter <= 0 {
+		worker.RetryAfter = 10 * time.Second
+	}
+	if worker.Timeout <= 0 {
+		worker.Timeout = 5 * time.Second
+	}
+	if worker.StaleAfter <= 0 {
+		worker.StaleAfter = 5 * time.Minute
+	}
+
+	return worker
+}
+
+// Start runs the workers until ctx is done.
+func (w *Worker) Start(ctx context.Context) {
+	for i := 0; i < w.Workers; i++ {
+		go w.run(ctx)
+	}
+}
+
+func (w *Worker) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := w.Queue.PromoteDue(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			w.Log.Warnf("failed to promote print retries: %v", err)
+		}
+
+		jobID, err := w.Queue.Pop(ctx, popTimeout)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.Log.Warnf("failed to take a print job: %v", err)
+				time.Sleep(popTimeout)
+			}
+			continue
+		}
+		if jobID != 0 {
+			w.Process(ctx, jobID)
+		}
+	}
+}
+
+// Requeue pushes jobs stuck in queued or printing for longer than StaleAfter
+// back onto the queue. It runs on a timer.
+func (w *Worker) Requeue(ctx context.Context) error {
+	ids, err := w.Printers.RequeueStale(ctx, time.Now().Add(-w.StaleAfter))
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		w.Log.Infof("requeued %d stale print jobs: %v", len(ids), ids)
+	}
+	for _, id := range ids {
+		if err := w.Queue.Push(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Process makes one attempt at a job. A failed attempt is retried later
+// until the attempts run out, then the job is marked failed.
+func (w *Worker) Process(ctx context.Context, jobID int) {
+	job, err := w.Printers.FindJob(ctx, jobID)
+	if err != nil {
+		w.Log.Warnf("print job %d: %v", jobID, err)
+		return
+	}
+	started, err := w.Printers.StartJob(ctx, job)
+	if err != nil || !started {
+		return
+	}
+
+	job.Status = entity.PrintPrinted
+	job.LastError = ""
+	if err := w.print(ctx, job); err != nil {
+		job.LastError = err.Error()
+		job.Status = entity.PrintFailed
+		if job.Attempts < w.MaxAttempts {
+			job.Status = entity.PrintQueued
+		}
+	}
+
+	if err := w.Printers.FinishJob(ctx, job); err != nil {
+		w.Log.Warnf("failed to record print job %d: %v", job.ID, err)
+		return
+	}
+
+	switch job.Status {
+	case entity.PrintQueued:
+		delay := w.RetryAfter << (job.Attempts - 1)
+		if err := w.Queue.Retry(ctx, job.ID, time.Now().Add(delay)); err != nil {
+			w.Log.Warnf("failed to schedule retry of print job %d: %v", job.ID, err)
+		}
+	case entity.PrintFailed:
+		w.Log.Warnf("print job %d failed after %d attempts: %s", job.ID, job.Attempts, job.LastError)
+	}
+}
+
+func (w *Worker) print(ctx context.Context, job *entity.PrintJob) error {
+	printer, err := w.Printers.FindPrinter(ctx, job.PrinterID)
+	if err != nil {
+		return err
+	}
+	if !printer.IsActive {
+		return fmt.Errorf("printer %s is not active", printer.Name)
+	}
+
+	data, err := w.render(ctx, job, printer)
+	if err != nil {
+		return err
+	}
+
+	return Send(ctx, net.JoinHostPort(printer.Host, strconv.Itoa(printer.Port)), data, w.Timeout)
+}
+
+func (w *Worker) render(ctx context.Context, job *entity.PrintJob, printer *entity.Printer) ([]byte, error) {
+	format, _ := receipt.Lookup(fmt.Sprintf("escpos%d", printer.PaperWidth))
+
+	switch job.Kind {
+	case entity.PrintReceipt:
+		document, err := w.Receipts.ForOrder(ctx, job.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		return format.Render(receipt.ForReceipt(document)), nil
+	case entity.PrintTicket:
+		if job.TicketID == nil {
+			return nil, fmt.Errorf("ticket job %d has no ticket", job.ID)
+		}
+		slip, err := w.Receipts.ForTicket(ctx, *job.TicketID)
+		if err != nil {
+			return nil, err
+		}
+		return format.Render(receipt.ForTicket(slip)), nil
+	}
+
+	return nil, fmt.Errorf("unknown print job kind %q", job.Kind)
+}
+
+// Send writes raw bytes to a printer listening on address, the way raw TCP
+// printing (port 9100) expects: connect, write, close.
+func Send(ctx context.Context, address string, data []byte, timeout time.Duration) error {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Write(data); err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
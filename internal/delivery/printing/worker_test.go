@@ -0,0 +1,273 @@
+package printing
+
+import (
+	"bytes"
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// printerStub listens like a raw TCP printer and keeps what it was sent.
+type printerStub struct {
+	listener net.Listener
+	mu       sync.Mutex
+	received [][]byte
+	done     chan struct{}
+}
+
+func newPrinterStub(t *testing.T) *printerStub {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &printerStub{listener: listener, done: make(chan struct{}, 8)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			data, _ := io.ReadAll(conn)
+			conn.Close()
+			stub.mu.Lock()
+			stub.received = append(stub.received, data)
+			stub.mu.Unlock()
+			stub.done <- struct{}{}
+		}
+	}()
+
+	return stub
+}
+
+func (p *printerStub) port() int {
+	return p.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (p *printerStub) wait(t *testing.T) []byte {
+	t.Helper()
+	select {
+	case <-p.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the printer got nothing")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.received[len(p.received)-1]
+}
+
+// closedPort is a port nothing listens on, so connecting fails right away.
+func closedPort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	return port
+}
+
+type fakePrinters struct {
+	model.PrinterRepository
+	printer  *entity.Printer
+	job      *entity.PrintJob
+	finished []entity.PrintJob
+	stale    []int
+	before   time.Time
+}
+
+func (f *fakePrinters) FindJob(ctx context.Context, id int) (*entity.PrintJob, error) {
+	job := *f.job
+	return &job, nil
+}
+
+func (f *fakePrinters) StartJob(ctx context.Context, job *entity.PrintJob) (bool, error) {
+	job.Attempts = f.job.Attempts + 1
+	job.Status = entity.PrintPrinting
+	return true, nil
+}
+
+func (f *fakePrinters) FinishJob(ctx context.Context, job *entity.PrintJob) error {
+	*f.job = *job
+	f.finished = append(f.finished, *job)
+	return nil
+}
+
+func (f *fakePrinters) RequeueStale(ctx context.Context, before time.Time) ([]int, error) {
+	f.before = before
+	return f.stale, nil
+}
+
+func (f *fakePrinters) FindPrinter(ctx context.Context, id int) (*entity.Printer, error) {
+	return f.printer, nil
+}
+
+type fakeQueue struct {
+	model.PrintQueue
+	retries []time.Time
+	pushed  []int
+}
+
+func (f *fakeQueue) Push(ctx context.Context, jobID int) error {
+	f.pushed = append(f.pushed, jobID)
+	return nil
+}
+
+func (f *fakeQueue) Retry(ctx context.Context, jobID int, at time.Time) error {
+	f.retries = append(f.retries, at)
+	return nil
+}
+
+type fakeReceipts struct {
+	model.ReceiptService
+}
+
+func (fakeReceipts) ForOrder(ctx context.Context, orderID int) (*model.Receipt, error) {
+	return &model.Receipt{
+		Store:    &entity.Store{Name: "Kopi Senja"},
+		Order:    &entity.Order{ID: orderID, OrderNumber: "A-" + strconv.Itoa(orderID), Subtotal: 25000, Total: 25000},
+		IssuedAt: time.Date(2025, 10, 3, 14, 5, 0, 0, time.UTC),
+	}, nil
+}
+
+func newTestWorker(port int) (*Worker, *fakePrinters, *fakeQueue) {
+	printers := &fakePrinters{
+		printer: &entity.Printer{ID: 1, Name: "Counter", Host: "127.0.0.1", Port: port, PaperWidth: 80, IsActive: true},
+		job:     &entity.PrintJob{ID: 9, PrinterID: 1, OrderID: 42, Kind: entity.PrintReceipt, Status: entity.PrintQueued},
+	}
+	queue := &fakeQueue{}
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	return &Worker{
+		Printers:    printers,
+		Queue:       queue,
+		Receipts:    fakeReceipts{},
+		Log:         log,
+		Workers:     1,
+		MaxAttempts: 3,
+		RetryAfter:  time.Minute,
+		Timeout:     time.Second,
+		StaleAfter:  5 * time.Minute,
+	}, printers, queue
+}
+
+func TestProcessSendsReceipt(t *testing.T) {
+	stub := newPrinterStub(t)
+	worker, printers, queue := newTestWorker(stub.port())
+
+	worker.Process(context.Background(), 9)
+	data := stub.wait(t)
+
+	if !bytes.HasPrefix(data, []byte{0x1b, '@'}) { // ESC @
+		t.Errorf("job does not start by initializing the printer: %q", data[:min(len(data), 8)])
+	}
+	if !bytes.Contains(data, []byte("A-42")) {
+		t.Errorf("receipt is missing the order number")
+	}
+	if !bytes.HasSuffix(data, []byte{0x1d, 'V', 1}) { // GS V 1
+		t.Errorf("job does not end with a cut")
+	}
+	if printers.job.Status != entity.PrintPrinted || printers.job.LastError != "" {
+		t.Errorf("job is %s (%q), want printed", printers.job.Status, printers.job.LastError)
+	}
+	if len(queue.retries) != 0 {
+		t.Errorf("a printed job was retried %d times", len(queue.retries))
+	}
+}
+
+func TestProcessRetriesWithBackoffThenFails(t *testing.T) {
+	worker, printers, queue := newTestWorker(closedPort(t))
+
+	for attempt := 1; attempt <= worker.MaxAttempts; attempt++ {
+		before := time.Now()
+		worker.Process(context.Background(), 9)
+
+		if printers.job.Attempts != attempt {
+			t.Fatalf("attempt %d: job has %d attempts", attempt, printers.job.Attempts)
+		}
+		if printers.job.LastError == "" {
+			t.Errorf("attempt %d: no error recorded", attempt)
+		}
+		if attempt < worker.MaxAttempts {
+			if printers.job.Status != entity.PrintQueued {
+				t.Fatalf("attempt %d: job is %s, want queued", attempt, printers.job.Status)
+			}
+			delay := queue.retries[attempt-1].Sub(before)
+			want := worker.RetryAfter << (attempt - 1)
+			if delay < want || delay > want+time.Second {
+				t.Errorf("attempt %d: retry in %s, want %s", attempt, delay, want)
+			}
+		}
+	}
+
+	if printers.job.Status != entity.PrintFailed {
+		t.Errorf("job is %s after %d attempts, want failed", printers.job.Status, worker.MaxAttempts)
+	}
+	if len(queue.retries) != worker.MaxAttempts-1 {
+		t.Errorf("job was retried %d times, want %d", len(queue.retries), worker.MaxAttempts-1)
+	}
+}
+
+func TestProcessRecoversOnRetry(t *testing.T) {
+	worker, printers, _ := newTestWorker(closedPort(t))
+
+	worker.Process(context.Background(), 9)
+	if printers.job.Status != entity.PrintQueued {
+		t.Fatalf("job is %s after a failed attempt, want queued", printers.job.Status)
+	}
+
+	stub := newPrinterStub(t)
+	printers.printer.Port = stub.port()
+	worker.Process(context.Background(), 9)
+	stub.wait(t)
+
+	if printers.job.Status != entity.PrintPrinted || printers.job.Attempts != 2 {
+		t.Errorf("job is %s after %d attempts, want printed after 2", printers.job.Status, printers.job.Attempts)
+	}
+}
+
+func TestProcessSkipsInactivePrinter(t *testing.T) {
+	stub := newPrinterStub(t)
+	worker, printers, _ := newTestWorker(stub.port())
+	printers.printer.IsActive = false
+	worker.MaxAttempts = 1
+
+	worker.Process(context.Background(), 9)
+
+	if printers.job.Status != entity.PrintFailed {
+		t.Errorf("job is %s, want failed", printers.job.Status)
+	}
+	select {
+	case <-stub.done:
+		t.Error("an inactive printer was sent the job")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRequeuePushesStaleJobs(t *testing.T) {
+	worker, printers, queue := newTestWorker(closedPort(t))
+	printers.stale = []int{9, 11}
+
+	if err := worker.Requeue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queue.pushed) != 2 || queue.pushed[0] != 9 || queue.pushed[1] != 11 {
+		t.Errorf("pushed %v, want [9 11]", queue.pushed)
+	}
+	if age := time.Since(printers.before); age < worker.StaleAfter || age > worker.StaleAfter+time.Second {
+		t.Errorf("requeued jobs older than %s, want %s", age, worker.StaleAfter)
+	}
+}
@@ -0,0 +1,174 @@
+package receipt
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var orderTypeLabels = map[string]string{
+	entity.OrderDineIn:   "Dine in",
+	entity.OrderTakeaway: "Takeaway",
+	entity.OrderDelivery: "Delivery",
+	entity.OrderPickup:   "Pickup",
+}
+
+var paymentLabels = map[string]string{
+	entity.PaymentCash: "Cash",
+	entity.PaymentCard: "Card",
+	entity.PaymentQRIS: "QRIS",
+}
+
+// ForReceipt lays out a customer receipt: store, order, items with their
+// customizations, totals with the included tax, and payments.
+func ForReceipt(r *model.Receipt) []Line {
+	order := r.Order
+	lines := []Line{{Left: r.Store.Name, Center: true, Bold: true, Large: true}}
+	for _, text := range []string{r.Store.Address, r.Store.Phone} {
+		if text != "" {
+			lines = append(lines, Line{Left: text, Center: true})
+		}
+	}
+
+	lines = append(lines,
+		Line{Rule: true},
+		Line{Left: "Order", Right: order.OrderNumber},
+		Line{Left: "Date", Right: r.IssuedAt.Format("02/01/2006 15:04")},
+	)
+	lines = append(lines, orderDetails(order)...)
+	lines = append(lines, Line{Rule: true})
+
+	for _, item := range r.Items {
+		price := money(item.UnitPrice * int64(item.Quantity))
+		if item.StampProgramID != nil {
+			price = "FREE"
+		}
+		lines = append(lines, Line{Left: fmt.Sprintf("%dx %s", item.Quantity, item.MenuItemName), Right: price})
+		lines = append(lines, itemDetails(item)...)
+	}
+
+	lines = append(lines, Line{Rule: true}, Line{Left: "Subtotal", Right: money(order.Subtotal)})
+	if order.Discount > 0 {
+		label := "Discount"
+		if order.PointsRedeemed > 0 {
+			label = fmt.Sprintf("Points (%d)", order.PointsRedeemed)
+		}
+		lines = append(lines, Line{Left: label, Right: money(-order.Discount)})
+	}
+	if order.Fees > 0 {
+		lines = append(lines, Line{Left: "Fees", Right: money(order.Fees)})
+	}
+	lines = append(lines, Line{Left: "TOTAL", Right: money(order.Total), Bold: true})
+	if r.Tax.Amount > 0 {
+		lines = append(lines, Line{Left: fmt.Sprintf("incl. %s %s%%", r.Tax.Name, strconv.FormatFloat(r.Tax.Percent, 'f', -1, 64)), Right: money(r.Tax.Amount)})
+	}
+
+	if len(r.Payments) > 0 {
+		lines = append(lines, Line{Rule: true})
+		var paid int64
+		for _, payment := range r.Payments {
+			label := paymentLabels[payment.Method]
+			if label == "" {
+				label = payment.Method
+			}
+			if payment.Status == entity.PaymentRefunded {
+				lines = append(lines, Line{Left: label + " (refunded)", Right: money(-payment.Amount)})
+				continue
+			}
+			paid += payment.Amount
+			if payment.Tendered != nil && *payment.Tendered > payment.Amount {
+				lines = append(lines,
+					Line{Left: label, Right: money(*payment.Tendered)},
+					Line{Left: "Change", Right: money(*payment.Tendered - payment.Amount)},
+				)
+				continue
+			}
+			lines = append(lines, Line{Left: label, Right: money(payment.Amount)})
+		}
+		if paid < order.Total {
+			lines = append(lines, Line{Left: "Balance due", Right: money(order.Total - paid), Bold: true})
+		}
+	}
+
+	if r.Footer != "" {
+		lines = append(lines, Line{Rule: true}, Line{Left: r.Footer, Center: true})
+	}
+
+	return lines
+}
+
+// ForTicket lays out what a station makes for one order, large enough to
+// read from across the bar. Prices are left out.
+func ForTicket(t *model.TicketSlip) []Line {
+	order := t.Order
+	lines := []Line{
+		{Left: strings.ToUpper(t.Station.Name), Center: true, Bold: true, Large: true},
+		{Left: order.OrderNumber, Center: true, Bold: true, Large: true},
+		{Rule: true},
+		{Left: "Placed", Right: t.PlacedAt.Format("15:04")},
+	}
+	if t.PickupAt != nil {
+		lines = append(lines, Line{Left: "Pickup", Right: t.PickupAt.Format("02/01 15:04"), Bold: true})
+	}
+	lines = append(lines, orderDetails(order)...)
+	lines = append(lines, Line{Rule: true})
+
+	for _, item := range t.Items {
+		lines = append(lines, Line{Left: fmt.Sprintf("%dx %s", item.Quantity, item.MenuItemName), Bold: true, Large: true})
+		lines = append(lines, itemDetails(item)...)
+	}
+	if order.CustomerNote != "" {
+		lines = append(lines, Line{Rule: true}, Line{Left: "Note: " + order.CustomerNote, Bold: true})
+	}
+
+	return lines
+}
+
+func orderDetails(order *entity.Order) []Line {
+	lines := []Line{}
+	if label, ok := orderTypeLabels[order.OrderType]; ok {
+		lines = append(lines, Line{Left: "Type", Right: label})
+	}
+	if order.TableNumber != "" {
+		lines = append(lines, Line{Left: "Table", Right: order.TableNumber, Bold: true})
+	}
+	if order.CustomerName != "" {
+		lines = append(lines, Line{Left: "Customer", Right: order.CustomerName})
+	}
+	if order.DeliveryAddress != "" {
+		lines = append(lines, Line{Left: "Deliver to: " + order.DeliveryAddress})
+	}
+	return lines
+}
+
+func itemDetails(item model.OrderItemDetail) []Line {
+	lines := []Line{}
+	for _, customization := range item.CustomizationList() {
+		lines = append(lines, Line{Left: "+ " + customization.Label, Indent: 3})
+	}
+	if item.Note != "" {
+		lines = append(lines, Line{Left: "! " + item.Note, Indent: 3})
+	}
+	return lines
+}
+
+// money formats rupiah with dots between thousands, e.g. Rp25.000.
+func money(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+
+	return sign + "Rp" + b.String()
+}
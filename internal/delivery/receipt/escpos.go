@@ -0,0 +1,39 @@
+package receipt
+
+import (
+	"bytes"
+	"strings"
+)
+
+const (
+	esc = 0x1b
+	gs  = 0x1d
+)
+
+// encodeESCPOS writes the rows as ESC/POS commands: initialize, the rows with
+// bold and double size switched around them, then feed and a partial cut.
+func encodeESCPOS(rows []row, columns int) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{esc, '@'})
+
+	for _, row := range rows {
+		if row.bold {
+			b.Write([]byte{esc, 'E', 1})
+		}
+		if row.large {
+			b.Write([]byte{gs, '!', 0x11})
+		}
+		b.WriteString(strings.TrimRight(row.text, " "))
+		if row.large {
+			b.Write([]byte{gs, '!', 0})
+		}
+		if row.bold {
+			b.Write([]byte{esc, 'E', 0})
+		}
+		b.WriteByte('\n')
+	}
+
+	b.Write([]byte{esc, 'd', 4})
+	b.Write([]byte{gs, 'V', 1})
+	return b.Bytes()
+}
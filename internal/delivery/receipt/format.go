@@ -0,0 +1,57 @@
+package receipt
+
+import (
+	"sort"
+	"strings"
+)
+
+// Format is one output a document can be rendered to.
+type Format struct {
+	Name        string
+	ContentType string
+	Extension   string
+	Columns     int  // characters per row at normal size
+	Large       bool // prints double size text
+	encode      func(rows []row, columns int) []byte
+}
+
+var formats = map[string]Format{
+	"escpos58": {Name: "escpos58", ContentType: "application/octet-stream", Extension: "bin", Columns: 32, Large: true, encode: encodeESCPOS},
+	"escpos80": {Name: "escpos80", ContentType: "application/octet-stream", Extension: "bin", Columns: 48, Large: true, encode: encodeESCPOS},
+	"text":     {Name: "text", ContentType: "text/plain; charset=utf-8", Extension: "txt", Columns: 48, encode: encodeText},
+	"pdf":      {Name: "pdf", ContentType: "application/pdf", Extension: "pdf", Columns: 48, Large: true, encode: encodePDF},
+}
+
+// DefaultFormat is used when a request does not name one.
+const DefaultFormat = "text"
+
+func Lookup(name string) (Format, bool) {
+	if name == "" {
+		name = DefaultFormat
+	}
+	format, ok := formats[name]
+	return format, ok
+}
+
+// Names lists the known formats, for error messages.
+func Names() string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func (f Format) Render(lines []Line) []byte {
+	return f.encode(layout(lines, f.Columns, f.Large), f.Columns)
+}
+
+func encodeText(rows []row, columns int) []byte {
+	var b strings.Builder
+	for _, row := range rows {
+		b.WriteString(strings.TrimRight(row.text, " "))
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,133 @@
+// Package receipt renders receipts and station tickets for thermal printers
+// (ESC/POS), as plain text and as PDF. Documents are first laid out as lines,
+// then wrapped to the paper width and encoded; output depends only on the
+// input, so it can be compared byte for byte.
+package receipt
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Line is one logical line of a document. Left and Right share a row when
+// they fit, otherwise Left wraps and Right goes flush right below it.
+type Line struct {
+	Left   string
+	Right  string
+	Center bool
+	Bold   bool
+	Large  bool // double width and height, half the columns
+	Rule   bool
+	Indent int // spaces before Left, also on wrapped rows
+}
+
+// row is one printed row, already padded to the paper width.
+type row struct {
+	text  string
+	bold  bool
+	large bool
+}
+
+// layout wraps lines into rows. Formats that can not print large text lay
+// large lines out at the normal width.
+func layout(lines []Line, columns int, large bool) []row {
+	rows := []row{}
+	for _, line := range lines {
+		if line.Rule {
+			rows = append(rows, row{text: strings.Repeat("-", columns)})
+			continue
+		}
+
+		width := columns
+		if line.Large && large {
+			width = columns / 2
+		}
+		indent := strings.Repeat(" ", min(line.Indent, width/2))
+		add := func(text string) {
+			rows = append(rows, row{text: text, bold: line.Bold, large: line.Large && large})
+		}
+
+		left := wrap(ascii(line.Left), width-len(indent))
+		for i := range left {
+			left[i] = indent + left[i]
+		}
+		right := ascii(line.Right)
+		if right == "" {
+			for _, text := range left {
+				if line.Center {
+					text = strings.Repeat(" ", (width-len(text))/2) + text
+				}
+				add(text)
+			}
+			continue
+		}
+
+		if len(right) > width {
+			right = right[:width]
+		}
+		last := left[len(left)-1]
+		for _, text := range left[:len(left)-1] {
+			add(text)
+		}
+		if len(last)+1+len(right) <= width {
+			add(last + strings.Repeat(" ", width-len(last)-len(right)) + right)
+		} else {
+			add(last)
+			add(strings.Repeat(" ", width-len(right)) + right)
+		}
+	}
+
+	return rows
+}
+
+// wrap breaks text into rows of at most width characters on spaces, cutting
+// words that are longer than a row. It always returns at least one row.
+func wrap(text string, width int) []string {
+	out := []string{}
+	current := ""
+	for _, word := range strings.Fields(text) {
+		for len(word) > width {
+			if current != "" {
+				out = append(out, current)
+				current = ""
+			}
+			out = append(out, word[:width])
+			word = word[width:]
+		}
+		switch {
+		case current == "":
+			current = word
+		case len(current)+1+len(word) <= width:
+			current += " " + word
+		default:
+			out = append(out, current)
+			current = word
+		}
+	}
+	if current != "" || len(out) == 0 {
+		out = append(out, current)
+	}
+
+	return out
+}
+
+// ascii keeps printable ASCII only. Thermal printers start in a code page
+// that differs per model, so accents are dropped and anything else prints
+// as '?'.
+func ascii(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(text) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r == '\t' || r == '\n' || r == '\r':
+			b.WriteByte(' ')
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
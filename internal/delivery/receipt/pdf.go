@@ -0,0 +1,78 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PDF pages are as wide as the paper roll and as long as the document, set
+// in Courier so the columns line up like on the printer.
+const (
+	pdfFontSize = 8.0
+	pdfLeading  = 10.0
+	pdfMargin   = 12.0
+	pdfCharRate = 0.6 // Courier advance per point of font size
+)
+
+func encodePDF(rows []row, columns int) []byte {
+	width := pdfMargin*2 + float64(columns)*pdfFontSize*pdfCharRate
+	height := pdfMargin * 2
+	for _, row := range rows {
+		height += rowLeading(row)
+	}
+
+	var content bytes.Buffer
+	y := height - pdfMargin
+	for _, row := range rows {
+		size, leading := pdfFontSize, rowLeading(row)
+		if row.large {
+			size *= 2
+		}
+		font := "F1"
+		if row.bold {
+			font = "F2"
+		}
+		y -= leading
+		fmt.Fprintf(&content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n",
+			font, size, pdfMargin, y+leading*0.25, pdfEscape(strings.TrimRight(row.text, " ")))
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", width, height),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return b.Bytes()
+}
+
+func rowLeading(row row) float64 {
+	if row.large {
+		return pdfLeading * 2
+	}
+	return pdfLeading
+}
+
+func pdfEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(text)
+}
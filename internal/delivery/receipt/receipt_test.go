@@ -0,0 +1,151 @@
+package receipt
+
+import (
+	"bytes"
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+var wib = time.FixedZone("WIB", 7*60*60)
+
+func sampleOrder() *entity.Order {
+	return &entity.Order{
+		ID:           42,
+		OrderNumber:  "A-0042",
+		OrderType:    entity.OrderDineIn,
+		TableNumber:  "7",
+		CustomerName: "Sari",
+		CustomerNote: "No straw please",
+		Subtotal:     83000,
+		Discount:     5000,
+		Total:        78000,
+	}
+}
+
+func sampleItems() []model.OrderItemDetail {
+	stamp := 3
+	return []model.OrderItemDetail{
+		{
+			OrderItem: entity.OrderItem{
+				Quantity:  2,
+				UnitPrice: 33000,
+				Customizations: []entity.OrderItemCustomization{
+					{Label: "Oat milk", AdditionalPrice: 5000},
+					{Label: "Extra shot", AdditionalPrice: 6000},
+				},
+				Note: "Less ice",
+			},
+			MenuItemName: "Iced Caffe Latte",
+		},
+		{
+			OrderItem:    entity.OrderItem{Quantity: 1, UnitPrice: 17000},
+			MenuItemName: "Butter Croissant",
+		},
+		{
+			OrderItem:    entity.OrderItem{Quantity: 1, UnitPrice: 28000, StampProgramID: &stamp},
+			MenuItemName: "Americano",
+		},
+	}
+}
+
+func sampleReceipt() *model.Receipt {
+	tendered := int64(100000)
+	return &model.Receipt{
+		Store: &entity.Store{Name: "Kopi Senja", Address: "Jl. Braga No. 12, Bandung", Phone: "022-4201234"},
+		Order: sampleOrder(),
+		Items: sampleItems(),
+		Payments: []entity.Payment{
+			{Method: entity.PaymentCash, Amount: 50000, Status: entity.PaymentCaptured, Tendered: &tendered},
+			{Method: entity.PaymentQRIS, Amount: 20000, Status: entity.PaymentCaptured},
+		},
+		Tax:      model.ReceiptTax{Name: "PB1", Percent: 10, Amount: 7091},
+		IssuedAt: time.Date(2025, 10, 3, 14, 5, 0, 0, wib),
+		Footer:   "Thank you, see you again!",
+	}
+}
+
+func sampleTicket() *model.TicketSlip {
+	pickup := time.Date(2025, 10, 3, 14, 30, 0, 0, wib)
+	return &model.TicketSlip{
+		Station:  &entity.Station{Name: "Bar"},
+		Order:    sampleOrder(),
+		Items:    sampleItems()[:1],
+		PlacedAt: time.Date(2025, 10, 3, 14, 5, 0, 0, wib),
+		PickupAt: &pickup,
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	tests := []struct {
+		golden string
+		format string
+		lines  []Line
+	}{
+		{"receipt_escpos58.golden", "escpos58", ForReceipt(sampleReceipt())},
+		{"receipt_escpos80.golden", "escpos80", ForReceipt(sampleReceipt())},
+		{"receipt_text.golden", "text", ForReceipt(sampleReceipt())},
+		{"ticket_escpos58.golden", "escpos58", ForTicket(sampleTicket())},
+		{"ticket_escpos80.golden", "escpos80", ForTicket(sampleTicket())},
+	}
+
+	for _, test := range tests {
+		t.Run(test.golden, func(t *testing.T) {
+			format, ok := Lookup(test.format)
+			if !ok {
+				t.Fatalf("unknown format %q", test.format)
+			}
+			got := format.Render(test.lines)
+
+			path := filepath.Join("testdata", test.golden)
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("missing golden file, run with -update: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output differs from %s\ngot:\n%q\nwant:\n%q", path, got, want)
+			}
+		})
+	}
+}
+
+func TestESCPOSFraming(t *testing.T) {
+	format, _ := Lookup("escpos80")
+	got := format.Render([]Line{{Left: "Hello", Bold: true, Large: true}})
+
+	want := []byte{esc, '@'}
+	want = append(want, esc, 'E', 1, gs, '!', 0x11)
+	want = append(want, "Hello"...)
+	want = append(want, gs, '!', 0, esc, 'E', 0, '\n')
+	want = append(want, esc, 'd', 4, gs, 'V', 1)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMoney(t *testing.T) {
+	tests := map[int64]string{
+		0:        "Rp0",
+		500:      "Rp500",
+		25000:    "Rp25.000",
+		1234567:  "Rp1.234.567",
+		-5000:    "-Rp5.000",
+		-1000000: "-Rp1.000.000",
+	}
+	for amount, want := range tests {
+		if got := money(amount); got != want {
+			t.Errorf("money(%d) = %q, want %q", amount, got, want)
+		}
+	}
+}
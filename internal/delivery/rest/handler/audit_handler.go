@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AuditHandler struct {
+	Service model.AuditService
+	Log     *logrus.Logger
+}
+
+func NewAuditHandler(service model.AuditService, log *logrus.Logger) model.AuditHandler {
+	return &AuditHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *AuditHandler) Search(ctx *fiber.Ctx) error {
+	request := new(model.AuditSearchRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	entries, err := h.Service.Search(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(entries, fiber.StatusOK))
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type CartHandler struct {
+	Service model.CartService
+	Log     *logrus.Logger
+}
+
+func NewCartHandler(service model.CartService, log *logrus.Logger) model.CartHandler {
+	return &CartHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *CartHandler) Open(ctx *fiber.Ctx) error {
+	cart, err := h.Service.Open(ctx.UserContext(), ctx.Params("slug"), ctx.Params("table"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(cart, fiber.StatusCreated))
+}
+
+func (h *CartHandler) Get(ctx *fiber.Ctx) error {
+	cart, err := h.Service.Get(ctx.UserContext(), ctx.Params("token"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(cart, fiber.StatusOK))
+}
+
+func (h *CartHandler) AddItem(ctx *fiber.Ctx) error {
+	request := new(model.PlaceOrderItem)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	cart, err := h.Service.AddItem(ctx.UserContext(), ctx.Params("token"), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(cart, fiber.StatusOK))
+}
+
+func (h *CartHandler) RemoveItem(ctx *fiber.Ctx) error {
+	index, err := ctx.ParamsInt("index")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	cart, err := h.Service.RemoveItem(ctx.UserContext(), ctx.Params("token"), index)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(cart, fiber.StatusOK))
+}
+
+func (h *CartHandler) Checkout(ctx *fiber.Ctx) error {
+	request := new(model.CheckoutRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	order, err := h.Service.Checkout(ctx.UserContext(), ctx.Params("token"), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(order, fiber.StatusCreated))
+}
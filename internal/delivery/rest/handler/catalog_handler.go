@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"bytes"
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type CatalogHandler struct {
+	Service model.CatalogService
+	Log     *logrus.Logger
+}
+
+func NewCatalogHandler(service model.CatalogService, log *logrus.Logger) model.CatalogHandler {
+	return &CatalogHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+// Export sends the catalog as a file, ready to be edited and imported again.
+func (h *CatalogHandler) Export(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.ExportCatalogRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	var file bytes.Buffer
+	if err := h.Service.Export(ctx.UserContext(), middleware.GetUser(ctx), id, request, &file); err != nil {
+		return err
+	}
+
+	extension := "json"
+	ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	if request.Format == "csv" {
+		extension = "csv"
+		ctx.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	}
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="catalog-store-%d.%s"`, id, extension))
+
+	return ctx.Send(file.Bytes())
+}
+
+// Import takes the file as the request body, CSV when the format says so or
+// the body is sent as text/csv, JSON otherwise.
+func (h *CatalogHandler) Import(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.ImportCatalogRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+	if request.Format == "" && ctx.Is("csv") {
+		request.Format = "csv"
+	}
+
+	result, err := h.Service.Import(ctx.UserContext(), middleware.GetUser(ctx), id, request, bytes.NewReader(ctx.Body()))
+	if err != nil {
+		return err
+	}
+
+	if len(result.Errors) > 0 {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(model.NewWebResponse(result, fiber.StatusUnprocessableEntity))
+	}
+	return ctx.JSON(model.NewWebResponse(result, fiber.StatusOK))
+}
+
+func (h *CatalogHandler) Clone(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.CloneStoreRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	result, err := h.Service.Clone(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(result, fiber.StatusCreated))
+}
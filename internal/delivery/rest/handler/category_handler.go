@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type CategoryHandler struct {
+	Service model.CategoryService
+	Log     *logrus.Logger
+}
+
+func NewCategoryHandler(service model.CategoryService, log *logrus.Logger) model.CategoryHandler {
+	return &CategoryHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *CategoryHandler) List(ctx *fiber.Ctx) error {
+	request := new(model.ListCategoriesRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	categories, err := h.Service.List(ctx.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(categories, fiber.StatusOK))
+}
+
+func (h *CategoryHandler) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateCategoryRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	category, err := h.Service.Create(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(category, fiber.StatusCreated))
+}
+
+func (h *CategoryHandler) Update(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.UpdateCategoryRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	category, err := h.Service.Update(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(category, fiber.StatusOK))
+}
+
+func (h *CategoryHandler) Remove(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.Service.Remove(ctx.UserContext(), middleware.GetUser(ctx), id); err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(true, fiber.StatusOK))
+}
+
+func (h *CategoryHandler) StoreCategories(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	categories, err := h.Service.StoreCategories(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(categories, fiber.StatusOK))
+}
+
+func (h *CategoryHandler) UpdateStoreCategory(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	categoryID, err := ctx.ParamsInt("categoryId")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.UpdateStoreCategoryRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	category, err := h.Service.UpdateStoreCategory(ctx.UserContext(), middleware.GetUser(ctx), id, categoryID, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(category, fiber.StatusOK))
+}
+
+func (h *CategoryHandler) Reorder(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.ReorderCategoriesRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	categories, err := h.Service.Reorder(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(categories, fiber.StatusOK))
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type CustomerHandler struct {
+	Service model.CustomerService
+	Log     *logrus.Logger
+}
+
+func NewCustomerHandler(service model.CustomerService, log *logrus.Logger) model.CustomerHandler {
+	return &CustomerHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *CustomerHandler) Register(ctx *fiber.Ctx) error {
+	request := new(model.CreateCustomerRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	customer, err := h.Service.Register(ctx.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(customer, fiber.StatusCreated))
+}
+
+func (h *CustomerHandler) Lookup(ctx *fiber.Ctx) error {
+	request := new(model.CustomerLookupRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	customer, err := h.Service.Lookup(ctx.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(customer, fiber.StatusOK))
+}
+
+func (h *CustomerHandler) Get(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	customer, err := h.Service.Get(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(customer, fiber.StatusOK))
+}
+
+func (h *CustomerHandler) Ledger(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	entries, err := h.Service.Ledger(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(entries, fiber.StatusOK))
+}
+
+func (h *CustomerHandler) Stamps(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	cards, err := h.Service.Stamps(ctx.UserContext(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(cards, fiber.StatusOK))
+}
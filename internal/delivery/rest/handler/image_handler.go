@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ImageHandler struct {
+	Service model.ImageService
+	Log     *logrus.Logger
+}
+
+func NewImageHandler(service model.ImageService, log *logrus.Logger) model.ImageHandler {
+	return &ImageHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+// UploadMenuItemImage takes the photo as the "image" field of a multipart
+// form.
+func (h *ImageHandler) UploadMenuItemImage(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	upload, err := ctx.FormFile("image")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "the image field is required")
+	}
+
+	image, err := h.Service.UploadMenuItemImage(ctx.UserContext(), middleware.GetUser(ctx), id, upload)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(image, fiber.StatusCreated))
+}
+
+func (h *ImageHandler) Serve(ctx *fiber.Ctx) error {
+	file, err := h.Service.Open(ctx.UserContext(), ctx.Params("*"))
+	if err != nil {
+		return err
+	}
+
+	etag := fmt.Sprintf(`"%x-%x"`, file.ModifiedAt.Unix(), file.Size)
+	ctx.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d, immutable", int(file.MaxAge.Seconds())))
+	ctx.Set(fiber.HeaderETag, etag)
+	ctx.Set(fiber.HeaderLastModified, file.ModifiedAt.UTC().Format(http.TimeFormat))
+	if ctx.Get(fiber.HeaderIfNoneMatch) == etag {
+		file.Body.Close()
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	ctx.Set(fiber.HeaderContentType, file.ContentType)
+	return ctx.SendStream(file.Body, int(file.Size))
+}
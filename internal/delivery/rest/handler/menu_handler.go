@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type MenuHandler struct {
+	Service model.MenuService
+	Log     *logrus.Logger
+}
+
+func NewMenuHandler(service model.MenuService, log *logrus.Logger) model.MenuHandler {
+	return &MenuHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *MenuHandler) ForStore(ctx *fiber.Ctx) error {
+	at, err := menuTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter := new(model.MenuFilter)
+	if err := ctx.QueryParser(filter); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	menu, err := h.Service.ForStore(ctx.UserContext(), middleware.GetUser(ctx), ctx.QueryInt("store_id"), at, filter)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(menu, fiber.StatusOK))
+}
+
+func (h *MenuHandler) BySlug(ctx *fiber.Ctx) error {
+	at, err := menuTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter := new(model.MenuFilter)
+	if err := ctx.QueryParser(filter); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	menu, err := h.Service.BySlug(ctx.UserContext(), ctx.Params("slug"), at, filter)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(menu, fiber.StatusOK))
+}
+
+func (h *MenuHandler) Windows(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	windows, err := h.Service.Windows(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(windows, fiber.StatusOK))
+}
+
+func (h *MenuHandler) AddWindow(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.CreateAvailabilityWindowRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	window, err := h.Service.AddWindow(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(window, fiber.StatusCreated))
+}
+
+func (h *MenuHandler) RemoveWindow(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	windowID, err := ctx.ParamsInt("windowId")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.Service.RemoveWindow(ctx.UserContext(), middleware.GetUser(ctx), id, windowID); err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(true, fiber.StatusOK))
+}
+
+// menuTime is the optional ?at= of a menu request, so pre-orders can browse
+// the menu of their pickup time. It defaults to now.
+func (h *MenuHandler) UpdateTags(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.UpdateMenuItemTagsRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	item, err := h.Service.UpdateTags(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(item, fiber.StatusOK))
+}
+
+func menuTime(ctx *fiber.Ctx) (time.Time, error) {
+	at := ctx.Query("at")
+	if at == "" {
+		return time.Now(), nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return time.Time{}, fiber.NewError(fiber.StatusBadRequest, "at must be an RFC 3339 time")
+	}
+
+	return parsed, nil
+}
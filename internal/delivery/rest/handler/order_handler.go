@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type OrderHandler struct {
+	Service model.OrderService
+	Log     *logrus.Logger
+}
+
+func NewOrderHandler(service model.OrderService, log *logrus.Logger) model.OrderHandler {
+	return &OrderHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *OrderHandler) Place(ctx *fiber.Ctx) error {
+	request := new(model.PlaceOrderRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	order, err := h.Service.Place(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(order, fiber.StatusCreated))
+}
+
+func (h *OrderHandler) Queue(ctx *fiber.Ctx) error {
+	request := new(model.OrderQueueRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	queue, err := h.Service.Queue(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(queue, fiber.StatusOK))
+}
+
+func (h *OrderHandler) UpdateStatus(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.UpdateOrderStatusRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	order, err := h.Service.UpdateStatus(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(order, fiber.StatusOK))
+}
+
+func (h *OrderHandler) BumpTicket(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	bumped, err := h.Service.BumpTicket(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(bumped, fiber.StatusOK))
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentHandler struct {
+	Service model.PaymentService
+	Log     *logrus.Logger
+}
+
+func NewPaymentHandler(service model.PaymentService, log *logrus.Logger) model.PaymentHandler {
+	return &PaymentHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *PaymentHandler) Capture(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.CapturePaymentRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	response, err := h.Service.Capture(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(response, fiber.StatusCreated))
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PickupHandler struct {
+	Service model.PickupService
+	Log     *logrus.Logger
+}
+
+func NewPickupHandler(service model.PickupService, log *logrus.Logger) model.PickupHandler {
+	return &PickupHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *PickupHandler) Settings(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	settings, err := h.Service.Settings(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(settings, fiber.StatusOK))
+}
+
+func (h *PickupHandler) UpdateSettings(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.UpdatePickupSettingsRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	settings, err := h.Service.UpdateSettings(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(settings, fiber.StatusOK))
+}
+
+func (h *PickupHandler) Slots(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.PickupSlotRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	slots, err := h.Service.Slots(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(slots, fiber.StatusOK))
+}
+
+func (h *PickupHandler) PublicSlots(ctx *fiber.Ctx) error {
+	request := new(model.PickupSlotRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	slots, err := h.Service.PublicSlots(ctx.UserContext(), ctx.Params("slug"), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(slots, fiber.StatusOK))
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PriceHandler struct {
+	Service model.PriceService
+	Log     *logrus.Logger
+}
+
+func NewPriceHandler(service model.PriceService, log *logrus.Logger) model.PriceHandler {
+	return &PriceHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *PriceHandler) History(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.PriceHistoryRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	prices, err := h.Service.History(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(prices, fiber.StatusOK))
+}
+
+func (h *PriceHandler) Schedule(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.SchedulePriceRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	price, err := h.Service.Schedule(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(price, fiber.StatusCreated))
+}
+
+func (h *PriceHandler) Cancel(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	priceID, err := ctx.ParamsInt("priceId")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.Service.Cancel(ctx.UserContext(), middleware.GetUser(ctx), id, priceID); err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(true, fiber.StatusOK))
+}
+
+func (h *PriceHandler) PriceAt(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.EffectivePriceRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	price, err := h.Service.PriceAt(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(price, fiber.StatusOK))
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PrinterHandler struct {
+	Service model.PrinterService
+	Log     *logrus.Logger
+}
+
+func NewPrinterHandler(service model.PrinterService, log *logrus.Logger) model.PrinterHandler {
+	return &PrinterHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *PrinterHandler) Printers(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	printers, err := h.Service.Printers(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(printers, fiber.StatusOK))
+}
+
+func (h *PrinterHandler) CreatePrinter(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.CreatePrinterRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	printer, err := h.Service.CreatePrinter(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(printer, fiber.StatusCreated))
+}
+
+func (h *PrinterHandler) UpdatePrinter(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	printerID, err := ctx.ParamsInt("printerId")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.UpdatePrinterRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	printer, err := h.Service.UpdatePrinter(ctx.UserContext(), middleware.GetUser(ctx), id, printerID, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(printer, fiber.StatusOK))
+}
+
+func (h *PrinterHandler) Jobs(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.PrintJobListRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	jobs, err := h.Service.Jobs(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(jobs, fiber.StatusOK))
+}
+
+func (h *PrinterHandler) Reprint(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	job, err := h.Service.Reprint(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(job, fiber.StatusCreated))
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ProjectionHandler struct {
+	Service model.ProjectionService
+	Log     *logrus.Logger
+}
+
+func NewProjectionHandler(service model.ProjectionService, log *logrus.Logger) model.ProjectionHandler {
+	return &ProjectionHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *ProjectionHandler) Rebuild(ctx *fiber.Ctx) error {
+	request := new(model.RebuildProjectionRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	response, err := h.Service.Rebuild(ctx.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(response, fiber.StatusOK))
+}
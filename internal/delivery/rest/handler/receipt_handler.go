@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"coffee/internal/delivery/receipt"
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ReceiptHandler struct {
+	Service model.ReceiptService
+	Log     *logrus.Logger
+}
+
+func NewReceiptHandler(service model.ReceiptService, log *logrus.Logger) model.ReceiptHandler {
+	return &ReceiptHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *ReceiptHandler) Receipt(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	format, err := receiptFormat(ctx)
+	if err != nil {
+		return err
+	}
+
+	document, err := h.Service.Receipt(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="receipt-%s.%s"`, document.Order.OrderNumber, format.Extension))
+	ctx.Set(fiber.HeaderContentType, format.ContentType)
+	return ctx.Send(format.Render(receipt.ForReceipt(document)))
+}
+
+func (h *ReceiptHandler) Ticket(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	format, err := receiptFormat(ctx)
+	if err != nil {
+		return err
+	}
+
+	slip, err := h.Service.Ticket(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="ticket-%d.%s"`, slip.Ticket.ID, format.Extension))
+	ctx.Set(fiber.HeaderContentType, format.ContentType)
+	return ctx.Send(format.Render(receipt.ForTicket(slip)))
+}
+
+func receiptFormat(ctx *fiber.Ctx) (receipt.Format, error) {
+	format, ok := receipt.Lookup(ctx.Query("format"))
+	if !ok {
+		return format, fiber.NewError(fiber.StatusBadRequest, "format must be one of "+receipt.Names())
+	}
+	return format, nil
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ReportHandler struct {
+	Service model.ReportService
+	Log     *logrus.Logger
+}
+
+func NewReportHandler(service model.ReportService, log *logrus.Logger) model.ReportHandler {
+	return &ReportHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *ReportHandler) SalesMix(ctx *fiber.Ctx) error {
+	request := new(model.SalesMixRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	response, err := h.Service.SalesMix(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	if request.Format == "csv" {
+		ctx.Attachment(fmt.Sprintf("sales-mix-%d-%s-%s.csv", response.StoreID, request.From, request.To))
+		return writeSalesMixCSV(ctx, response)
+	}
+
+	return ctx.JSON(model.NewWebResponse(response, fiber.StatusOK))
+}
+
+func (h *ReportHandler) PrepTimes(ctx *fiber.Ctx) error {
+	request := new(model.PrepTimeRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	response, err := h.Service.PrepTimes(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(response, fiber.StatusOK))
+}
+
+func (h *ReportHandler) SalesAnalytics(ctx *fiber.Ctx) error {
+	request := new(model.SalesAnalyticsRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	response, err := h.Service.SalesAnalytics(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(response, fiber.StatusOK))
+}
+
+func writeSalesMixCSV(w io.Writer, report *model.SalesMixResponse) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{
+		"type", "id", "name", "parent_id", "parent_name",
+		"quantity", "revenue", "previous_quantity", "previous_revenue",
+		"quantity_change_pct", "revenue_change_pct", "quantity_rank", "revenue_rank",
+	})
+
+	sections := []struct {
+		kind  string
+		lines []model.SalesMixLine
+	}{
+		{"category", report.Categories},
+		{"menu_item", report.Items},
+		{"customization", report.Customizations},
+	}
+
+	for _, section := range sections {
+		for _, line := range section.lines {
+			out.Write([]string{
+				section.kind,
+				strconv.Itoa(line.ID),
+				line.Name,
+				strconv.Itoa(line.ParentID),
+				line.ParentName,
+				strconv.FormatInt(line.Quantity, 10),
+				strconv.FormatInt(line.Revenue, 10),
+				strconv.FormatInt(line.PreviousQuantity, 10),
+				strconv.FormatInt(line.PreviousRevenue, 10),
+				formatChange(line.QuantityChange),
+				formatChange(line.RevenueChange),
+				strconv.Itoa(line.QuantityRank),
+				strconv.Itoa(line.RevenueRank),
+			})
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func formatChange(change *float64) string {
+	if change == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*change, 'f', 2, 64)
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ScheduleHandler struct {
+	Service model.ScheduleService
+	Log     *logrus.Logger
+}
+
+func NewScheduleHandler(service model.ScheduleService, log *logrus.Logger) model.ScheduleHandler {
+	return &ScheduleHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *ScheduleHandler) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateScheduleRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	schedule, err := h.Service.Create(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(schedule, fiber.StatusCreated))
+}
+
+func (h *ScheduleHandler) List(ctx *fiber.Ctx) error {
+	request := new(model.ScheduleListRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	schedules, err := h.Service.List(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(schedules, fiber.StatusOK))
+}
+
+func (h *ScheduleHandler) Remove(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.Service.Remove(ctx.UserContext(), middleware.GetUser(ctx), id); err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(true, fiber.StatusOK))
+}
+
+func (h *ScheduleHandler) Timesheet(ctx *fiber.Ctx) error {
+	request := new(model.TimesheetRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	response, err := h.Service.Timesheet(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	if request.Format == "csv" {
+		ctx.Attachment(fmt.Sprintf("timesheet-%d-%s-%s.csv", response.StoreID, request.From, request.To))
+		return writeTimesheetCSV(ctx, response)
+	}
+
+	return ctx.JSON(model.NewWebResponse(response, fiber.StatusOK))
+}
+
+// writeTimesheetCSV writes one line per time entry followed by a total line
+// per staff member, which is what payroll imports.
+func writeTimesheetCSV(w io.Writer, timesheet *model.TimesheetResponse) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{
+		"user_id", "full_name", "entry_id", "clock_in", "clock_out",
+		"scheduled_start", "scheduled_end", "break_minutes", "worked_minutes", "scheduled_minutes",
+	})
+
+	for _, user := range timesheet.Users {
+		userID := strconv.Itoa(user.UserID)
+
+		for _, entry := range user.Entries {
+			out.Write([]string{
+				userID,
+				user.FullName,
+				strconv.Itoa(entry.EntryID),
+				entry.ClockInAt.Format(time.RFC3339),
+				formatOptionalTime(entry.ClockOutAt),
+				formatOptionalTime(entry.ScheduledStartAt),
+				formatOptionalTime(entry.ScheduledEndAt),
+				strconv.FormatInt(entry.BreakMinutes, 10),
+				strconv.FormatInt(entry.WorkedMinutes, 10),
+				"",
+			})
+		}
+
+		out.Write([]string{
+			userID,
+			user.FullName,
+			"total",
+			"", "", "", "",
+			strconv.FormatInt(user.BreakMinutes, 10),
+			strconv.FormatInt(user.WorkedMinutes, 10),
+			strconv.FormatInt(user.ScheduledMinutes, 10),
+		})
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ShiftHandler struct {
+	Service model.ShiftService
+	Log     *logrus.Logger
+}
+
+func NewShiftHandler(service model.ShiftService, log *logrus.Logger) model.ShiftHandler {
+	return &ShiftHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *ShiftHandler) Open(ctx *fiber.Ctx) error {
+	request := new(model.OpenShiftRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	shift, err := h.Service.Open(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(shift, fiber.StatusCreated))
+}
+
+func (h *ShiftHandler) Current(ctx *fiber.Ctx) error {
+	report, err := h.Service.Current(ctx.UserContext(), middleware.GetUser(ctx), ctx.QueryInt("store_id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(report, fiber.StatusOK))
+}
+
+func (h *ShiftHandler) AddMovement(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.CashMovementRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	movement, err := h.Service.AddMovement(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(movement, fiber.StatusCreated))
+}
+
+func (h *ShiftHandler) Close(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.CloseShiftRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	report, err := h.Service.Close(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(report, fiber.StatusOK))
+}
+
+func (h *ShiftHandler) Report(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	report, err := h.Service.Report(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(report, fiber.StatusOK))
+}
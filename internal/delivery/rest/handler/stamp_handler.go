@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type StampHandler struct {
+	Service model.StampService
+	Log     *logrus.Logger
+}
+
+func NewStampHandler(service model.StampService, log *logrus.Logger) model.StampHandler {
+	return &StampHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *StampHandler) CreateProgram(ctx *fiber.Ctx) error {
+	request := new(model.CreateStampProgramRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	program, err := h.Service.CreateProgram(ctx.UserContext(), middleware.GetUser(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(program, fiber.StatusCreated))
+}
+
+func (h *StampHandler) UpdateProgram(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.UpdateStampProgramRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	program, err := h.Service.UpdateProgram(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(program, fiber.StatusOK))
+}
+
+func (h *StampHandler) ListPrograms(ctx *fiber.Ctx) error {
+	programs, err := h.Service.ListPrograms(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(programs, fiber.StatusOK))
+}
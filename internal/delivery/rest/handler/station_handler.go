@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type StationHandler struct {
+	Service model.StationService
+	Log     *logrus.Logger
+}
+
+func NewStationHandler(service model.StationService, log *logrus.Logger) model.StationHandler {
+	return &StationHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *StationHandler) Stations(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	stations, err := h.Service.Stations(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(stations, fiber.StatusOK))
+}
+
+func (h *StationHandler) CreateStation(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.CreateStationRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	station, err := h.Service.CreateStation(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(station, fiber.StatusCreated))
+}
+
+func (h *StationHandler) UpdateStation(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	stationID, err := ctx.ParamsInt("stationId")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.UpdateStationRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	station, err := h.Service.UpdateStation(ctx.UserContext(), middleware.GetUser(ctx), id, stationID, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(station, fiber.StatusOK))
+}
+
+func (h *StationHandler) Routes(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	routes, err := h.Service.Routes(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(routes, fiber.StatusOK))
+}
+
+func (h *StationHandler) AddRoute(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.CreateStationRouteRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	route, err := h.Service.AddRoute(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(route, fiber.StatusCreated))
+}
+
+func (h *StationHandler) RemoveRoute(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	routeID, err := ctx.ParamsInt("routeId")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.Service.RemoveRoute(ctx.UserContext(), middleware.GetUser(ctx), id, routeID); err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(true, fiber.StatusOK))
+}
+
+func (h *StationHandler) Queue(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	tickets, err := h.Service.Queue(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(tickets, fiber.StatusOK))
+}
+
+func (h *StationHandler) OrderTickets(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	tickets, err := h.Service.OrderTickets(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(tickets, fiber.StatusOK))
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type StoreHoursHandler struct {
+	Service model.StoreHoursService
+	Log     *logrus.Logger
+}
+
+func NewStoreHoursHandler(service model.StoreHoursService, log *logrus.Logger) model.StoreHoursHandler {
+	return &StoreHoursHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *StoreHoursHandler) Status(ctx *fiber.Ctx) error {
+	status, err := h.Service.Status(ctx.UserContext(), ctx.Params("slug"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(status, fiber.StatusOK))
+}
+
+func (h *StoreHoursHandler) Hours(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	hours, err := h.Service.Hours(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(hours, fiber.StatusOK))
+}
+
+func (h *StoreHoursHandler) UpdateHours(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.UpdateOpeningHoursRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	hours, err := h.Service.UpdateHours(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(hours, fiber.StatusOK))
+}
+
+func (h *StoreHoursHandler) UpdateTimezone(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.UpdateTimezoneRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	store, err := h.Service.UpdateTimezone(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(store, fiber.StatusOK))
+}
+
+func (h *StoreHoursHandler) Closures(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	return h.listClosures(ctx, id)
+}
+
+func (h *StoreHoursHandler) AddClosure(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	return h.addClosure(ctx, id)
+}
+
+func (h *StoreHoursHandler) RemoveClosure(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	return h.removeClosure(ctx, id)
+}
+
+func (h *StoreHoursHandler) Holidays(ctx *fiber.Ctx) error {
+	return h.listClosures(ctx, 0)
+}
+
+func (h *StoreHoursHandler) AddHoliday(ctx *fiber.Ctx) error {
+	return h.addClosure(ctx, 0)
+}
+
+func (h *StoreHoursHandler) RemoveHoliday(ctx *fiber.Ctx) error {
+	return h.removeClosure(ctx, 0)
+}
+
+func (h *StoreHoursHandler) listClosures(ctx *fiber.Ctx, storeID int) error {
+	request := new(model.ClosureListRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	closures, err := h.Service.Closures(ctx.UserContext(), middleware.GetUser(ctx), storeID, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(closures, fiber.StatusOK))
+}
+
+func (h *StoreHoursHandler) addClosure(ctx *fiber.Ctx, storeID int) error {
+	request := new(model.CreateClosureRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	closure, err := h.Service.AddClosure(ctx.UserContext(), middleware.GetUser(ctx), storeID, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(closure, fiber.StatusCreated))
+}
+
+func (h *StoreHoursHandler) removeClosure(ctx *fiber.Ctx, storeID int) error {
+	closureID, err := ctx.ParamsInt("closureId")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.Service.RemoveClosure(ctx.UserContext(), middleware.GetUser(ctx), storeID, closureID); err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(true, fiber.StatusOK))
+}
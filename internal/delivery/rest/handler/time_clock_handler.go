@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"coffee/internal/model"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type TimeClockHandler struct {
+	Service model.TimeClockService
+	Log     *logrus.Logger
+}
+
+func NewTimeClockHandler(service model.TimeClockService, log *logrus.Logger) model.TimeClockHandler {
+	return &TimeClockHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *TimeClockHandler) ClockIn(ctx *fiber.Ctx) error {
+	return h.clock(ctx, h.Service.ClockIn)
+}
+
+func (h *TimeClockHandler) ClockOut(ctx *fiber.Ctx) error {
+	return h.clock(ctx, h.Service.ClockOut)
+}
+
+func (h *TimeClockHandler) StartBreak(ctx *fiber.Ctx) error {
+	return h.clock(ctx, h.Service.StartBreak)
+}
+
+func (h *TimeClockHandler) EndBreak(ctx *fiber.Ctx) error {
+	return h.clock(ctx, h.Service.EndBreak)
+}
+
+func (h *TimeClockHandler) clock(ctx *fiber.Ctx, action func(context.Context, string, *model.ClockRequest) (*model.ClockResponse, error)) error {
+	request := new(model.ClockRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	response, err := action(ctx.UserContext(), ctx.Params("slug"), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(response, fiber.StatusOK))
+}
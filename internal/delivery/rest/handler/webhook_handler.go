@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookHandler struct {
+	Service model.WebhookService
+	Log     *logrus.Logger
+}
+
+func NewWebhookHandler(service model.WebhookService, log *logrus.Logger) model.WebhookHandler {
+	return &WebhookHandler{
+		Service: service,
+		Log:     log,
+	}
+}
+
+func (h *WebhookHandler) Subscriptions(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	subscriptions, err := h.Service.Subscriptions(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(subscriptions, fiber.StatusOK))
+}
+
+func (h *WebhookHandler) CreateSubscription(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.CreateWebhookRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	subscription, err := h.Service.CreateSubscription(ctx.UserContext(), middleware.GetUser(ctx), id, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(subscription, fiber.StatusCreated))
+}
+
+func (h *WebhookHandler) UpdateSubscription(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	webhookID, err := ctx.ParamsInt("webhookId")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.UpdateWebhookRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	subscription, err := h.Service.UpdateSubscription(ctx.UserContext(), middleware.GetUser(ctx), id, webhookID, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(subscription, fiber.StatusOK))
+}
+
+func (h *WebhookHandler) RemoveSubscription(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	webhookID, err := ctx.ParamsInt("webhookId")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	if err := h.Service.RemoveSubscription(ctx.UserContext(), middleware.GetUser(ctx), id, webhookID); err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(true, fiber.StatusOK))
+}
+
+func (h *WebhookHandler) Deliveries(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	webhookID, err := ctx.ParamsInt("webhookId")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	request := new(model.WebhookDeliveryListRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	deliveries, err := h.Service.Deliveries(ctx.UserContext(), middleware.GetUser(ctx), id, webhookID, request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.NewWebResponse(deliveries, fiber.StatusOK))
+}
+
+func (h *WebhookHandler) Replay(ctx *fiber.Ctx) error {
+	id, err := ctx.ParamsInt("id")
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+
+	delivery, err := h.Service.Replay(ctx.UserContext(), middleware.GetUser(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.NewWebResponse(delivery, fiber.StatusCreated))
+}
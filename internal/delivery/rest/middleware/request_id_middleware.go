@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"coffee/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	fiberutils "github.com/gofiber/fiber/v2/utils"
+)
+
+// RequestIDMiddleware tags every request with an id, the caller's own
+// X-Request-ID if it sent a sane one, and echoes it back. Services get it
+// through the user context so audit entries can point at the request.
+func RequestIDMiddleware() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		id := ctx.Get(fiber.HeaderXRequestID)
+		if id == "" || len(id) > 100 {
+			id = fiberutils.UUIDv4()
+		}
+
+		ctx.Set(fiber.HeaderXRequestID, id)
+		ctx.SetUserContext(model.WithRequestID(ctx.UserContext(), id))
+
+		return ctx.Next()
+	}
+}
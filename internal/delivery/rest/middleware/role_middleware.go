@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewRoleMiddleware only lets users through whose role is one of roles.
+// It must run after the auth middleware.
+func NewRoleMiddleware(roles ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		auth := GetUser(ctx)
+		if !slices.Contains(roles, auth.Role) {
+			return fiber.ErrForbidden
+		}
+
+		return ctx.Next()
+	}
+}
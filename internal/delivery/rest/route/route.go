@@ -2,8 +2,12 @@ package route
 
 import (
 	"coffee/internal/delivery/rest/middleware"
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/spf13/viper"
 )
 
@@ -11,14 +15,39 @@ type  RouteConfig struct {
 	Viper				*viper.Viper
 	App 				*fiber.App
 	AuthMiddleware		fiber.Handler
+	ReportHandler		model.ReportHandler
+	OrderHandler		model.OrderHandler
+	ProjectionHandler	model.ProjectionHandler
+	ShiftHandler		model.ShiftHandler
+	PaymentHandler		model.PaymentHandler
+	ScheduleHandler		model.ScheduleHandler
+	TimeClockHandler	model.TimeClockHandler
+	CustomerHandler		model.CustomerHandler
+	StampHandler		model.StampHandler
+	MenuHandler			model.MenuHandler
+	CartHandler			model.CartHandler
+	PickupHandler		model.PickupHandler
+	StoreHoursHandler	model.StoreHoursHandler
+	StationHandler		model.StationHandler
+	ReceiptHandler		model.ReceiptHandler
+	PrinterHandler		model.PrinterHandler
+	WebhookHandler		model.WebhookHandler
+	AuditHandler		model.AuditHandler
+	PriceHandler		model.PriceHandler
+	CatalogHandler		model.CatalogHandler
+	CategoryHandler		model.CategoryHandler
+	ImageHandler		model.ImageHandler
 }
 
 func (c *RouteConfig) Setup(){
 	c.SetupMiddleware()
+	c.SetupGuestRoute()
+	c.SetupAuthRoute()
 }
 
 func (c *RouteConfig) SetupMiddleware() {
 	c.App.Use(middleware.CORSMiddleware(c.Viper))
+	c.App.Use(middleware.RequestIDMiddleware())
 }
 
 func (c *RouteConfig) SetupGuestRoute() {
@@ -28,10 +57,145 @@ func (c *RouteConfig) SetupGuestRoute() {
 		})
 	})
 
+	c.App.Get("/media/*", c.ImageHandler.Serve)
+
+	// The store tablet has no token, PINs are short, so keep guessing slow.
+	kiosk := c.App.Group("/kiosk/:slug", limiter.New(limiter.Config{
+		Max:        10,
+		Expiration: time.Minute,
+	}))
+	kiosk.Post("/clock-in", c.TimeClockHandler.ClockIn)
+	kiosk.Post("/clock-out", c.TimeClockHandler.ClockOut)
+	kiosk.Post("/break/start", c.TimeClockHandler.StartBreak)
+	kiosk.Post("/break/end", c.TimeClockHandler.EndBreak)
+
+	// QR table ordering. Guests hold a cart token instead of a JWT.
+	public := c.App.Group("/public", limiter.New(limiter.Config{
+		Max:        60,
+		Expiration: time.Minute,
+	}))
+	public.Get("/stores/:slug", c.StoreHoursHandler.Status)
+	public.Get("/stores/:slug/menu", c.MenuHandler.BySlug)
+	public.Post("/stores/:slug/tables/:table/carts", c.CartHandler.Open)
+	public.Get("/stores/:slug/pickup-slots", c.PickupHandler.PublicSlots)
+
+	carts := public.Group("/carts/:token")
+	carts.Get("/", c.CartHandler.Get)
+	carts.Post("/items", c.CartHandler.AddItem)
+	carts.Delete("/items/:index", c.CartHandler.RemoveItem)
+	carts.Post("/checkout", limiter.New(limiter.Config{
+		Max:        5,
+		Expiration: time.Minute,
+	}), c.CartHandler.Checkout)
 }
 
 func (c *RouteConfig) SetupAuthRoute() {
 	auth := c.App.Group("/api")
 	auth.Use(c.AuthMiddleware)
 
+	managers := middleware.NewRoleMiddleware(entity.RoleManager, entity.RoleAdmin)
+	admins := middleware.NewRoleMiddleware(entity.RoleAdmin)
+
+	auth.Get("/menu", c.MenuHandler.ForStore)
+
+	orders := auth.Group("/orders")
+	orders.Post("/", c.OrderHandler.Place)
+	orders.Get("/queue", c.OrderHandler.Queue)
+	orders.Patch("/:id/status", c.OrderHandler.UpdateStatus)
+	orders.Post("/:id/payments", c.PaymentHandler.Capture)
+	orders.Get("/:id/tickets", c.StationHandler.OrderTickets)
+	orders.Get("/:id/receipt", c.ReceiptHandler.Receipt)
+
+	auth.Get("/stations/:id/tickets", c.StationHandler.Queue)
+	auth.Post("/tickets/:id/bump", c.OrderHandler.BumpTicket)
+	auth.Get("/tickets/:id/slip", c.ReceiptHandler.Ticket)
+	auth.Post("/print-jobs/:id/reprint", c.PrinterHandler.Reprint)
+	auth.Post("/webhook-deliveries/:id/replay", managers, c.WebhookHandler.Replay)
+
+	stores := auth.Group("/stores/:id")
+	stores.Get("/pickup-slots", c.PickupHandler.Slots)
+	stores.Get("/pickup-settings", c.PickupHandler.Settings)
+	stores.Put("/pickup-settings", managers, c.PickupHandler.UpdateSettings)
+	stores.Get("/opening-hours", c.StoreHoursHandler.Hours)
+	stores.Put("/opening-hours", managers, c.StoreHoursHandler.UpdateHours)
+	stores.Put("/timezone", managers, c.StoreHoursHandler.UpdateTimezone)
+	stores.Get("/closures", c.StoreHoursHandler.Closures)
+	stores.Post("/closures", managers, c.StoreHoursHandler.AddClosure)
+	stores.Delete("/closures/:closureId", managers, c.StoreHoursHandler.RemoveClosure)
+	stores.Get("/availability-windows", c.MenuHandler.Windows)
+	stores.Post("/availability-windows", managers, c.MenuHandler.AddWindow)
+	stores.Delete("/availability-windows/:windowId", managers, c.MenuHandler.RemoveWindow)
+	stores.Get("/stations", c.StationHandler.Stations)
+	stores.Post("/stations", managers, c.StationHandler.CreateStation)
+	stores.Patch("/stations/:stationId", managers, c.StationHandler.UpdateStation)
+	stores.Get("/station-routes", c.StationHandler.Routes)
+	stores.Post("/station-routes", managers, c.StationHandler.AddRoute)
+	stores.Delete("/station-routes/:routeId", managers, c.StationHandler.RemoveRoute)
+	stores.Get("/printers", c.PrinterHandler.Printers)
+	stores.Post("/printers", managers, c.PrinterHandler.CreatePrinter)
+	stores.Patch("/printers/:printerId", managers, c.PrinterHandler.UpdatePrinter)
+	stores.Get("/print-jobs", c.PrinterHandler.Jobs)
+	stores.Get("/webhooks", managers, c.WebhookHandler.Subscriptions)
+	stores.Post("/webhooks", managers, c.WebhookHandler.CreateSubscription)
+	stores.Patch("/webhooks/:webhookId", managers, c.WebhookHandler.UpdateSubscription)
+	stores.Delete("/webhooks/:webhookId", managers, c.WebhookHandler.RemoveSubscription)
+	stores.Get("/webhooks/:webhookId/deliveries", managers, c.WebhookHandler.Deliveries)
+	stores.Get("/catalog", admins, c.CatalogHandler.Export)
+	stores.Post("/catalog", admins, c.CatalogHandler.Import)
+	stores.Post("/clone", admins, c.CatalogHandler.Clone)
+	stores.Get("/categories", c.CategoryHandler.StoreCategories)
+	stores.Put("/categories/order", managers, c.CategoryHandler.Reorder)
+	stores.Patch("/categories/:categoryId", managers, c.CategoryHandler.UpdateStoreCategory)
+
+	categories := auth.Group("/categories")
+	categories.Get("/", c.CategoryHandler.List)
+	categories.Post("/", admins, c.CategoryHandler.Create)
+	categories.Patch("/:id", admins, c.CategoryHandler.Update)
+	categories.Delete("/:id", admins, c.CategoryHandler.Remove)
+
+	menuItems := auth.Group("/menu-items/:id")
+	menuItems.Get("/prices", c.PriceHandler.History)
+	menuItems.Post("/prices", managers, c.PriceHandler.Schedule)
+	menuItems.Delete("/prices/:priceId", managers, c.PriceHandler.Cancel)
+	menuItems.Get("/price", c.PriceHandler.PriceAt)
+	menuItems.Post("/image", admins, c.ImageHandler.UploadMenuItemImage)
+	menuItems.Put("/tags", admins, c.MenuHandler.UpdateTags)
+
+	customers := auth.Group("/customers")
+	customers.Post("/", c.CustomerHandler.Register)
+	customers.Get("/", c.CustomerHandler.Lookup)
+	customers.Get("/:id", c.CustomerHandler.Get)
+	customers.Get("/:id/ledger", c.CustomerHandler.Ledger)
+	customers.Get("/:id/stamps", c.CustomerHandler.Stamps)
+
+	stamps := auth.Group("/stamp-programs")
+	stamps.Get("/", c.StampHandler.ListPrograms)
+	stamps.Post("/", admins, c.StampHandler.CreateProgram)
+	stamps.Patch("/:id", admins, c.StampHandler.UpdateProgram)
+
+	shifts := auth.Group("/shifts")
+	shifts.Post("/", c.ShiftHandler.Open)
+	shifts.Get("/current", c.ShiftHandler.Current)
+	shifts.Post("/:id/movements", c.ShiftHandler.AddMovement)
+	shifts.Post("/:id/close", c.ShiftHandler.Close)
+	shifts.Get("/:id/report", c.ShiftHandler.Report)
+
+	schedules := auth.Group("/schedules", managers)
+	schedules.Post("/", c.ScheduleHandler.Create)
+	schedules.Get("/", c.ScheduleHandler.List)
+	schedules.Delete("/:id", c.ScheduleHandler.Remove)
+
+	auth.Get("/timesheets", managers, c.ScheduleHandler.Timesheet)
+	auth.Get("/audit", managers, c.AuditHandler.Search)
+
+	reports := auth.Group("/reports", managers)
+	reports.Get("/sales-mix", c.ReportHandler.SalesMix)
+	reports.Get("/prep-times", c.ReportHandler.PrepTimes)
+	reports.Get("/analytics", c.ReportHandler.SalesAnalytics)
+
+	admin := auth.Group("/admin", admins)
+	admin.Post("/projections/rebuild", c.ProjectionHandler.Rebuild)
+	admin.Get("/holidays", c.StoreHoursHandler.Holidays)
+	admin.Post("/holidays", c.StoreHoursHandler.AddHoliday)
+	admin.Delete("/holidays/:closureId", c.StoreHoursHandler.RemoveHoliday)
 }
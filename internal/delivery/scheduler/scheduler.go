@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job is background work repeated on a fixed interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Start runs every job in its own goroutine until ctx is done. A failed run
+// is logged and retried on the next tick. Jobs without an interval are off.
+func Start(ctx context.Context, log *logrus.Logger, jobs ...Job) {
+	for _, job := range jobs {
+		if job.Interval <= 0 {
+			log.Warnf("job %s has no interval, not starting it", job.Name)
+			continue
+		}
+		go run(ctx, log, job)
+	}
+}
+
+func run(ctx context.Context, log *logrus.Logger, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job.Run(ctx); err != nil {
+				log.Warnf("job %s failed: %v", job.Name, err)
+			}
+		}
+	}
+}
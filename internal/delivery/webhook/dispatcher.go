@@ -0,0 +1,163 @@
+// Package webhook posts recorded webhook deliveries to their subscribers.
+// Payloads are signed so a receiver can tell they came from us:
+//
+//	X-Coffee-Signature: t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+//
+// keyed with the subscription's secret.
+package webhook
+
+import (
+	"bytes"
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	batchSize       = 20
+	maxResponseBody = 1024
+	maxRetryDelay   = 6 * time.Hour
+)
+
+type Dispatcher struct {
+	Repo        model.WebhookRepository
+	Client      *http.Client
+	Log         *logrus.Logger
+	MaxAttempts int
+	RetryAfter  time.Duration // doubled after every failed attempt
+}
+
+func NewDispatcher(repo model.WebhookRepository, viper *viper.Viper, log *logrus.Logger) *Dispatcher {
+	dispatcher := &Dispatcher{
+		Repo:        repo,
+		Log:         log,
+		MaxAttempts: max(viper.GetInt("webhooks.max_attempts"), 1),
+		RetryAfter:  viper.GetDuration("webhooks.retry_after"),
+	}
+	if dispatcher.RetryAfter <= 0 {
+		dispatcher.RetryAfter = 30 * time.Second
+	}
+
+	timeout := viper.GetDuration("webhooks.timeout")
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	dispatcher.Client = &http.Client{
+		Timeout: timeout,
+		// A redirect would resend the payload somewhere nobody subscribed.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return dispatcher
+}
+
+// Run sends the deliveries that are due, a batch at a time, until none are
+// left. It is meant to be run by the scheduler.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		deliveries, err := d.Repo.ClaimDue(ctx, batchSize, 2*d.Client.Timeout)
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			wg.Add(1)
+			go func(delivery *model.DueDelivery) {
+				defer wg.Done()
+				d.Deliver(ctx, delivery)
+			}(&deliveries[i])
+		}
+		wg.Wait()
+
+		if len(deliveries) < batchSize {
+			return nil
+		}
+	}
+
+	return ctx.Err()
+}
+
+// Deliver makes one attempt at a claimed delivery. A failed attempt is
+// retried later until the attempts run out, then the delivery is marked
+// failed and waits for a replay.
+func (d *Dispatcher) Deliver(ctx context.Context, due *model.DueDelivery) {
+	delivery := &due.WebhookDelivery
+	delivery.ResponseStatus = nil
+	delivery.ResponseBody = ""
+	delivery.LastError = ""
+	delivery.NextAttemptAt = nil
+
+	if err := d.send(ctx, due); err != nil {
+		delivery.LastError = err.Error()
+		delivery.Status = entity.DeliveryFailed
+		if delivery.Attempts < d.MaxAttempts {
+			delivery.Status = entity.DeliveryPending
+			next := time.Now().Add(min(d.RetryAfter<<(delivery.Attempts-1), maxRetryDelay))
+			delivery.NextAttemptAt = &next
+		}
+	} else {
+		delivery.Status = entity.DeliveryDelivered
+	}
+
+	if err := d.Repo.FinishDelivery(ctx, delivery); err != nil {
+		d.Log.Warnf("failed to record webhook delivery %d: %v", delivery.ID, err)
+		return
+	}
+	if delivery.Status == entity.DeliveryFailed {
+		d.Log.Warnf("webhook delivery %d failed after %d attempts: %s", delivery.ID, delivery.Attempts, delivery.LastError)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, due *model.DueDelivery) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, due.URL, bytes.NewReader(due.Payload))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "coffee-webhooks/1")
+	request.Header.Set("X-Coffee-Event", due.Event)
+	request.Header.Set("X-Coffee-Delivery", due.EventID)
+	request.Header.Set("X-Coffee-Signature", fmt.Sprintf("t=%d,v1=%s", timestamp, Sign(due.Secret, timestamp, due.Payload)))
+
+	response, err := d.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxResponseBody))
+	due.ResponseStatus = &response.StatusCode
+	due.ResponseBody = string(bytes.ToValidUTF8(body, nil))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("subscriber answered %s", response.Status)
+	}
+
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
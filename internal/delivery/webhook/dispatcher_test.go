@@ -0,0 +1,209 @@
+package webhook
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type fakeRepo struct {
+	model.WebhookRepository
+	finished []entity.WebhookDelivery
+}
+
+func (f *fakeRepo) FinishDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	f.finished = append(f.finished, *delivery)
+	return nil
+}
+
+func newTestDispatcher() (*Dispatcher, *fakeRepo) {
+	repo := &fakeRepo{}
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	config := viper.New()
+	config.Set("webhooks.max_attempts", 4)
+	config.Set("webhooks.retry_after", "30s")
+	config.Set("webhooks.timeout", "1s")
+
+	return NewDispatcher(repo, config, log), repo
+}
+
+func dueDelivery(url string, attempts int) *model.DueDelivery {
+	return &model.DueDelivery{
+		WebhookDelivery: entity.WebhookDelivery{
+			ID:       7,
+			EventID:  "evt-1",
+			Event:    entity.EventOrderStatusChanged,
+			Payload:  []byte(`{"order_id":42}`),
+			Status:   entity.DeliveryPending,
+			Attempts: attempts,
+		},
+		URL:    url,
+		Secret: "whsec_test",
+	}
+}
+
+func TestSign(t *testing.T) {
+	got := Sign("whsec_test", 1700000000, []byte(`{"order_id":42}`))
+	want := "bfbec325673aa36de87fdfc2d2588e678273eca774c52f93a07eda8b59998102"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if Sign("other", 1700000000, []byte(`{"order_id":42}`)) == want {
+		t.Error("the signature does not depend on the secret")
+	}
+	if Sign("whsec_test", 1700000001, []byte(`{"order_id":42}`)) == want {
+		t.Error("the signature does not depend on the timestamp")
+	}
+}
+
+func TestDeliverSignsAndSucceeds(t *testing.T) {
+	var header http.Header
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.Write([]byte("thanks"))
+	}))
+	defer receiver.Close()
+
+	dispatcher, repo := newTestDispatcher()
+	dispatcher.Deliver(context.Background(), dueDelivery(receiver.URL, 1))
+
+	if string(body) != `{"order_id":42}` {
+		t.Errorf("receiver got %q", body)
+	}
+	if header.Get("X-Coffee-Event") != entity.EventOrderStatusChanged || header.Get("X-Coffee-Delivery") != "evt-1" {
+		t.Errorf("wrong event headers: %v", header)
+	}
+
+	// A receiver checks the signature the way the package doc describes.
+	var timestamp int64
+	var signature string
+	for _, part := range strings.Split(header.Get("X-Coffee-Signature"), ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			signature = value
+		}
+	}
+	if signature != Sign("whsec_test", timestamp, body) {
+		t.Errorf("signature %q does not verify", header.Get("X-Coffee-Signature"))
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age < 0 || age > time.Minute {
+		t.Errorf("signature timestamp is %s old", age)
+	}
+
+	delivery := repo.finished[0]
+	if delivery.Status != entity.DeliveryDelivered || delivery.NextAttemptAt != nil {
+		t.Errorf("delivery is %s, want delivered", delivery.Status)
+	}
+	if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusOK || delivery.ResponseBody != "thanks" {
+		t.Errorf("response not recorded: %v %q", delivery.ResponseStatus, delivery.ResponseBody)
+	}
+}
+
+func TestDeliverBacksOffThenFails(t *testing.T) {
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	dispatcher, repo := newTestDispatcher()
+	for attempt := 1; attempt <= dispatcher.MaxAttempts; attempt++ {
+		before := time.Now()
+		dispatcher.Deliver(context.Background(), dueDelivery(receiver.URL, attempt))
+		delivery := repo.finished[len(repo.finished)-1]
+
+		if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusServiceUnavailable {
+			t.Fatalf("attempt %d: response status not recorded", attempt)
+		}
+		if !strings.Contains(delivery.LastError, "503") {
+			t.Errorf("attempt %d: error %q does not name the status", attempt, delivery.LastError)
+		}
+
+		if attempt == dispatcher.MaxAttempts {
+			if delivery.Status != entity.DeliveryFailed || delivery.NextAttemptAt != nil {
+				t.Errorf("last attempt: delivery is %s, want failed without a retry", delivery.Status)
+			}
+			continue
+		}
+		if delivery.Status != entity.DeliveryPending || delivery.NextAttemptAt == nil {
+			t.Fatalf("attempt %d: delivery is %s, want pending with a retry", attempt, delivery.Status)
+		}
+		want := dispatcher.RetryAfter << (attempt - 1)
+		if delay := delivery.NextAttemptAt.Sub(before); delay < want || delay > want+time.Second {
+			t.Errorf("attempt %d: retry in %s, want %s", attempt, delay, want)
+		}
+	}
+
+	if calls != dispatcher.MaxAttempts {
+		t.Errorf("receiver was called %d times, want %d", calls, dispatcher.MaxAttempts)
+	}
+}
+
+func TestDeliverCapsBackoff(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	dispatcher, repo := newTestDispatcher()
+	dispatcher.MaxAttempts = 20
+	before := time.Now()
+	dispatcher.Deliver(context.Background(), dueDelivery(receiver.URL, 15))
+
+	delivery := repo.finished[0]
+	if delay := delivery.NextAttemptAt.Sub(before); delay < maxRetryDelay || delay > maxRetryDelay+time.Second {
+		t.Errorf("retry in %s, want the %s cap", delay, maxRetryDelay)
+	}
+}
+
+func TestDeliverDoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+	receiver := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer receiver.Close()
+
+	dispatcher, repo := newTestDispatcher()
+	dispatcher.Deliver(context.Background(), dueDelivery(receiver.URL, 1))
+
+	if followed {
+		t.Error("the payload was sent on to the redirect target")
+	}
+	if delivery := repo.finished[0]; delivery.Status != entity.DeliveryPending {
+		t.Errorf("a redirect counted as %s", delivery.Status)
+	}
+}
+
+func TestDeliverUnreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+
+	dispatcher, repo := newTestDispatcher()
+	dispatcher.Deliver(context.Background(), dueDelivery(url, 1))
+
+	delivery := repo.finished[0]
+	if delivery.Status != entity.DeliveryPending || delivery.ResponseStatus != nil || delivery.LastError == "" {
+		t.Errorf("unreachable receiver: %s, status %v, error %q", delivery.Status, delivery.ResponseStatus, delivery.LastError)
+	}
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditEntry records who changed what. It lives in Mongo next to the order
+// projections. StoreID is 0 for changes that apply to every store.
+type AuditEntry struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	At         time.Time          `bson:"at" json:"at"`
+	ActorID    string             `bson:"actor_id" json:"actor_id"`
+	ActorRole  string             `bson:"actor_role" json:"actor_role"`
+	StoreID    int                `bson:"store_id" json:"store_id"`
+	EntityType string             `bson:"entity_type" json:"entity_type"`
+	EntityID   int                `bson:"entity_id" json:"entity_id"`
+	Action     string             `bson:"action" json:"action"`
+	Changes    []AuditChange      `bson:"changes" json:"changes"`
+	RequestID  string             `bson:"request_id,omitempty" json:"request_id,omitempty"`
+}
+
+// AuditChange is one field that changed, by its JSON path, e.g. "hours.0.opens_at".
+type AuditChange struct {
+	Field  string `bson:"field" json:"field"`
+	Before any    `bson:"before" json:"before"`
+	After  any    `bson:"after" json:"after"`
+}
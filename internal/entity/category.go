@@ -2,11 +2,15 @@ package entity
 
 import "time"
 
+// DefaultCategoryColor is the color categories get when none is picked.
+const DefaultCategoryColor = "#4B3621"
+
 type Category struct {
 	ID        int       `db:"id" json:"id"`
 	Name      string    `db:"name" json:"name"`
 	Icon      string    `db:"icon" json:"icon,omitempty"`
 	Color     string    `db:"color" json:"color"`
+	ParentID  *int      `db:"parent_id" json:"parent_id,omitempty"`
 	IsActive  bool      `db:"is_active" json:"is_active"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 }
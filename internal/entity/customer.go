@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+const (
+	LoyaltyEarn   = "earn"
+	LoyaltyRedeem = "redeem"
+	LoyaltyExpire = "expire"
+	LoyaltyRefund = "refund"
+)
+
+type Customer struct {
+	ID            int       `db:"id" json:"id"`
+	Phone         string    `db:"phone" json:"phone"`
+	FullName      string    `db:"full_name" json:"full_name,omitempty"`
+	Email         string    `db:"email" json:"email,omitempty"`
+	Tier          string    `db:"tier" json:"tier"`
+	PointsBalance int64     `db:"points_balance" json:"points_balance"`
+	LifetimeSpend int64     `db:"lifetime_spend" json:"lifetime_spend"` // IDR
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type LoyaltyEntry struct {
+	ID         int        `db:"id" json:"id"`
+	CustomerID int        `db:"customer_id" json:"customer_id"`
+	OrderID    *int       `db:"order_id" json:"order_id,omitempty"`
+	Type       string     `db:"type" json:"type"`
+	Points     int64      `db:"points" json:"points"` // negative for redeem and expire
+	Remaining  int64      `db:"remaining" json:"remaining"`
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	Note       string     `db:"note" json:"note,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,10 @@
+package entity
+
+// Names of the domain events, as published on the event stream and to
+// webhook subscribers.
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventPaymentCaptured    = "payment.captured"
+	EventMenuItemChanged    = "menu_item.changed"
+)
@@ -1,17 +1,23 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type MenuItem struct {
-	ID          int       `db:"id" json:"id"`
-	Name        string    `db:"name" json:"name"`
-	Description string    `db:"description" json:"description,omitempty"`
-	BasePrice   int64     `db:"base_price" json:"base_price"` // IDR: no cents
-	CategoryID  int       `db:"category_id" json:"category_id"`
-	IsActive    bool      `db:"is_active" json:"is_active"`
-	ImageURL    string    `db:"image_url" json:"image_url,omitempty"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	ID          int            `db:"id" json:"id"`
+	Name        string         `db:"name" json:"name"`
+	Description string         `db:"description" json:"description,omitempty"`
+	BasePrice   int64          `db:"base_price" json:"base_price"` // IDR: no cents
+	CategoryID  int            `db:"category_id" json:"category_id"`
+	IsActive    bool           `db:"is_active" json:"is_active"`
+	ImageURL    string         `db:"image_url" json:"image_url,omitempty"`
+	Tags        pq.StringArray `db:"tags" json:"tags"`
+	Allergens   pq.StringArray `db:"allergens" json:"allergens"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
 }
 
 type StoreMenu struct {
@@ -22,4 +28,33 @@ type StoreMenu struct {
 	IsAvailable    bool      `db:"is_available" json:"is_available"`
 	SortOrder      int       `db:"sort_order" json:"sort_order"`
 	CreatedAt      time.Time `db:"created_at" json:"created_at"`
-}
\ No newline at end of file
+}
+
+// MenuAvailabilityWindow limits when a store sells a menu item or a whole
+// category. Empty fields do not limit anything.
+type MenuAvailabilityWindow struct {
+	ID          int       `db:"id" json:"id"`
+	StoreID     int       `db:"store_id" json:"store_id"`
+	MenuItemID  *int      `db:"menu_item_id" json:"menu_item_id,omitempty"`
+	CategoryID  *int      `db:"category_id" json:"category_id,omitempty"`
+	WeekdayMask int       `db:"weekday_mask" json:"-"` // bit 0 is Sunday
+	Weekdays    []int     `db:"-" json:"weekdays"`
+	StartsAt    string    `db:"starts_at" json:"starts_at,omitempty"` // 15:04
+	EndsAt      string    `db:"ends_at" json:"ends_at,omitempty"`
+	ValidFrom   string    `db:"valid_from" json:"valid_from,omitempty"` // 2006-01-02
+	ValidUntil  string    `db:"valid_until" json:"valid_until,omitempty"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// MenuItemPrice is one entry of a menu item's price history. Without a
+// StoreID it sets the base price; a store entry without a Price ends the
+// store's override.
+type MenuItemPrice struct {
+	ID            int       `db:"id" json:"id"`
+	MenuItemID    int       `db:"menu_item_id" json:"menu_item_id"`
+	StoreID       *int      `db:"store_id" json:"store_id,omitempty"`
+	Price         *int64    `db:"price" json:"price"`
+	EffectiveFrom time.Time `db:"effective_from" json:"effective_from"`
+	CreatedBy     *int      `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
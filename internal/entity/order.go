@@ -1,16 +1,45 @@
 package entity
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	OrderDineIn   = "dine_in"
+	OrderTakeaway = "takeaway"
+	OrderDelivery = "delivery"
+	OrderPickup   = "pickup" // pre-ordered for a pickup time
+)
+
+const (
+	OrderScheduled = "scheduled" // pre-order waiting to be released
+	OrderPending   = "pending"
+	OrderPreparing = "preparing"
+	OrderReady     = "ready"
+	OrderCompleted = "completed"
+	OrderCancelled = "cancelled"
+)
 
 type Order struct {
-	ID            int       `db:"id" json:"id"`
-	StoreID       int       `db:"store_id" json:"store_id"`
-	OrderNumber   string    `db:"order_number" json:"order_number"`
-	Status        string    `db:"status" json:"status"` // pending, preparing, ready, etc.
-	Total         int64     `db:"total" json:"total"`   // IDR
-	CustomerNote  string    `db:"customer_note" json:"customer_note,omitempty"`
-	CreatedAt     time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+	ID              int        `db:"id" json:"id"`
+	StoreID         int        `db:"store_id" json:"store_id"`
+	OrderNumber     string     `db:"order_number" json:"order_number"`
+	Status          string     `db:"status" json:"status"` // pending, preparing, ready, etc.
+	OrderType       string     `db:"order_type" json:"order_type"`
+	Subtotal        int64      `db:"subtotal" json:"subtotal"`
+	Discount        int64      `db:"discount_total" json:"discount_total"`
+	Fees            int64      `db:"fee_total" json:"fee_total"` // packaging, delivery
+	Total           int64      `db:"total" json:"total"`         // IDR, subtotal - discount + fees
+	CustomerID      *int       `db:"customer_id" json:"customer_id,omitempty"`
+	CustomerName    string     `db:"customer_name" json:"customer_name,omitempty"`
+	PointsRedeemed  int64      `db:"points_redeemed" json:"points_redeemed,omitempty"`
+	TableNumber     string     `db:"table_number" json:"table_number,omitempty"`         // dine_in
+	PickupAt        *time.Time `db:"pickup_at" json:"pickup_at,omitempty"`               // pickup
+	DeliveryAddress string     `db:"delivery_address" json:"delivery_address,omitempty"` // delivery
+	CustomerNote    string     `db:"customer_note" json:"customer_note,omitempty"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
 }
 
 type OrderItem struct {
@@ -21,5 +50,45 @@ type OrderItem struct {
 	UnitPrice      int64       `db:"unit_price" json:"unit_price"`
 	Customizations interface{} `db:"customizations" json:"customizations,omitempty"` // JSONB
 	Note           string      `db:"note" json:"note,omitempty"`
+	StampProgramID *int        `db:"stamp_program_id" json:"stamp_program_id,omitempty"` // set on free reward items
+	StationID      *int        `db:"station_id" json:"station_id,omitempty"`
 	CreatedAt      time.Time   `db:"created_at" json:"created_at"`
-}
\ No newline at end of file
+}
+
+// CustomizationList decodes Customizations whether it was scanned from the
+// JSONB column or set in code.
+func (i *OrderItem) CustomizationList() []OrderItemCustomization {
+	var raw []byte
+	switch value := i.Customizations.(type) {
+	case []OrderItemCustomization:
+		return value
+	case []byte:
+		raw = value
+	case string:
+		raw = []byte(value)
+	default:
+		return nil
+	}
+
+	list := []OrderItemCustomization{}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil
+	}
+	return list
+}
+
+// OrderItemCustomization is one element of the OrderItem.Customizations JSONB array.
+type OrderItemCustomization struct {
+	GroupID         int    `json:"group_id" bson:"group_id"`
+	OptionID        int    `json:"option_id" bson:"option_id"`
+	Label           string `json:"label" bson:"label"`
+	AdditionalPrice int64  `json:"additional_price" bson:"additional_price"`
+}
+type OrderStatusHistory struct {
+	ID         int       `db:"id" json:"id"`
+	OrderID    int       `db:"order_id" json:"order_id"`
+	FromStatus string    `db:"from_status" json:"from_status"`
+	ToStatus   string    `db:"to_status" json:"to_status"`
+	ChangedBy  *int      `db:"changed_by" json:"changed_by,omitempty"`
+	ChangedAt  time.Time `db:"changed_at" json:"changed_at"`
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "time"
+
+// OrderProjection is a completed order denormalized for reporting. It lives in
+// the Mongo reporting store, keyed by the Postgres order id.
+type OrderProjection struct {
+	OrderID      int                `bson:"_id" json:"order_id"`
+	OrderNumber  string             `bson:"order_number" json:"order_number"`
+	Store        ProjectedStore     `bson:"store" json:"store"`
+	Status       string             `bson:"status" json:"status"`
+	OrderType    string             `bson:"order_type" json:"order_type"`
+	Fees         int64              `bson:"fee_total" json:"fee_total"`
+	Total        int64              `bson:"total" json:"total"`
+	CustomerNote string             `bson:"customer_note,omitempty" json:"customer_note,omitempty"`
+	Items        []ProjectedItem    `bson:"items" json:"items"`
+	Payments     []ProjectedPayment `bson:"payments" json:"payments"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	CompletedAt  time.Time          `bson:"completed_at" json:"completed_at"`
+	ProjectedAt  time.Time          `bson:"projected_at" json:"projected_at"`
+}
+
+type ProjectedStore struct {
+	ID   int    `bson:"id" json:"id"`
+	Name string `bson:"name" json:"name"`
+	Slug string `bson:"slug" json:"slug"`
+}
+
+type ProjectedItem struct {
+	MenuItemID     int                      `bson:"menu_item_id" json:"menu_item_id"`
+	Name           string                   `bson:"name" json:"name"`
+	CategoryID     int                      `bson:"category_id" json:"category_id"`
+	CategoryName   string                   `bson:"category_name" json:"category_name"`
+	Quantity       int                      `bson:"quantity" json:"quantity"`
+	UnitPrice      int64                    `bson:"unit_price" json:"unit_price"`
+	Subtotal       int64                    `bson:"subtotal" json:"subtotal"`
+	Customizations []OrderItemCustomization `bson:"customizations" json:"customizations"`
+	Note           string                   `bson:"note,omitempty" json:"note,omitempty"`
+}
+
+type ProjectedPayment struct {
+	ID         int       `bson:"id" json:"id"`
+	Method     string    `bson:"method" json:"method"`
+	Amount     int64     `bson:"amount" json:"amount"`
+	Status     string    `bson:"status" json:"status"`
+	CapturedAt time.Time `bson:"captured_at" json:"captured_at"`
+}
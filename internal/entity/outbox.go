@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxMessage is an event waiting to be relayed. Messages of one order are
+// relayed in the order they were written.
+type OutboxMessage struct {
+	ID        int64           `db:"id" json:"id"`
+	Event     string          `db:"event" json:"event"`
+	StoreID   int             `db:"store_id" json:"store_id"`
+	OrderID   int             `db:"order_id" json:"order_id"`
+	Payload   json.RawMessage `db:"payload" json:"payload"`
+	Attempts  int             `db:"attempts" json:"attempts"`
+	LastError string          `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+	SentAt    *time.Time      `db:"sent_at" json:"sent_at,omitempty"`
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const (
+	PaymentCash = "cash"
+	PaymentCard = "card"
+	PaymentQRIS = "qris"
+)
+
+const (
+	PaymentCaptured = "captured"
+	PaymentRefunded = "refunded"
+)
+
+type Payment struct {
+	ID         int       `db:"id" json:"id"`
+	OrderID    int       `db:"order_id" json:"order_id"`
+	Method     string    `db:"method" json:"method"`
+	Amount     int64     `db:"amount" json:"amount"` // IDR
+	Status     string    `db:"status" json:"status"`
+	Reference  string    `db:"reference" json:"reference,omitempty"`
+	ShiftID    *int      `db:"shift_id" json:"shift_id,omitempty"` // cash only
+	ReceivedBy *int      `db:"received_by" json:"received_by,omitempty"`
+	Tendered   *int64    `db:"tendered" json:"tendered,omitempty"` // cash handed over, if counted
+	CapturedAt time.Time `db:"captured_at" json:"captured_at"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
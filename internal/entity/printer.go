@@ -0,0 +1,45 @@
+package entity
+
+import "time"
+
+const (
+	PrintReceipt = "receipt"
+	PrintTicket  = "ticket"
+)
+
+const (
+	PrintQueued   = "queued"
+	PrintPrinting = "printing"
+	PrintPrinted  = "printed"
+	PrintFailed   = "failed"
+)
+
+type Printer struct {
+	ID             int       `db:"id" json:"id"`
+	StoreID        int       `db:"store_id" json:"store_id"`
+	Name           string    `db:"name" json:"name"`
+	Host           string    `db:"host" json:"host"`
+	Port           int       `db:"port" json:"port"`
+	PaperWidth     int       `db:"paper_width" json:"paper_width"`         // mm, 58 or 80
+	StationID      *int      `db:"station_id" json:"station_id,omitempty"` // prints this station's tickets
+	PrintsReceipts bool      `db:"prints_receipts" json:"prints_receipts"`
+	IsActive       bool      `db:"is_active" json:"is_active"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type PrintJob struct {
+	ID        int        `db:"id" json:"id"`
+	PrinterID int        `db:"printer_id" json:"printer_id"`
+	OrderID   int        `db:"order_id" json:"order_id"`
+	TicketID  *int       `db:"ticket_id" json:"ticket_id,omitempty"`
+	Kind      string     `db:"kind" json:"kind"`
+	Status    string     `db:"status" json:"status"`
+	Attempts  int        `db:"attempts" json:"attempts"`
+	LastError string     `db:"last_error" json:"last_error,omitempty"`
+	CreatedBy *int       `db:"created_by" json:"created_by,omitempty"`
+	ReprintOf *int       `db:"reprint_of" json:"reprint_of,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+	PrintedAt *time.Time `db:"printed_at" json:"printed_at,omitempty"`
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+const (
+	ShiftOpen   = "open"
+	ShiftClosed = "closed"
+)
+
+const (
+	CashPayIn  = "pay_in"
+	CashPayOut = "pay_out"
+)
+
+type CashShift struct {
+	ID             int        `db:"id" json:"id"`
+	StoreID        int        `db:"store_id" json:"store_id"`
+	OpenedBy       int        `db:"opened_by" json:"opened_by"`
+	ClosedBy       *int       `db:"closed_by" json:"closed_by,omitempty"`
+	Status         string     `db:"status" json:"status"`
+	OpeningFloat   int64      `db:"opening_float" json:"opening_float"`
+	ExpectedAmount *int64     `db:"expected_amount" json:"expected_amount,omitempty"`
+	CountedAmount  *int64     `db:"counted_amount" json:"counted_amount,omitempty"`
+	Variance       *int64     `db:"variance" json:"variance,omitempty"` // counted - expected
+	Note           string     `db:"note" json:"note,omitempty"`
+	OpenedAt       time.Time  `db:"opened_at" json:"opened_at"`
+	ClosedAt       *time.Time `db:"closed_at" json:"closed_at,omitempty"`
+}
+
+type CashMovement struct {
+	ID        int       `db:"id" json:"id"`
+	ShiftID   int       `db:"shift_id" json:"shift_id"`
+	Type      string    `db:"type" json:"type"`
+	Amount    int64     `db:"amount" json:"amount"`
+	Reason    string    `db:"reason" json:"reason"`
+	CreatedBy *int      `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
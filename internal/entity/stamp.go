@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+const (
+	StampEarn   = "earn"
+	StampRedeem = "redeem"
+	StampRefund = "refund"
+)
+
+// StampProgram covers either a category or a single menu item, never both.
+type StampProgram struct {
+	ID             int       `db:"id" json:"id"`
+	Name           string    `db:"name" json:"name"`
+	CategoryID     *int      `db:"category_id" json:"category_id,omitempty"`
+	MenuItemID     *int      `db:"menu_item_id" json:"menu_item_id,omitempty"`
+	StampsRequired int       `db:"stamps_required" json:"stamps_required"` // stamps per free item
+	IsActive       bool      `db:"is_active" json:"is_active"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type StampCard struct {
+	ProgramID       int       `db:"program_id" json:"program_id"`
+	CustomerID      int       `db:"customer_id" json:"customer_id"`
+	Stamps          int       `db:"stamps" json:"stamps"`
+	RewardsRedeemed int       `db:"rewards_redeemed" json:"rewards_redeemed"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+const (
+	TicketOpen = "open"
+	TicketDone = "done"
+)
+
+type Station struct {
+	ID        int       `db:"id" json:"id"`
+	StoreID   int       `db:"store_id" json:"store_id"`
+	Name      string    `db:"name" json:"name"`
+	IsDefault bool      `db:"is_default" json:"is_default"` // takes unrouted items
+	IsActive  bool      `db:"is_active" json:"is_active"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// StationRoute sends either a menu item or a category to a station.
+type StationRoute struct {
+	ID         int       `db:"id" json:"id"`
+	StoreID    int       `db:"store_id" json:"store_id"`
+	StationID  int       `db:"station_id" json:"station_id"`
+	MenuItemID *int      `db:"menu_item_id" json:"menu_item_id,omitempty"`
+	CategoryID *int      `db:"category_id" json:"category_id,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// OrderTicket is the part of an order one station makes.
+type OrderTicket struct {
+	ID        int        `db:"id" json:"id"`
+	OrderID   int        `db:"order_id" json:"order_id"`
+	StationID int        `db:"station_id" json:"station_id"`
+	Status    string     `db:"status" json:"status"`
+	BumpedBy  *int       `db:"bumped_by" json:"bumped_by,omitempty"`
+	BumpedAt  *time.Time `db:"bumped_at" json:"bumped_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
@@ -10,7 +10,47 @@ type Store struct {
 	Phone     string    `db:"phone" json:"phone,omitempty"`
 	Email     string    `db:"email" json:"email,omitempty"`
 	StoreSlug string    `db:"store_slug" json:"store_slug"`
+	Timezone  string    `db:"timezone" json:"timezone"` // IANA name, e.g. Asia/Jakarta
 	IsActive  bool      `db:"is_active" json:"is_active"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-}
\ No newline at end of file
+}
+
+type StoreTable struct {
+	ID          int       `db:"id" json:"id"`
+	StoreID     int       `db:"store_id" json:"store_id"`
+	TableNumber string    `db:"table_number" json:"table_number"`
+	Label       string    `db:"label" json:"label,omitempty"`
+	IsActive    bool      `db:"is_active" json:"is_active"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// StoreOpeningHour is one weekday of a store's week, in store-local time
+// formatted as 15:04.
+type StoreOpeningHour struct {
+	StoreID  int    `db:"store_id" json:"store_id"`
+	Weekday  int    `db:"weekday" json:"weekday"` // 0 is Sunday
+	OpensAt  string `db:"opens_at" json:"opens_at"`
+	ClosesAt string `db:"closes_at" json:"closes_at"`
+}
+
+type PickupSettings struct {
+	StoreID         int       `db:"store_id" json:"store_id"`
+	SlotMinutes     int       `db:"slot_minutes" json:"slot_minutes"`
+	CapacityPerSlot int       `db:"capacity_per_slot" json:"capacity_per_slot"` // orders per slot
+	ReleaseMinutes  int       `db:"release_minutes" json:"release_minutes"`     // sent to the queue this long before pickup
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// StoreClosure overrides one day of the weekly hours. Without times the store
+// is closed all day; without a store it applies to every store.
+type StoreClosure struct {
+	ID        int       `db:"id" json:"id"`
+	StoreID   *int      `db:"store_id" json:"store_id,omitempty"`
+	ClosedOn  string    `db:"closed_on" json:"closed_on"` // 2006-01-02
+	OpensAt   string    `db:"opens_at" json:"opens_at,omitempty"`
+	ClosesAt  string    `db:"closes_at" json:"closes_at,omitempty"`
+	Reason    string    `db:"reason" json:"reason"`
+	CreatedBy *int      `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
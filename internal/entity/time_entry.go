@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+type StaffSchedule struct {
+	ID        int       `db:"id" json:"id"`
+	StoreID   int       `db:"store_id" json:"store_id"`
+	UserID    int       `db:"user_id" json:"user_id"`
+	StartsAt  time.Time `db:"starts_at" json:"starts_at"`
+	EndsAt    time.Time `db:"ends_at" json:"ends_at"`
+	Note      string    `db:"note" json:"note,omitempty"`
+	CreatedBy *int      `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type TimeEntry struct {
+	ID         int        `db:"id" json:"id"`
+	StoreID    int        `db:"store_id" json:"store_id"`
+	UserID     int        `db:"user_id" json:"user_id"`
+	ScheduleID *int       `db:"schedule_id" json:"schedule_id,omitempty"`
+	ClockInAt  time.Time  `db:"clock_in_at" json:"clock_in_at"`
+	ClockOutAt *time.Time `db:"clock_out_at" json:"clock_out_at,omitempty"`
+}
+
+type TimeEntryBreak struct {
+	ID          int        `db:"id" json:"id"`
+	TimeEntryID int        `db:"time_entry_id" json:"time_entry_id"`
+	StartedAt   time.Time  `db:"started_at" json:"started_at"`
+	EndedAt     *time.Time `db:"ended_at" json:"ended_at,omitempty"`
+}
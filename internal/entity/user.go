@@ -4,6 +4,12 @@ import (
 	"time"
 )
 
+const (
+	RoleBarista = "barista"
+	RoleManager = "manager"
+	RoleAdmin   = "admin"
+)
+
 type User struct {
 	ID           int       `db:"id" json:"id"`
 	FullName     string    `db:"full_name" json:"full_name"`
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+type WebhookSubscription struct {
+	ID        int            `db:"id" json:"id"`
+	StoreID   int            `db:"store_id" json:"store_id"`
+	URL       string         `db:"url" json:"url"`
+	Secret    string         `db:"secret" json:"secret,omitempty"` // only shown when created
+	Events    pq.StringArray `db:"events" json:"events"`
+	IsActive  bool           `db:"is_active" json:"is_active"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one subscription.
+// Every subscription gets the same EventID for the same event.
+type WebhookDelivery struct {
+	ID             int             `db:"id" json:"id"`
+	SubscriptionID int             `db:"subscription_id" json:"subscription_id"`
+	EventID        string          `db:"event_id" json:"event_id"`
+	Event          string          `db:"event" json:"event"`
+	Payload        json.RawMessage `db:"payload" json:"payload"`
+	Status         string          `db:"status" json:"status"`
+	Attempts       int             `db:"attempts" json:"attempts"`
+	ResponseStatus *int            `db:"response_status" json:"response_status,omitempty"`
+	ResponseBody   string          `db:"response_body" json:"response_body,omitempty"`
+	LastError      string          `db:"last_error" json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time      `db:"next_attempt_at" json:"next_attempt_at,omitempty"`
+	ReplayOf       *int            `db:"replay_of" json:"replay_of,omitempty"`
+	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updated_at"`
+	DeliveredAt    *time.Time      `db:"delivered_at" json:"delivered_at,omitempty"`
+}
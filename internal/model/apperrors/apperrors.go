@@ -1,5 +1,7 @@
 package apperrors
 
+import "fmt"
+
 type Type = int
 
 const (
@@ -52,13 +54,27 @@ func PasswordNotMatch() []APIError {
 // 	}
 // }
 
-// func NewBadRequest(reason string, errors []APIError) *Apperrors {
-// 	return &Apperrors{
-// 		Code:    BadRequest,
-// 		Message: fmt.Sprintf("Bad request. Reason: %v", reason),
-// 		Errors: errors,
-// 	}
-// }
+func NewBadRequest(reason string, errors []APIError) *Apperrors {
+	return &Apperrors{
+		Code:    BadRequest,
+		Message: fmt.Sprintf("Bad request. Reason: %v", reason),
+		Errors: errors,
+	}
+}
+
+func NewPayloadTooLarge(reason string) *Apperrors {
+	return &Apperrors{
+		Code:    PayloadTooLarge,
+		Message: fmt.Sprintf("Payload too large. Reason: %v", reason),
+	}
+}
+
+func NewUnsupportedMediaType(reason string) *Apperrors {
+	return &Apperrors{
+		Code:    UnsupportedMediaType,
+		Message: fmt.Sprintf("Unsupported media type. Reason: %v", reason),
+	}
+}
 
 // func NewConflict(name string, value string) *Apperrors {
 // 	return &Apperrors{
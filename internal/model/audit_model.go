@@ -0,0 +1,42 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type AuditSearchRequest struct {
+	StoreID    int    `query:"store_id"`
+	EntityType string `query:"entity_type" validate:"max=50"`
+	EntityID   int    `query:"entity_id"`
+	ActorID    string `query:"actor_id" validate:"max=50"`
+	RequestID  string `query:"request_id" validate:"max=100"`
+	From       string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To         string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=200"`
+}
+
+// AuditFilter is a search the repository runs; a zero field matches anything.
+type AuditFilter struct {
+	StoreID    *int
+	EntityType string
+	EntityID   int
+	ActorID    string
+	RequestID  string
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
+
+type requestIDKey struct{}
+
+// WithRequestID carries the id of the HTTP request down to the services.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID is the id of the HTTP request ctx belongs to, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -1,8 +1,39 @@
 package model
 
+import (
+	"coffee/internal/entity"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
 type Auth struct {
-	Id   string `json:"id,omitempty"`
-	Role string `json:"role,omitempty"`
+	Id      string `json:"id,omitempty"`
+	Role    string `json:"role,omitempty"`
+	StoreID int    `json:"store_id,omitempty"`
+}
+
+// UserID is the numeric users.id behind the token, 0 if it is not one.
+func (a *Auth) UserID() int {
+	id, _ := strconv.Atoi(a.Id)
+	return id
+}
+
+// ScopeStore resolves the store a request acts on. Admins may pick any store,
+// everyone else is pinned to the store on their token.
+func (a *Auth) ScopeStore(requested int) (int, error) {
+	if a.Role == entity.RoleAdmin {
+		if requested == 0 {
+			return 0, fiber.NewError(fiber.StatusBadRequest, "store_id is required")
+		}
+		return requested, nil
+	}
+
+	if requested != 0 && requested != a.StoreID {
+		return 0, fiber.ErrForbidden
+	}
+
+	return a.StoreID, nil
 }
 
 type SignUpRequest struct {
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"coffee/internal/entity"
+	"time"
+)
+
+// Cart is a guest's order in progress at a table. It lives in Redis under a
+// random token that stands in for a login.
+type Cart struct {
+	Token       string           `json:"token"`
+	StoreID     int              `json:"store_id"`
+	StoreSlug   string           `json:"store_slug"`
+	TableNumber string           `json:"table_number"`
+	Items       []PlaceOrderItem `json:"items"`
+	ExpiresAt   time.Time        `json:"expires_at"`
+}
+
+type CheckoutRequest struct {
+	CustomerName  string `json:"customer_name" validate:"max=100"`
+	CustomerPhone string `json:"customer_phone" validate:"omitempty,min=8,max=20"`
+	CustomerNote  string `json:"customer_note" validate:"max=500"`
+}
+
+type CartResponse struct {
+	Cart     *Cart              `json:"cart"`
+	Items    []entity.OrderItem `json:"items"` // priced lines, in cart order
+	Subtotal int64              `json:"subtotal"`
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// CategoryPathSeparator joins the names of a category and its ancestors into
+// a path, e.g. "Coffee > Espresso Based".
+const CategoryPathSeparator = " > "
+
+// Catalog is a store's whole menu in one document: the shared categories and
+// menu items with the store's own names, prices and order, and the store's
+// customization groups. Rows are matched by name on import, case-insensitive,
+// categories by their path; nothing missing from a catalog is deleted.
+type Catalog struct {
+	Categories []CatalogCategory `json:"categories"`
+	Items      []CatalogItem     `json:"items"`
+	Groups     []CatalogGroup    `json:"groups"`
+}
+
+// CatalogCategory is a category with how the store shows it. Parent is the
+// path of its parent category, empty for a top-level one. Flags left out
+// default to true.
+type CatalogCategory struct {
+	Row       string `db:"-" json:"-"` // where the row came from, for error reports
+	Name      string `db:"name" json:"name" validate:"required,max=50,excludes=>"`
+	Parent    string `db:"parent" json:"parent,omitempty" validate:"max=255"`
+	Icon      string `db:"icon" json:"icon,omitempty" validate:"max=50"`
+	Color     string `db:"color" json:"color,omitempty" validate:"omitempty,hexcolor,len=7"`
+	IsActive  *bool  `db:"is_active" json:"is_active,omitempty"`
+	StoreName string `db:"store_name" json:"store_name,omitempty" validate:"max=50"`
+	IsVisible *bool  `db:"is_visible" json:"is_visible,omitempty"`
+	SortOrder int    `db:"sort_order" json:"sort_order"`
+}
+
+// CatalogItem is a menu item as the store sells it. Category is the path of
+// its category. Price is the store's own price, none means the base price.
+// Groups are the names of the store's customization groups offered on the
+// item. Tags and allergens left out are kept as they are.
+type CatalogItem struct {
+	Row         string         `db:"-" json:"-"`
+	Name        string         `db:"name" json:"name" validate:"required,max=100"`
+	Category    string         `db:"category" json:"category" validate:"required,max=255"`
+	Description string         `db:"description" json:"description,omitempty"`
+	BasePrice   int64          `db:"base_price" json:"base_price" validate:"min=0"`
+	Price       *int64         `db:"price" json:"price,omitempty" validate:"omitempty,min=0"`
+	IsActive    *bool          `db:"is_active" json:"is_active,omitempty"`
+	IsAvailable *bool          `db:"is_available" json:"is_available,omitempty"`
+	SortOrder   int            `db:"sort_order" json:"sort_order"`
+	ImageURL    string         `db:"image_url" json:"image_url,omitempty" validate:"omitempty,url"`
+	Groups      pq.StringArray `db:"groups" json:"groups,omitempty" validate:"max=20,dive,required,max=50"`
+	Tags        pq.StringArray `db:"tags" json:"tags,omitempty" validate:"max=20,dive,required,max=30"`
+	Allergens   pq.StringArray `db:"allergens" json:"allergens,omitempty" validate:"dive,oneof=gluten crustaceans eggs fish peanuts soy milk tree-nuts celery mustard sesame sulphites lupin molluscs"`
+}
+
+// CategoryPath is the path of a category named name under the given parent
+// path.
+func CategoryPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + CategoryPathSeparator + name
+}
+
+// CategoryPathKey is what a path is matched by: case and the spacing around
+// the separators do not count.
+func CategoryPathKey(path string) string {
+	names := strings.Split(path, ">")
+	for i := range names {
+		names[i] = strings.ToLower(strings.TrimSpace(names[i]))
+	}
+	return strings.Join(names, CategoryPathSeparator)
+}
+
+type CatalogGroup struct {
+	Row        string          `db:"-" json:"-"`
+	ID         int             `db:"id" json:"-"`
+	Name       string          `db:"name" json:"name" validate:"required,max=50"`
+	IsRequired bool            `db:"is_required" json:"is_required"`
+	SortOrder  int             `db:"sort_order" json:"sort_order"`
+	Options    []CatalogOption `db:"-" json:"options"`
+}
+
+type CatalogOption struct {
+	Row             string `db:"-" json:"-"`
+	GroupID         int    `db:"group_id" json:"-"`
+	Label           string `db:"label" json:"label" validate:"required,max=50"`
+	AdditionalPrice int64  `db:"additional_price" json:"additional_price" validate:"min=0"`
+	IsAvailable     *bool  `db:"is_available" json:"is_available,omitempty"`
+	SortOrder       int    `db:"sort_order" json:"sort_order"`
+}
+
+type ExportCatalogRequest struct {
+	Format string `query:"format" validate:"omitempty,oneof=json csv"`
+}
+
+type ImportCatalogRequest struct {
+	Format string `query:"format" validate:"omitempty,oneof=json csv"`
+	DryRun bool   `query:"dry_run"`
+}
+
+// ImportResult tells what an import did, or with DryRun what it would do.
+// With errors nothing is written.
+type ImportResult struct {
+	DryRun     bool          `json:"dry_run"`
+	Applied    bool          `json:"applied"`
+	Categories ImportCount   `json:"categories"`
+	Items      ImportCount   `json:"items"`
+	Groups     ImportCount   `json:"groups"`
+	Options    ImportCount   `json:"options"`
+	Errors     []ImportError `json:"errors"`
+	// MenuItemIDs are the menu items the import wrote.
+	MenuItemIDs []int `json:"-"`
+}
+
+type ImportCount struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// ImportError is a problem with one row: "line 12" of a CSV file or
+// "items[3]" of a JSON one.
+type ImportError struct {
+	Row     string `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// CloneStoreRequest copies the menu setup of the source store into a store
+// that has none yet.
+type CloneStoreRequest struct {
+	SourceStoreID      int  `json:"source_store_id" validate:"required"`
+	SkipPriceOverrides bool `json:"skip_price_overrides"` // the new store sells at base prices
+}
+
+type CloneStoreResult struct {
+	SourceStoreID int `json:"source_store_id"`
+	Categories    int `json:"categories"`
+	Items         int `json:"items"`
+	Groups        int `json:"groups"`
+	Options       int `json:"options"`
+	Links         int `json:"links"` // groups offered on menu items
+	// MenuItemIDs are the menu items the target store now sells.
+	MenuItemIDs []int `json:"-"`
+}
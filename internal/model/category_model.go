@@ -0,0 +1,47 @@
+package model
+
+import "coffee/internal/entity"
+
+type ListCategoriesRequest struct {
+	IncludeInactive bool `query:"include_inactive"`
+}
+
+// CreateCategoryRequest adds a category, under ParentID when it is set.
+type CreateCategoryRequest struct {
+	Name     string `json:"name" validate:"required,max=50"`
+	Icon     string `json:"icon" validate:"omitempty,max=50"`
+	Color    string `json:"color" validate:"omitempty,hexcolor"`
+	ParentID int    `json:"parent_id" validate:"min=0"`
+}
+
+// UpdateCategoryRequest changes the fields it has. An empty icon clears it
+// and a parent_id of 0 moves the category to the top.
+type UpdateCategoryRequest struct {
+	Name     string  `json:"name" validate:"omitempty,max=50"`
+	Icon     *string `json:"icon" validate:"omitempty,max=50"`
+	Color    string  `json:"color" validate:"omitempty,hexcolor"`
+	ParentID *int    `json:"parent_id" validate:"omitempty,min=0"`
+	IsActive *bool   `json:"is_active"`
+}
+
+// StoreCategoryDetail is a category as one store shows it: the global
+// category, the store's name for it and where it sits on the store's menu.
+type StoreCategoryDetail struct {
+	entity.Category
+	StoreName string `db:"store_name" json:"store_name,omitempty"`
+	IsVisible bool   `db:"is_visible" json:"is_visible"`
+	SortOrder int    `db:"sort_order" json:"sort_order"`
+}
+
+// UpdateStoreCategoryRequest renames or hides a category at one store. An
+// empty name goes back to the global one.
+type UpdateStoreCategoryRequest struct {
+	Name      *string `json:"name" validate:"omitempty,max=50"`
+	IsVisible *bool   `json:"is_visible"`
+}
+
+// ReorderCategoriesRequest puts the listed categories first on the store's
+// menu, in that order. The others follow in the order they had.
+type ReorderCategoriesRequest struct {
+	CategoryIDs []int `json:"category_ids" validate:"required,min=1,unique,dive,required"`
+}
@@ -0,0 +1,306 @@
+package converter
+
+import (
+	"coffee/internal/model"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A catalog CSV has one row per category, customization group, option or
+// menu item, told apart by the kind column. Columns a kind does not use stay
+// empty. The parent column of a category and the category column of an item
+// hold category paths such as "Coffee > Espresso Based". The group column of
+// an option names its group; that of an item lists the item's groups
+// separated by "|", and so do its tags and allergens columns. A file without
+// those two columns leaves the tags and allergens of its items alone.
+var catalogColumns = []string{
+	"kind", "name", "parent", "category", "group", "description", "icon", "color", "store_name",
+	"base_price", "price", "is_active", "is_available", "is_visible", "is_required", "sort_order", "image_url",
+	"tags", "allergens",
+}
+
+const (
+	kindCategory = "category"
+	kindGroup    = "group"
+	kindOption   = "option"
+	kindItem     = "item"
+)
+
+func CatalogToCSV(w io.Writer, catalog *model.Catalog) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(catalogColumns); err != nil {
+		return err
+	}
+
+	for _, category := range catalog.Categories {
+		row := catalogRow{
+			"kind": kindCategory, "name": category.Name, "parent": category.Parent, "icon": category.Icon, "color": category.Color,
+			"store_name": category.StoreName, "is_active": formatFlag(category.IsActive),
+			"is_visible": formatFlag(category.IsVisible), "sort_order": strconv.Itoa(category.SortOrder),
+		}
+		if err := out.Write(row.values()); err != nil {
+			return err
+		}
+	}
+
+	for _, group := range catalog.Groups {
+		row := catalogRow{
+			"kind": kindGroup, "name": group.Name, "is_required": strconv.FormatBool(group.IsRequired),
+			"sort_order": strconv.Itoa(group.SortOrder),
+		}
+		if err := out.Write(row.values()); err != nil {
+			return err
+		}
+		for _, option := range group.Options {
+			row := catalogRow{
+				"kind": kindOption, "name": option.Label, "group": group.Name,
+				"price": strconv.FormatInt(option.AdditionalPrice, 10), "is_available": formatFlag(option.IsAvailable),
+				"sort_order": strconv.Itoa(option.SortOrder),
+			}
+			if err := out.Write(row.values()); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, item := range catalog.Items {
+		row := catalogRow{
+			"kind": kindItem, "name": item.Name, "category": item.Category, "group": strings.Join(item.Groups, "|"),
+			"description": item.Description, "base_price": strconv.FormatInt(item.BasePrice, 10),
+			"is_active": formatFlag(item.IsActive), "is_available": formatFlag(item.IsAvailable),
+			"sort_order": strconv.Itoa(item.SortOrder), "image_url": item.ImageURL,
+			"tags": strings.Join(item.Tags, "|"), "allergens": strings.Join(item.Allergens, "|"),
+		}
+		if item.Price != nil {
+			row["price"] = strconv.FormatInt(*item.Price, 10)
+		}
+		if err := out.Write(row.values()); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// CatalogFromCSV reads a catalog CSV. Rows that do not parse come back as
+// errors, one per row, and are left out of the catalog; the error is for a
+// file that is not a catalog at all.
+func CatalogFromCSV(r io.Reader) (*model.Catalog, []model.ImportError, error) {
+	in := csv.NewReader(r)
+	in.FieldsPerRecord = -1
+	in.TrimLeadingSpace = true
+
+	header, err := in.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading header: %w", err)
+	}
+	index := map[string]int{}
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, required := range []string{"kind", "name"} {
+		if _, ok := index[required]; !ok {
+			return nil, nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	catalog := &model.Catalog{
+		Categories: []model.CatalogCategory{},
+		Items:      []model.CatalogItem{},
+		Groups:     []model.CatalogGroup{},
+	}
+	rowErrors := []model.ImportError{}
+	groups := map[string]int{}
+	var options []model.CatalogOption
+	var optionGroups []string
+
+	for {
+		record, err := in.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			rowErrors = append(rowErrors, model.ImportError{Row: fmt.Sprintf("line %d", parseErr.StartLine), Message: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := in.FieldPos(0)
+		ref := fmt.Sprintf("line %d", line)
+
+		row := catalogRow{}
+		for column, i := range index {
+			if i < len(record) {
+				row[column] = strings.TrimSpace(record[i])
+			}
+		}
+		if row.empty() {
+			continue
+		}
+
+		parser := &rowParser{row: row}
+		switch strings.ToLower(row["kind"]) {
+		case kindCategory:
+			catalog.Categories = append(catalog.Categories, model.CatalogCategory{
+				Row:       ref,
+				Name:      row["name"],
+				Parent:    row["parent"],
+				Icon:      row["icon"],
+				Color:     row["color"],
+				StoreName: row["store_name"],
+				IsActive:  parser.flag("is_active"),
+				IsVisible: parser.flag("is_visible"),
+				SortOrder: parser.intValue("sort_order"),
+			})
+		case kindGroup:
+			groups[strings.ToLower(row["name"])] = len(catalog.Groups)
+			catalog.Groups = append(catalog.Groups, model.CatalogGroup{
+				Row:        ref,
+				Name:       row["name"],
+				IsRequired: parser.boolValue("is_required"),
+				SortOrder:  parser.intValue("sort_order"),
+				Options:    []model.CatalogOption{},
+			})
+		case kindOption:
+			options = append(options, model.CatalogOption{
+				Row:             ref,
+				Label:           row["name"],
+				AdditionalPrice: parser.int64Value("price"),
+				IsAvailable:     parser.flag("is_available"),
+				SortOrder:       parser.intValue("sort_order"),
+			})
+			optionGroups = append(optionGroups, row["group"])
+		case kindItem:
+			item := model.CatalogItem{
+				Row:         ref,
+				Name:        row["name"],
+				Category:    row["category"],
+				Description: row["description"],
+				BasePrice:   parser.int64Value("base_price"),
+				IsActive:    parser.flag("is_active"),
+				IsAvailable: parser.flag("is_available"),
+				SortOrder:   parser.intValue("sort_order"),
+				ImageURL:    row["image_url"],
+			}
+			if row["price"] != "" {
+				price := parser.int64Value("price")
+				item.Price = &price
+			}
+			item.Groups = splitList(row["group"])
+			if _, ok := row["tags"]; ok {
+				item.Tags = splitList(row["tags"])
+			}
+			if _, ok := row["allergens"]; ok {
+				item.Allergens = splitList(row["allergens"])
+			}
+			catalog.Items = append(catalog.Items, item)
+		default:
+			parser.fail("kind", fmt.Sprintf("unknown kind %q", row["kind"]))
+		}
+
+		for _, problem := range parser.problems {
+			problem.Row = ref
+			rowErrors = append(rowErrors, problem)
+		}
+	}
+
+	// Options may come before their group in the file.
+	for i, option := range options {
+		at, ok := groups[strings.ToLower(optionGroups[i])]
+		if !ok {
+			rowErrors = append(rowErrors, model.ImportError{Row: option.Row, Field: "group", Message: fmt.Sprintf("unknown customization group %q", optionGroups[i])})
+			continue
+		}
+		catalog.Groups[at].Options = append(catalog.Groups[at].Options, option)
+	}
+
+	return catalog, rowErrors, nil
+}
+
+// splitList reads a "|" separated column; an empty one is an empty list.
+func splitList(value string) []string {
+	list := []string{}
+	for _, name := range strings.Split(value, "|") {
+		if name = strings.TrimSpace(name); name != "" {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// catalogRow is one CSV row by column name.
+type catalogRow map[string]string
+
+func (r catalogRow) values() []string {
+	values := make([]string, len(catalogColumns))
+	for i, column := range catalogColumns {
+		values[i] = r[column]
+	}
+	return values
+}
+
+func (r catalogRow) empty() bool {
+	for _, value := range r {
+		if value != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// rowParser reads typed columns of a row and collects what does not parse.
+type rowParser struct {
+	row      catalogRow
+	problems []model.ImportError
+}
+
+func (p *rowParser) fail(column, message string) {
+	p.problems = append(p.problems, model.ImportError{Field: column, Message: message})
+}
+
+func (p *rowParser) intValue(column string) int {
+	return int(p.int64Value(column))
+}
+
+func (p *rowParser) int64Value(column string) int64 {
+	if p.row[column] == "" {
+		return 0
+	}
+	value, err := strconv.ParseInt(p.row[column], 10, 64)
+	if err != nil {
+		p.fail(column, fmt.Sprintf("%q is not a whole number", p.row[column]))
+	}
+	return value
+}
+
+func (p *rowParser) boolValue(column string) bool {
+	flag := p.flag(column)
+	return flag != nil && *flag
+}
+
+// flag is an optional true or false, nil when the column is empty.
+func (p *rowParser) flag(column string) *bool {
+	if p.row[column] == "" {
+		return nil
+	}
+	value, err := strconv.ParseBool(p.row[column])
+	if err != nil {
+		p.fail(column, fmt.Sprintf("%q is not true or false", p.row[column]))
+		return nil
+	}
+	return &value
+}
+
+func formatFlag(flag *bool) string {
+	if flag == nil {
+		return ""
+	}
+	return strconv.FormatBool(*flag)
+}
@@ -0,0 +1,22 @@
+package model
+
+import "coffee/internal/entity"
+
+type CreateCustomerRequest struct {
+	Phone    string `json:"phone" validate:"required,min=8,max=20"`
+	FullName string `json:"full_name" validate:"max=100"`
+	Email    string `json:"email" validate:"omitempty,email,max=100"`
+}
+
+type CustomerLookupRequest struct {
+	Phone string `query:"phone" validate:"required,min=8,max=20"`
+}
+
+type CustomerResponse struct {
+	Customer *entity.Customer `json:"customer"`
+	// NextTier is empty at the top tier.
+	NextTier      string `json:"next_tier,omitempty"`
+	SpendToNext   int64  `json:"spend_to_next_tier,omitempty"`
+	PointValue    int64  `json:"point_value"` // IDR discount per point
+	RedeemableIDR int64  `json:"redeemable_idr"`
+}
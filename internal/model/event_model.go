@@ -0,0 +1,109 @@
+package model
+
+import (
+	"coffee/internal/entity"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DomainEvent is the typed data of an event. Its JSON is the event's payload
+// on the stream and the data of webhooks.
+type DomainEvent interface {
+	EventName() string
+}
+
+type OrderPlaced struct {
+	Order *entity.Order      `json:"order"`
+	Items []entity.OrderItem `json:"items"`
+}
+
+func (*OrderPlaced) EventName() string { return entity.EventOrderCreated }
+
+type OrderStatusChanged struct {
+	Order *entity.Order `json:"order"`
+	From  string        `json:"from"`
+	To    string        `json:"to"`
+}
+
+func (*OrderStatusChanged) EventName() string { return entity.EventOrderStatusChanged }
+
+type PaymentCaptured struct {
+	Payment     *entity.Payment `json:"payment"`
+	Change      int64           `json:"change"`
+	Outstanding int64           `json:"outstanding"`
+}
+
+func (*PaymentCaptured) EventName() string { return entity.EventPaymentCaptured }
+
+// MenuItemChanged tells that what a store sells changed: one item, every item
+// of a category, or when both are zero, anything on the menu.
+type MenuItemChanged struct {
+	MenuItemID int    `json:"menu_item_id,omitempty"`
+	CategoryID int    `json:"category_id,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+func (*MenuItemChanged) EventName() string { return entity.EventMenuItemChanged }
+
+// DecodeEvent turns a payload read back from the stream into its typed event.
+func DecodeEvent(name string, payload []byte) (DomainEvent, error) {
+	var data DomainEvent
+	switch name {
+	case entity.EventOrderCreated:
+		data = new(OrderPlaced)
+	case entity.EventOrderStatusChanged:
+		data = new(OrderStatusChanged)
+	case entity.EventPaymentCaptured:
+		data = new(PaymentCaptured)
+	case entity.EventMenuItemChanged:
+		data = new(MenuItemChanged)
+	default:
+		return nil, fmt.Errorf("unknown event %q", name)
+	}
+
+	if err := json.Unmarshal(payload, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Event is a domain event as subscribers receive it. ID stays the same when
+// the event is delivered again, so subscribers can tell repeats.
+type Event struct {
+	ID         string
+	StoreID    int
+	OccurredAt time.Time
+	Data       DomainEvent
+}
+
+func (e *Event) Name() string {
+	return e.Data.EventName()
+}
+
+type EventHandler func(ctx context.Context, event *Event) error
+
+// EventBus lets modules react to each other. Synchronous subscribers run
+// before delivery of the event counts as done, and a failure has it
+// delivered again; asynchronous ones run in the background, once.
+type EventBus interface {
+	Publish(ctx context.Context, storeID int, data DomainEvent) error
+	Subscribe(name string, handler EventHandler)
+	SubscribeAsync(name string, handler EventHandler)
+}
+
+// StreamEvent is an entry of the event stream. OutboxID is empty for events
+// published straight to the stream.
+type StreamEvent struct {
+	StreamID  string
+	OutboxID  string
+	Name      string
+	StoreID   int
+	Payload   []byte
+	CreatedAt time.Time
+	// Deliveries counts how often the group handed the event to a reader,
+	// this time included.
+	Deliveries int64
+}
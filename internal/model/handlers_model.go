@@ -1,2 +1,162 @@
 package model
 
+import "github.com/gofiber/fiber/v2"
+
+type ReportHandler interface {
+	SalesMix(ctx *fiber.Ctx) error
+	PrepTimes(ctx *fiber.Ctx) error
+	SalesAnalytics(ctx *fiber.Ctx) error
+}
+
+type ProjectionHandler interface {
+	Rebuild(ctx *fiber.Ctx) error
+}
+
+type OrderHandler interface {
+	Place(ctx *fiber.Ctx) error
+	Queue(ctx *fiber.Ctx) error
+	UpdateStatus(ctx *fiber.Ctx) error
+	BumpTicket(ctx *fiber.Ctx) error
+}
+
+type ReceiptHandler interface {
+	Receipt(ctx *fiber.Ctx) error
+	Ticket(ctx *fiber.Ctx) error
+}
+
+type PrinterHandler interface {
+	Printers(ctx *fiber.Ctx) error
+	CreatePrinter(ctx *fiber.Ctx) error
+	UpdatePrinter(ctx *fiber.Ctx) error
+	Jobs(ctx *fiber.Ctx) error
+	Reprint(ctx *fiber.Ctx) error
+}
+
+type AuditHandler interface {
+	Search(ctx *fiber.Ctx) error
+}
+
+type WebhookHandler interface {
+	Subscriptions(ctx *fiber.Ctx) error
+	CreateSubscription(ctx *fiber.Ctx) error
+	UpdateSubscription(ctx *fiber.Ctx) error
+	RemoveSubscription(ctx *fiber.Ctx) error
+	Deliveries(ctx *fiber.Ctx) error
+	Replay(ctx *fiber.Ctx) error
+}
+
+type StationHandler interface {
+	Stations(ctx *fiber.Ctx) error
+	CreateStation(ctx *fiber.Ctx) error
+	UpdateStation(ctx *fiber.Ctx) error
+	Routes(ctx *fiber.Ctx) error
+	AddRoute(ctx *fiber.Ctx) error
+	RemoveRoute(ctx *fiber.Ctx) error
+	Queue(ctx *fiber.Ctx) error
+	OrderTickets(ctx *fiber.Ctx) error
+}
+
+type ShiftHandler interface {
+	Open(ctx *fiber.Ctx) error
+	Current(ctx *fiber.Ctx) error
+	AddMovement(ctx *fiber.Ctx) error
+	Close(ctx *fiber.Ctx) error
+	Report(ctx *fiber.Ctx) error
+}
+
+type PaymentHandler interface {
+	Capture(ctx *fiber.Ctx) error
+}
+
+type ScheduleHandler interface {
+	Create(ctx *fiber.Ctx) error
+	List(ctx *fiber.Ctx) error
+	Remove(ctx *fiber.Ctx) error
+	Timesheet(ctx *fiber.Ctx) error
+}
+
+type TimeClockHandler interface {
+	ClockIn(ctx *fiber.Ctx) error
+	ClockOut(ctx *fiber.Ctx) error
+	StartBreak(ctx *fiber.Ctx) error
+	EndBreak(ctx *fiber.Ctx) error
+}
+
+type CustomerHandler interface {
+	Register(ctx *fiber.Ctx) error
+	Lookup(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	Ledger(ctx *fiber.Ctx) error
+	Stamps(ctx *fiber.Ctx) error
+}
+
+type StampHandler interface {
+	CreateProgram(ctx *fiber.Ctx) error
+	UpdateProgram(ctx *fiber.Ctx) error
+	ListPrograms(ctx *fiber.Ctx) error
+}
+
+type MenuHandler interface {
+	ForStore(ctx *fiber.Ctx) error
+	BySlug(ctx *fiber.Ctx) error
+	Windows(ctx *fiber.Ctx) error
+	AddWindow(ctx *fiber.Ctx) error
+	RemoveWindow(ctx *fiber.Ctx) error
+	UpdateTags(ctx *fiber.Ctx) error
+}
+
+type CategoryHandler interface {
+	List(ctx *fiber.Ctx) error
+	Create(ctx *fiber.Ctx) error
+	Update(ctx *fiber.Ctx) error
+	Remove(ctx *fiber.Ctx) error
+	StoreCategories(ctx *fiber.Ctx) error
+	UpdateStoreCategory(ctx *fiber.Ctx) error
+	Reorder(ctx *fiber.Ctx) error
+}
+
+type CatalogHandler interface {
+	Export(ctx *fiber.Ctx) error
+	Import(ctx *fiber.Ctx) error
+	Clone(ctx *fiber.Ctx) error
+}
+
+type ImageHandler interface {
+	UploadMenuItemImage(ctx *fiber.Ctx) error
+	Serve(ctx *fiber.Ctx) error
+}
+
+type PriceHandler interface {
+	History(ctx *fiber.Ctx) error
+	Schedule(ctx *fiber.Ctx) error
+	Cancel(ctx *fiber.Ctx) error
+	PriceAt(ctx *fiber.Ctx) error
+}
+
+type CartHandler interface {
+	Open(ctx *fiber.Ctx) error
+	Get(ctx *fiber.Ctx) error
+	AddItem(ctx *fiber.Ctx) error
+	RemoveItem(ctx *fiber.Ctx) error
+	Checkout(ctx *fiber.Ctx) error
+}
+
+type PickupHandler interface {
+	Settings(ctx *fiber.Ctx) error
+	UpdateSettings(ctx *fiber.Ctx) error
+	Slots(ctx *fiber.Ctx) error
+	PublicSlots(ctx *fiber.Ctx) error
+}
+
+type StoreHoursHandler interface {
+	Status(ctx *fiber.Ctx) error
+	Hours(ctx *fiber.Ctx) error
+	UpdateHours(ctx *fiber.Ctx) error
+	UpdateTimezone(ctx *fiber.Ctx) error
+	Closures(ctx *fiber.Ctx) error
+	AddClosure(ctx *fiber.Ctx) error
+	RemoveClosure(ctx *fiber.Ctx) error
+	Holidays(ctx *fiber.Ctx) error
+	AddHoliday(ctx *fiber.Ctx) error
+	RemoveHoliday(ctx *fiber.Ctx) error
+}
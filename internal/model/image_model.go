@@ -0,0 +1,38 @@
+package model
+
+import (
+	"io"
+	"time"
+)
+
+// StoredFile describes a file in a FileStorage.
+type StoredFile struct {
+	Key         string
+	ContentType string
+	Size        int64
+	ModifiedAt  time.Time
+}
+
+// MediaFile is a stored file on its way to a client, with how long the
+// client may keep it.
+type MediaFile struct {
+	StoredFile
+	Body   io.ReadCloser
+	MaxAge time.Duration
+}
+
+// MenuItemImage is an uploaded menu item photo and its thumbnails, smallest
+// first.
+type MenuItemImage struct {
+	MenuItemID int            `json:"menu_item_id"`
+	URL        string         `json:"url"`
+	Width      int            `json:"width"`
+	Height     int            `json:"height"`
+	Thumbnails []ImageVariant `json:"thumbnails"`
+}
+
+type ImageVariant struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
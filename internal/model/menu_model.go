@@ -0,0 +1,78 @@
+package model
+
+import (
+	"coffee/internal/entity"
+
+	"github.com/lib/pq"
+)
+
+// MenuEntry is one row of a store's effective menu: what the store sells
+// right now, at the store's price.
+type MenuEntry struct {
+	StoreMenuItem
+	Description string            `db:"description" json:"description,omitempty"`
+	ImageURL    string            `db:"image_url" json:"image_url,omitempty"`
+	Tags        pq.StringArray    `db:"tags" json:"tags"`
+	Allergens   pq.StringArray    `db:"allergens" json:"allergens"`
+	Groups      []MenuOptionGroup `db:"-" json:"groups"`
+}
+
+type MenuOptionGroup struct {
+	ID         int          `json:"id"`
+	Name       string       `json:"name"`
+	IsRequired bool         `json:"is_required"`
+	Options    []MenuOption `json:"options"`
+}
+
+type MenuOption struct {
+	ID              int    `json:"id"`
+	Label           string `json:"label"`
+	AdditionalPrice int64  `json:"additional_price"`
+}
+
+// MenuCategory is a category on a store's menu under the store's name for
+// it, with the items filed right under it and its subcategories.
+type MenuCategory struct {
+	ID       int            `json:"id"`
+	Name     string         `json:"name"`
+	Icon     string         `json:"icon,omitempty"`
+	Color    string         `json:"color,omitempty"`
+	Items    []MenuEntry    `json:"items"`
+	Children []MenuCategory `json:"children"`
+}
+
+// MenuFilter narrows a menu down to the items with every one of Tags and
+// none of ExcludeAllergens. Both take repeated or comma separated values.
+type MenuFilter struct {
+	Tags             []string `query:"tag" validate:"max=20,dive,max=30"`
+	ExcludeAllergens []string `query:"exclude_allergen" validate:"dive,oneof=gluten crustaceans eggs fish peanuts soy milk tree-nuts celery mustard sesame sulphites lupin molluscs"`
+}
+
+// UpdateMenuItemTagsRequest replaces the tags and allergens of a menu item.
+// Tags are free-form, such as vegan, decaf-available or new; allergens come
+// from a fixed list.
+type UpdateMenuItemTagsRequest struct {
+	Tags      []string `json:"tags" validate:"max=20,dive,required,max=30"`
+	Allergens []string `json:"allergens" validate:"dive,oneof=gluten crustaceans eggs fish peanuts soy milk tree-nuts celery mustard sesame sulphites lupin molluscs"`
+}
+
+type StoreMenuResponse struct {
+	Store      *entity.Store  `json:"store"`
+	Categories []MenuCategory `json:"categories"`
+}
+
+// AvailabilityCheck tells whether a menu item of a category can be sold at
+// the moment it was resolved for.
+type AvailabilityCheck func(menuItemID, categoryID int) bool
+
+// CreateAvailabilityWindowRequest limits a menu item or a whole category to
+// some weekdays, a time of day and a date range. Empty fields do not limit.
+type CreateAvailabilityWindowRequest struct {
+	MenuItemID int    `json:"menu_item_id" validate:"required_without=CategoryID,excluded_with=CategoryID"`
+	CategoryID int    `json:"category_id" validate:"required_without=MenuItemID,excluded_with=MenuItemID"`
+	Weekdays   []int  `json:"weekdays" validate:"max=7,unique,dive,min=0,max=6"` // 0 is Sunday
+	StartsAt   string `json:"starts_at" validate:"required_with=EndsAt,omitempty,datetime=15:04"`
+	EndsAt     string `json:"ends_at" validate:"required_with=StartsAt,omitempty,datetime=15:04"`
+	ValidFrom  string `json:"valid_from" validate:"omitempty,datetime=2006-01-02"`
+	ValidUntil string `json:"valid_until" validate:"omitempty,datetime=2006-01-02"`
+}
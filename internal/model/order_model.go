@@ -0,0 +1,97 @@
+package model
+
+import (
+	"coffee/internal/entity"
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type UpdateOrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=pending preparing ready completed cancelled"` // pending releases a pre-order early
+}
+
+type PlaceOrderRequest struct {
+	StoreID         int              `json:"store_id"` // admins only, staff order for their own store
+	OrderType       string           `json:"order_type" validate:"omitempty,oneof=dine_in takeaway delivery pickup"`
+	TableNumber     string           `json:"table_number" validate:"required_if=OrderType dine_in,max=10"`
+	PickupAt        *time.Time       `json:"pickup_at" validate:"required_if=OrderType pickup"`
+	DeliveryAddress string           `json:"delivery_address" validate:"required_if=OrderType delivery,max=500"`
+	CustomerPhone   string           `json:"customer_phone" validate:"omitempty,min=8,max=20"`
+	CustomerName    string           `json:"customer_name" validate:"max=100"`
+	CustomerNote    string           `json:"customer_note" validate:"max=500"`
+	RedeemPoints    int64            `json:"redeem_points" validate:"min=0"`
+	Items           []PlaceOrderItem `json:"items" validate:"required,min=1,max=50,dive"`
+	// Rewards are free items paid for with stamps, one per program.
+	Rewards []PlaceOrderReward `json:"rewards" validate:"max=10,unique=ProgramID,dive"`
+}
+
+type PlaceOrderItem struct {
+	MenuItemID int    `json:"menu_item_id" validate:"required"`
+	Quantity   int    `json:"quantity" validate:"required,min=1,max=99"`
+	OptionIDs  []int  `json:"option_ids" validate:"max=20"`
+	Note       string `json:"note" validate:"max=255"`
+}
+
+type PlaceOrderReward struct {
+	ProgramID  int    `json:"program_id" validate:"required"`
+	MenuItemID int    `json:"menu_item_id" validate:"required"`
+	OptionIDs  []int  `json:"option_ids" validate:"max=20"`
+	Note       string `json:"note" validate:"max=255"`
+}
+
+type OrderResponse struct {
+	Order *entity.Order      `json:"order"`
+	Items []entity.OrderItem `json:"items"`
+}
+
+// OrderItemDetail is an order item joined with its menu item and category.
+type OrderItemDetail struct {
+	entity.OrderItem
+	MenuItemName string `db:"menu_item_name" json:"menu_item_name"`
+	CategoryID   int    `db:"category_id" json:"category_id"`
+	CategoryName string `db:"category_name" json:"category_name"`
+}
+
+// StoreMenuItem is a menu item as sold at one store, with the store price
+// already resolved.
+type StoreMenuItem struct {
+	MenuItemID  int    `db:"menu_item_id" json:"menu_item_id"`
+	Name        string `db:"name" json:"name"`
+	CategoryID  int    `db:"category_id" json:"category_id"`
+	Price       int64  `db:"price" json:"price"`
+	IsAvailable bool   `db:"is_available" json:"is_available"`
+}
+
+// MenuItemOption is a customization option offered on a menu item at a store.
+type MenuItemOption struct {
+	MenuItemID      int    `db:"menu_item_id" json:"menu_item_id"`
+	GroupID         int    `db:"group_id" json:"group_id"`
+	GroupName       string `db:"group_name" json:"group_name"`
+	IsRequired      bool   `db:"is_required" json:"is_required"`
+	OptionID        int    `db:"option_id" json:"option_id"`
+	Label           string `db:"label" json:"label"`
+	AdditionalPrice int64  `db:"additional_price" json:"additional_price"`
+}
+
+type OrderQueueRequest struct {
+	StoreID int    `query:"store_id"`
+	Type    string `query:"type" validate:"omitempty,oneof=dine_in takeaway delivery pickup"`
+}
+
+// QueueOrder is an open order as the barista sees it, items included.
+type QueueOrder struct {
+	*entity.Order
+	Items []OrderItemDetail `json:"items"`
+}
+
+// TxHook runs inside the transaction that stores a new order or changes its
+// status, after the order row is written. Returning an error rolls the whole
+// change back.
+type TxHook func(ctx context.Context, tx *sqlx.Tx, order *entity.Order) error
+
+// BumpTxHook runs inside the transaction that bumps a ticket, with the order
+// read again under its row lock and the number of tickets still open.
+// Returning an error rolls the bump back.
+type BumpTxHook func(ctx context.Context, tx *sqlx.Tx, order *entity.Order, open int) error
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"coffee/internal/entity"
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type CapturePaymentRequest struct {
+	Method    string `json:"method" validate:"required,oneof=cash card qris"`
+	Amount    int64  `json:"amount" validate:"required,gt=0"`
+	Tendered  int64  `json:"tendered" validate:"omitempty,gtefield=Amount"` // cash only
+	Reference string `json:"reference" validate:"max=100"`
+}
+
+type PaymentResponse struct {
+	Payment     *entity.Payment `json:"payment"`
+	Change      int64           `json:"change"`
+	Outstanding int64           `json:"outstanding"`
+}
+
+// PaymentTxHook runs inside the transaction that captures a payment, with the
+// balance left on the order after it.
+type PaymentTxHook func(ctx context.Context, tx *sqlx.Tx, payment *entity.Payment, outstanding int64) error
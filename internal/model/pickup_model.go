@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+type PickupSlotRequest struct {
+	Date string `query:"date" validate:"required,datetime=2006-01-02"`
+}
+
+type PickupSlotCount struct {
+	PickupAt time.Time `db:"pickup_at"`
+	Orders   int       `db:"orders"`
+}
+
+type PickupSlot struct {
+	StartsAt  time.Time `json:"starts_at"`
+	Capacity  int       `json:"capacity"`
+	Booked    int       `json:"booked"`
+	Available bool      `json:"available"` // not full and not in the past
+}
+
+type UpdatePickupSettingsRequest struct {
+	SlotMinutes     int `json:"slot_minutes" validate:"required,min=5,max=120"`
+	CapacityPerSlot int `json:"capacity_per_slot" validate:"min=0,max=1000"`
+	ReleaseMinutes  int `json:"release_minutes" validate:"min=0,max=240"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+type PriceHistoryRequest struct {
+	StoreID int `query:"store_id"` // admins without one see every store
+}
+
+// SchedulePriceRequest sets a menu item's price from a moment on, now when
+// EffectiveFrom is empty. Without a store it changes the base price; a store
+// price without a price ends the store's override.
+type SchedulePriceRequest struct {
+	StoreID       int    `json:"store_id"` // admins only may change the base price
+	Price         *int64 `json:"price" validate:"omitempty,min=0"`
+	EffectiveFrom string `json:"effective_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+type EffectivePriceRequest struct {
+	StoreID int    `query:"store_id"` // admins without one get the base price
+	At      string `query:"at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+type EffectivePriceResponse struct {
+	MenuItemID int       `json:"menu_item_id"`
+	StoreID    int       `json:"store_id,omitempty"`
+	At         time.Time `json:"at"`
+	Price      int64     `json:"price"`
+}
+
+// PriceChange is a store item whose price changed when scheduled prices came
+// due.
+type PriceChange struct {
+	StoreID    int `db:"store_id"`
+	MenuItemID int `db:"menu_item_id"`
+}
@@ -0,0 +1,27 @@
+package model
+
+type CreatePrinterRequest struct {
+	Name           string `json:"name" validate:"required,max=50"`
+	Host           string `json:"host" validate:"required,hostname|ip"`
+	Port           int    `json:"port" validate:"omitempty,min=1,max=65535"`
+	PaperWidth     int    `json:"paper_width" validate:"omitempty,oneof=58 80"`
+	StationID      int    `json:"station_id"`
+	PrintsReceipts bool   `json:"prints_receipts"`
+}
+
+// UpdatePrinterRequest changes the given fields. A station_id of 0 detaches
+// the printer from its station.
+type UpdatePrinterRequest struct {
+	Name           string `json:"name" validate:"omitempty,max=50"`
+	Host           string `json:"host" validate:"omitempty,hostname|ip"`
+	Port           int    `json:"port" validate:"omitempty,min=1,max=65535"`
+	PaperWidth     int    `json:"paper_width" validate:"omitempty,oneof=58 80"`
+	StationID      *int   `json:"station_id"`
+	PrintsReceipts *bool  `json:"prints_receipts"`
+	IsActive       *bool  `json:"is_active"`
+}
+
+type PrintJobListRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=queued printing printed failed"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=200"`
+}
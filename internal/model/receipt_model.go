@@ -0,0 +1,35 @@
+package model
+
+import (
+	"coffee/internal/entity"
+	"time"
+)
+
+// Receipt is everything printed on a customer receipt. Times are in the
+// store's timezone.
+type Receipt struct {
+	Store    *entity.Store     `json:"store"`
+	Order    *entity.Order     `json:"order"`
+	Items    []OrderItemDetail `json:"items"`
+	Payments []entity.Payment  `json:"payments"`
+	Tax      ReceiptTax        `json:"tax"`
+	IssuedAt time.Time         `json:"issued_at"`
+	Footer   string            `json:"footer,omitempty"`
+}
+
+// ReceiptTax is the tax already included in the prices, e.g. PB1.
+type ReceiptTax struct {
+	Name    string  `json:"name"`
+	Percent float64 `json:"percent"`
+	Amount  int64   `json:"amount"`
+}
+
+// TicketSlip is what a station prints for its part of an order.
+type TicketSlip struct {
+	Station  *entity.Station     `json:"station"`
+	Ticket   *entity.OrderTicket `json:"ticket"`
+	Order    *entity.Order       `json:"order"`
+	Items    []OrderItemDetail   `json:"items"`
+	PlacedAt time.Time           `json:"placed_at"`
+	PickupAt *time.Time          `json:"pickup_at,omitempty"`
+}
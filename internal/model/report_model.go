@@ -0,0 +1,161 @@
+package model
+
+import "time"
+
+type SalesMixRequest struct {
+	StoreID int    `query:"store_id"`
+	From    string `query:"from" validate:"required,datetime=2006-01-02"`
+	To      string `query:"to" validate:"required,datetime=2006-01-02"`
+	Sort    string `query:"sort" validate:"omitempty,oneof=revenue quantity"`
+	Format  string `query:"format" validate:"omitempty,oneof=json csv"`
+}
+
+type ReportPeriod struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"` // exclusive
+}
+
+// SalesMixRow is one aggregated line as read from the database. ParentID is
+// the category for menu items and the customization group for options.
+type SalesMixRow struct {
+	ID         int    `db:"id"`
+	Name       string `db:"name"`
+	ParentID   int    `db:"parent_id"`
+	ParentName string `db:"parent_name"`
+	Quantity   int64  `db:"quantity"`
+	Revenue    int64  `db:"revenue"`
+}
+
+type SalesMixLine struct {
+	ID               int      `json:"id"`
+	Name             string   `json:"name"`
+	ParentID         int      `json:"parent_id,omitempty"`
+	ParentName       string   `json:"parent_name,omitempty"`
+	Quantity         int64    `json:"quantity"`
+	Revenue          int64    `json:"revenue"`
+	PreviousQuantity int64    `json:"previous_quantity"`
+	PreviousRevenue  int64    `json:"previous_revenue"`
+	QuantityChange   *float64 `json:"quantity_change"` // percent, nil when there is nothing to compare against
+	RevenueChange    *float64 `json:"revenue_change"`
+	QuantityRank     int      `json:"quantity_rank"`
+	RevenueRank      int      `json:"revenue_rank"`
+}
+
+type SalesMixResponse struct {
+	StoreID        int            `json:"store_id"`
+	Period         ReportPeriod   `json:"period"`
+	PreviousPeriod ReportPeriod   `json:"previous_period"`
+	Items          []SalesMixLine `json:"items"`
+	Customizations []SalesMixLine `json:"customizations"`
+	Categories     []SalesMixLine `json:"categories"`
+}
+
+type PrepTimeRequest struct {
+	StoreID int    `query:"store_id"`
+	From    string `query:"from" validate:"required,datetime=2006-01-02"`
+	To      string `query:"to" validate:"required,datetime=2006-01-02"`
+}
+
+// OrderTimelineRow is an order with the first time it entered each status.
+// The barista is whoever moved it to preparing.
+type OrderTimelineRow struct {
+	OrderID     int        `db:"order_id"`
+	PendingAt   time.Time  `db:"pending_at"`
+	PreparingAt *time.Time `db:"preparing_at"`
+	ReadyAt     *time.Time `db:"ready_at"`
+	CompletedAt *time.Time `db:"completed_at"`
+	BaristaID   *int       `db:"barista_id"`
+	BaristaName *string    `db:"barista_name"`
+}
+
+// StageStats are durations in seconds.
+type StageStats struct {
+	Count   int     `json:"count"`
+	Average float64 `json:"average"`
+	P50     float64 `json:"p50"`
+	P90     float64 `json:"p90"`
+	P95     float64 `json:"p95"`
+}
+
+type PrepStages struct {
+	PendingToPreparing StageStats `json:"pending_to_preparing"`
+	PreparingToReady   StageStats `json:"preparing_to_ready"`
+	ReadyToCompleted   StageStats `json:"ready_to_completed"`
+}
+
+type HourlyPrepStats struct {
+	Hour   int        `json:"hour"`
+	Orders int        `json:"orders"`
+	Stages PrepStages `json:"stages"`
+}
+
+type BaristaPrepStats struct {
+	UserID   int               `json:"user_id"`
+	FullName string            `json:"full_name"`
+	Orders   int               `json:"orders"`
+	Stages   PrepStages        `json:"stages"`
+	Hours    []HourlyPrepStats `json:"hours"`
+}
+
+type ShiftThroughput struct {
+	Date     string `json:"date"`
+	Shift    string `json:"shift"`
+	UserID   int    `json:"user_id"`
+	FullName string `json:"full_name"`
+	Orders   int    `json:"orders"`
+}
+
+type PrepTimeResponse struct {
+	StoreID  int                `json:"store_id"`
+	Period   ReportPeriod       `json:"period"`
+	Orders   int                `json:"orders"`
+	Stages   PrepStages         `json:"stages"`
+	Hours    []HourlyPrepStats  `json:"hours"`
+	Baristas []BaristaPrepStats `json:"baristas"`
+	Shifts   []ShiftThroughput  `json:"shifts"`
+}
+
+type SalesAnalyticsRequest struct {
+	StoreID int    `query:"store_id"`
+	From    string `query:"from" validate:"required,datetime=2006-01-02"`
+	To      string `query:"to" validate:"required,datetime=2006-01-02"`
+	Limit   int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type DailySalesRow struct {
+	Date          string  `bson:"_id" json:"date"`
+	Orders        int     `bson:"orders" json:"orders"`
+	Revenue       int64   `bson:"revenue" json:"revenue"`
+	AverageTicket float64 `bson:"average_ticket" json:"average_ticket"`
+}
+
+type TopItemRow struct {
+	MenuItemID int    `bson:"_id" json:"menu_item_id"`
+	Name       string `bson:"name" json:"name"`
+	Quantity   int64  `bson:"quantity" json:"quantity"`
+	Revenue    int64  `bson:"revenue" json:"revenue"`
+}
+
+type PaymentMethodRow struct {
+	Method   string `bson:"_id" json:"method"`
+	Payments int    `bson:"payments" json:"payments"`
+	Amount   int64  `bson:"amount" json:"amount"`
+}
+
+type SalesAnalyticsResponse struct {
+	StoreID        int                `json:"store_id"`
+	Period         ReportPeriod       `json:"period"`
+	DailySales     []DailySalesRow    `json:"daily_sales"`
+	TopItems       []TopItemRow       `json:"top_items"`
+	PaymentMethods []PaymentMethodRow `json:"payment_methods"`
+}
+
+type RebuildProjectionRequest struct {
+	StoreID int    `json:"store_id"`
+	Since   string `json:"since" validate:"required,datetime=2006-01-02"`
+}
+
+type RebuildProjectionResponse struct {
+	Projected int `json:"projected"`
+	Failed    int `json:"failed"`
+}
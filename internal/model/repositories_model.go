@@ -3,6 +3,10 @@ package model
 import (
 	"coffee/internal/entity"
 	"context"
+	"io"
+	"time"
+
+	"github.com/jmoiron/sqlx"
 )
 
 type UserRepository interface {
@@ -12,6 +16,7 @@ type UserRepository interface {
 	// FindByUsername(ctx context.Context, username string) (*entity.User, error)
 	// FindById(ctx context.Context, Id string) (*entity.User, error)
 	// FindByEmail(ctx context.Context, email string) (*entity.User, error)
+	FindById(ctx context.Context, id int) (*entity.User, error)
 }
 
 type SessionRepo interface {
@@ -19,4 +24,236 @@ type SessionRepo interface {
 	Remove(ctx context.Context, request *entity.Session) (error)
 	FindByUserId(ctx context.Context,  record *entity.Session) (error)
 	FindByToken(ctx context.Context,  record *entity.Session) (error)
-}
\ No newline at end of file
+}
+type ReportRepository interface {
+	SalesMixByMenuItem(ctx context.Context, storeID int, from, to time.Time) ([]SalesMixRow, error)
+	SalesMixByCustomization(ctx context.Context, storeID int, from, to time.Time) ([]SalesMixRow, error)
+	OrderTimelines(ctx context.Context, storeID int, from, to time.Time) ([]OrderTimelineRow, error)
+}
+
+type OrderRepository interface {
+	FindById(ctx context.Context, id int) (*entity.Order, error)
+	UpdateStatus(ctx context.Context, order *entity.Order, status string, changedBy int, hooks ...TxHook) error
+	UpdateStatusTx(ctx context.Context, tx *sqlx.Tx, order *entity.Order, status string, changedBy int, hooks ...TxHook) error
+	FindItems(ctx context.Context, orderID int) ([]OrderItemDetail, error)
+	FindItemsByOrders(ctx context.Context, orderIDs []int) ([]OrderItemDetail, error)
+	FindQueue(ctx context.Context, storeID int, orderType string) ([]entity.Order, error)
+	ReleaseScheduled(ctx context.Context, defaultMinutes int, hooks ...TxHook) ([]int, error)
+	FindCompletedAt(ctx context.Context, orderID int) (time.Time, error)
+	FindCompletedIds(ctx context.Context, storeID int, since time.Time) ([]int, error)
+	Store(ctx context.Context, order *entity.Order, items []entity.OrderItem, hooks ...TxHook) error
+}
+
+type MenuRepository interface {
+	FindStoreItems(ctx context.Context, storeID int, menuItemIDs []int, at time.Time) ([]StoreMenuItem, error)
+	FindItemOptions(ctx context.Context, storeID int, menuItemIDs []int) ([]MenuItemOption, error)
+	FindStoreMenu(ctx context.Context, storeID int, at time.Time) ([]MenuEntry, error)
+	FindWindows(ctx context.Context, storeID int) ([]entity.MenuAvailabilityWindow, error)
+	FindWindow(ctx context.Context, id int) (*entity.MenuAvailabilityWindow, error)
+	StoreWindow(ctx context.Context, window *entity.MenuAvailabilityWindow) error
+	RemoveWindow(ctx context.Context, id int) error
+	FindPrices(ctx context.Context, menuItemID int, storeID int) ([]entity.MenuItemPrice, error)
+	FindPrice(ctx context.Context, id int) (*entity.MenuItemPrice, error)
+	StorePrice(ctx context.Context, price *entity.MenuItemPrice) error
+	RemovePrice(ctx context.Context, id int) error
+	FindItem(ctx context.Context, id int) (*entity.MenuItem, error)
+	UpdateImage(ctx context.Context, id int, imageURL string) error
+	UpdateTags(ctx context.Context, id int, tags, allergens []string) error
+	PriceAt(ctx context.Context, menuItemID int, storeID int, at time.Time) (int64, error)
+	ApplyDuePrices(ctx context.Context) ([]PriceChange, error)
+}
+
+type CategoryRepository interface {
+	FindAll(ctx context.Context, includeInactive bool) ([]entity.Category, error)
+	FindById(ctx context.Context, id int) (*entity.Category, error)
+	Save(ctx context.Context, category *entity.Category) error
+	Remove(ctx context.Context, id int) error
+	FindStoreCategories(ctx context.Context, storeID int) ([]StoreCategoryDetail, error)
+	FindStoreCategory(ctx context.Context, storeID, categoryID int) (*StoreCategoryDetail, error)
+	SaveStoreCategory(ctx context.Context, category *entity.StoreCategory) error
+	Reorder(ctx context.Context, storeID int, categoryIDs []int) error
+}
+
+type CatalogRepository interface {
+	Export(ctx context.Context, storeID int) (*Catalog, error)
+	Import(ctx context.Context, storeID int, catalog *Catalog, changedBy int, dryRun bool) (*ImportResult, error)
+	Clone(ctx context.Context, sourceID int, targetID int, skipPriceOverrides bool, changedBy int) (*CloneStoreResult, error)
+}
+
+// FileStorage keeps uploaded files under keys, slash separated paths such as
+// "menu-items/12/ab34.jpg".
+type FileStorage interface {
+	Put(ctx context.Context, key string, body io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, *StoredFile, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type StationRepository interface {
+	FindStations(ctx context.Context, storeID int) ([]entity.Station, error)
+	FindStation(ctx context.Context, id int) (*entity.Station, error)
+	SaveStation(ctx context.Context, station *entity.Station) error
+	FindRoutes(ctx context.Context, storeID int) ([]entity.StationRoute, error)
+	FindRoute(ctx context.Context, id int) (*entity.StationRoute, error)
+	StoreRoute(ctx context.Context, route *entity.StationRoute) error
+	RemoveRoute(ctx context.Context, id int) error
+	RouteTx(ctx context.Context, tx *sqlx.Tx, order *entity.Order) error
+	FindTicket(ctx context.Context, id int) (*entity.OrderTicket, error)
+	FindTickets(ctx context.Context, orderID int) ([]entity.OrderTicket, error)
+	FindOpenTickets(ctx context.Context, stationID int) ([]StationTicket, error)
+	CountOpenTickets(ctx context.Context, orderID int) (int, error)
+	Bump(ctx context.Context, ticket *entity.OrderTicket, order *entity.Order, bumpedBy int, hook BumpTxHook) (int, error)
+}
+
+type PrinterRepository interface {
+	FindPrinters(ctx context.Context, storeID int) ([]entity.Printer, error)
+	FindPrinter(ctx context.Context, id int) (*entity.Printer, error)
+	FindReceiptPrinters(ctx context.Context, storeID int) ([]entity.Printer, error)
+	FindStationPrinters(ctx context.Context, stationIDs []int) ([]entity.Printer, error)
+	SavePrinter(ctx context.Context, printer *entity.Printer) error
+	StoreJob(ctx context.Context, job *entity.PrintJob) error
+	FindJob(ctx context.Context, id int) (*entity.PrintJob, error)
+	FindJobs(ctx context.Context, storeID int, status string, limit int) ([]entity.PrintJob, error)
+	StartJob(ctx context.Context, job *entity.PrintJob) (bool, error)
+	FinishJob(ctx context.Context, job *entity.PrintJob) error
+	RequeueStale(ctx context.Context, before time.Time) ([]int, error)
+}
+
+// PrintQueue orders print jobs for the workers by id.
+type PrintQueue interface {
+	Push(ctx context.Context, jobID int) error
+	Pop(ctx context.Context, timeout time.Duration) (int, error)
+	Retry(ctx context.Context, jobID int, at time.Time) error
+	PromoteDue(ctx context.Context, now time.Time) error
+}
+
+type OutboxRepository interface {
+	Append(ctx context.Context, tx *sqlx.Tx, message *entity.OutboxMessage) error
+	Relay(ctx context.Context, limit int, publish func(message *entity.OutboxMessage) error) (int, error)
+	PurgeSent(ctx context.Context, before time.Time) (int64, error)
+}
+
+// EventStream carries events between processes. Readers in one group share
+// the events; each is handed to one of them and stays pending until acked.
+type EventStream interface {
+	// Publish appends a message; one not from the outbox has no id.
+	Publish(ctx context.Context, message *entity.OutboxMessage) error
+	CreateGroup(ctx context.Context, group string) error
+	Read(ctx context.Context, group string, consumer string, count int, block time.Duration) ([]StreamEvent, error)
+	// Claim takes over events another reader left pending for longer than idle.
+	Claim(ctx context.Context, group string, consumer string, idle time.Duration, count int) ([]StreamEvent, error)
+	Ack(ctx context.Context, group string, ids ...string) error
+	// DeadLetter parks an event no subscriber could handle on the dead-letter
+	// stream, for someone to look at.
+	DeadLetter(ctx context.Context, event StreamEvent, reason string) error
+}
+
+type WebhookRepository interface {
+	FindSubscriptions(ctx context.Context, storeID int) ([]entity.WebhookSubscription, error)
+	FindSubscription(ctx context.Context, id int) (*entity.WebhookSubscription, error)
+	FindSubscribers(ctx context.Context, storeID int, event string) ([]entity.WebhookSubscription, error)
+	SaveSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error
+	RemoveSubscription(ctx context.Context, id int) error
+	StoreDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error
+	FindDelivery(ctx context.Context, id int) (*entity.WebhookDelivery, error)
+	FindDeliveries(ctx context.Context, subscriptionID int, status string, limit int) ([]entity.WebhookDelivery, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]DueDelivery, error)
+	FinishDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+}
+
+type CartRepository interface {
+	Save(ctx context.Context, cart *Cart) error
+	Find(ctx context.Context, token string) (*Cart, error)
+	// Update applies change to the cart atomically; an error from change is
+	// returned as is and nothing is written.
+	Update(ctx context.Context, token string, change func(cart *Cart) error) (*Cart, error)
+	Claim(ctx context.Context, token string) (*Cart, error)
+}
+
+type CustomerRepository interface {
+	Store(ctx context.Context, customer *entity.Customer) error
+	FindById(ctx context.Context, id int) (*entity.Customer, error)
+	FindByPhone(ctx context.Context, phone string) (*entity.Customer, error)
+	FindLedger(ctx context.Context, customerID int, limit int) ([]entity.LoyaltyEntry, error)
+	Credit(ctx context.Context, entry *entity.LoyaltyEntry, spend int64, tier string) error
+	ExpirePoints(ctx context.Context, customerID int) error
+	RedeemTx(ctx context.Context, tx *sqlx.Tx, customerID int, orderID int, points int64) error
+}
+
+type PaymentRepository interface {
+	// Store returns the balance left on the order after the payment.
+	Store(ctx context.Context, payment *entity.Payment, hooks ...PaymentTxHook) (int64, error)
+	FindByOrderId(ctx context.Context, orderID int) ([]entity.Payment, error)
+}
+
+type StoreRepository interface {
+	FindById(ctx context.Context, id int) (*entity.Store, error)
+	FindBySlug(ctx context.Context, slug string) (*entity.Store, error)
+	FindTable(ctx context.Context, storeID int, tableNumber string) (*entity.StoreTable, error)
+	FindHours(ctx context.Context, storeID int) ([]entity.StoreOpeningHour, error)
+	ReplaceHours(ctx context.Context, storeID int, hours []entity.StoreOpeningHour) error
+	UpdateTimezone(ctx context.Context, storeID int, timezone string) error
+	FindClosures(ctx context.Context, storeID int, from, to string) ([]entity.StoreClosure, error)
+	FindClosure(ctx context.Context, id int) (*entity.StoreClosure, error)
+	StoreClosure(ctx context.Context, closure *entity.StoreClosure) error
+	RemoveClosure(ctx context.Context, id int) error
+}
+
+// OrderProjectionRepository is the Mongo reporting store of completed orders.
+type OrderProjectionRepository interface {
+	Upsert(ctx context.Context, projection *entity.OrderProjection) error
+	DailySales(ctx context.Context, storeID int, from, to time.Time, timezone string) ([]DailySalesRow, error)
+	TopItems(ctx context.Context, storeID int, from, to time.Time, limit int) ([]TopItemRow, error)
+	PaymentMethods(ctx context.Context, storeID int, from, to time.Time) ([]PaymentMethodRow, error)
+}
+
+// AuditRepository is the Mongo log of administrative changes.
+type AuditRepository interface {
+	Store(ctx context.Context, entry *entity.AuditEntry) error
+	Search(ctx context.Context, filter *AuditFilter) ([]entity.AuditEntry, error)
+}
+
+type ShiftRepository interface {
+	Store(ctx context.Context, shift *entity.CashShift) error
+	FindById(ctx context.Context, id int) (*entity.CashShift, error)
+	FindOpenByStore(ctx context.Context, storeID int) (*entity.CashShift, error)
+	Close(ctx context.Context, shift *entity.CashShift) error
+	StoreMovement(ctx context.Context, movement *entity.CashMovement) error
+	FindMovements(ctx context.Context, shiftID int) ([]entity.CashMovement, error)
+	PaymentTotals(ctx context.Context, shiftID int) ([]ShiftPaymentTotal, error)
+}
+
+type ScheduleRepository interface {
+	Store(ctx context.Context, schedule *entity.StaffSchedule) error
+	FindById(ctx context.Context, id int) (*entity.StaffSchedule, error)
+	FindByStore(ctx context.Context, storeID int, from, to time.Time) ([]entity.StaffSchedule, error)
+	FindCurrent(ctx context.Context, userID int, at time.Time) (*entity.StaffSchedule, error)
+	Remove(ctx context.Context, id int) error
+}
+
+type TimeEntryRepository interface {
+	ClockIn(ctx context.Context, entry *entity.TimeEntry) error
+	FindOpen(ctx context.Context, userID int) (*entity.TimeEntry, error)
+	ClockOut(ctx context.Context, entry *entity.TimeEntry) error
+	StartBreak(ctx context.Context, entryID int) (*entity.TimeEntryBreak, error)
+	EndBreak(ctx context.Context, entryID int) (*entity.TimeEntryBreak, error)
+	Timesheet(ctx context.Context, storeID int, from, to time.Time) ([]TimesheetRow, error)
+}
+
+type StampRepository interface {
+	StoreProgram(ctx context.Context, program *entity.StampProgram) error
+	UpdateProgram(ctx context.Context, program *entity.StampProgram) error
+	FindProgram(ctx context.Context, id int) (*entity.StampProgram, error)
+	FindPrograms(ctx context.Context) ([]entity.StampProgram, error)
+	FindMatches(ctx context.Context, menuItemIDs []int) ([]StampMatch, error)
+	FindCards(ctx context.Context, customerID int) ([]entity.StampCard, error)
+	Earn(ctx context.Context, customerID int, orderID int, stamps map[int]int) error
+	RedeemTx(ctx context.Context, tx *sqlx.Tx, programID int, customerID int, orderID int) error
+	Restore(ctx context.Context, orderID int) error
+}
+
+type PickupRepository interface {
+	FindSettings(ctx context.Context, storeID int) (*entity.PickupSettings, error)
+	SaveSettings(ctx context.Context, settings *entity.PickupSettings) error
+	CountBooked(ctx context.Context, storeID int, from, to time.Time) ([]PickupSlotCount, error)
+	ReserveTx(ctx context.Context, tx *sqlx.Tx, storeID int, pickupAt time.Time, capacity int) error
+}
@@ -1,7 +1,12 @@
 package model
 
 import (
+	"coffee/internal/entity"
 	"coffee/internal/model/apperrors"
+	"context"
+	"io"
+	"mime/multipart"
+	"time"
 )
 
 
@@ -11,3 +16,194 @@ type JWTServices interface{
 	ValidateAccessToken(tokenString string) (string, *apperrors.Apperrors)
 	ValidateRefreshToken(tokenString string) (string, *apperrors.Apperrors)
 }
+
+type ReportService interface {
+	SalesMix(ctx context.Context, auth *Auth, request *SalesMixRequest) (*SalesMixResponse, error)
+	PrepTimes(ctx context.Context, auth *Auth, request *PrepTimeRequest) (*PrepTimeResponse, error)
+	SalesAnalytics(ctx context.Context, auth *Auth, request *SalesAnalyticsRequest) (*SalesAnalyticsResponse, error)
+}
+
+type ProjectionService interface {
+	Project(ctx context.Context, orderID int) error
+	Rebuild(ctx context.Context, request *RebuildProjectionRequest) (*RebuildProjectionResponse, error)
+}
+
+type OrderService interface {
+	Place(ctx context.Context, auth *Auth, request *PlaceOrderRequest) (*OrderResponse, error)
+	PlaceGuest(ctx context.Context, storeID int, tableNumber string, request *PlaceOrderRequest) (*OrderResponse, error)
+	Quote(ctx context.Context, storeID int, lines []PlaceOrderItem) ([]entity.OrderItem, int64, error)
+	Queue(ctx context.Context, auth *Auth, request *OrderQueueRequest) ([]QueueOrder, error)
+	UpdateStatus(ctx context.Context, auth *Auth, id int, request *UpdateOrderStatusRequest) (*entity.Order, error)
+	BumpTicket(ctx context.Context, auth *Auth, ticketID int) (*BumpTicketResponse, error)
+}
+
+type ReceiptService interface {
+	Receipt(ctx context.Context, auth *Auth, orderID int) (*Receipt, error)
+	Ticket(ctx context.Context, auth *Auth, ticketID int) (*TicketSlip, error)
+	ForOrder(ctx context.Context, orderID int) (*Receipt, error)
+	ForTicket(ctx context.Context, ticketID int) (*TicketSlip, error)
+}
+
+type PrinterService interface {
+	Printers(ctx context.Context, auth *Auth, storeID int) ([]entity.Printer, error)
+	CreatePrinter(ctx context.Context, auth *Auth, storeID int, request *CreatePrinterRequest) (*entity.Printer, error)
+	UpdatePrinter(ctx context.Context, auth *Auth, storeID int, id int, request *UpdatePrinterRequest) (*entity.Printer, error)
+	Jobs(ctx context.Context, auth *Auth, storeID int, request *PrintJobListRequest) ([]entity.PrintJob, error)
+	Reprint(ctx context.Context, auth *Auth, jobID int) (*entity.PrintJob, error)
+	QueueReceipt(ctx context.Context, order *entity.Order) error
+	QueueTickets(ctx context.Context, orderID int) error
+	OrderPlaced(ctx context.Context, event *Event) error
+	OrderStatusChanged(ctx context.Context, event *Event) error
+}
+
+type AuditService interface {
+	Record(ctx context.Context, auth *Auth, storeID int, entityType string, entityID int, before, after any)
+	Search(ctx context.Context, auth *Auth, request *AuditSearchRequest) ([]entity.AuditEntry, error)
+}
+
+type WebhookService interface {
+	Subscriptions(ctx context.Context, auth *Auth, storeID int) ([]entity.WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, auth *Auth, storeID int, request *CreateWebhookRequest) (*entity.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, auth *Auth, storeID int, id int, request *UpdateWebhookRequest) (*entity.WebhookSubscription, error)
+	RemoveSubscription(ctx context.Context, auth *Auth, storeID int, id int) error
+	Deliveries(ctx context.Context, auth *Auth, storeID int, id int, request *WebhookDeliveryListRequest) ([]entity.WebhookDelivery, error)
+	Replay(ctx context.Context, auth *Auth, deliveryID int) (*entity.WebhookDelivery, error)
+	Publish(ctx context.Context, event *Event) error
+}
+
+type StationService interface {
+	Stations(ctx context.Context, auth *Auth, storeID int) ([]entity.Station, error)
+	CreateStation(ctx context.Context, auth *Auth, storeID int, request *CreateStationRequest) (*entity.Station, error)
+	UpdateStation(ctx context.Context, auth *Auth, storeID int, id int, request *UpdateStationRequest) (*entity.Station, error)
+	Routes(ctx context.Context, auth *Auth, storeID int) ([]entity.StationRoute, error)
+	AddRoute(ctx context.Context, auth *Auth, storeID int, request *CreateStationRouteRequest) (*entity.StationRoute, error)
+	RemoveRoute(ctx context.Context, auth *Auth, storeID int, id int) error
+	Queue(ctx context.Context, auth *Auth, stationID int) ([]StationTicket, error)
+	OrderTickets(ctx context.Context, auth *Auth, orderID int) ([]entity.OrderTicket, error)
+}
+
+type ShiftService interface {
+	Open(ctx context.Context, auth *Auth, request *OpenShiftRequest) (*entity.CashShift, error)
+	Current(ctx context.Context, auth *Auth, storeID int) (*ShiftReport, error)
+	AddMovement(ctx context.Context, auth *Auth, id int, request *CashMovementRequest) (*entity.CashMovement, error)
+	Close(ctx context.Context, auth *Auth, id int, request *CloseShiftRequest) (*ShiftReport, error)
+	Report(ctx context.Context, auth *Auth, id int) (*ShiftReport, error)
+}
+
+type PaymentService interface {
+	Capture(ctx context.Context, auth *Auth, orderID int, request *CapturePaymentRequest) (*PaymentResponse, error)
+}
+
+type ScheduleService interface {
+	Create(ctx context.Context, auth *Auth, request *CreateScheduleRequest) (*entity.StaffSchedule, error)
+	List(ctx context.Context, auth *Auth, request *ScheduleListRequest) ([]entity.StaffSchedule, error)
+	Remove(ctx context.Context, auth *Auth, id int) error
+	Timesheet(ctx context.Context, auth *Auth, request *TimesheetRequest) (*TimesheetResponse, error)
+}
+
+type TimeClockService interface {
+	ClockIn(ctx context.Context, storeSlug string, request *ClockRequest) (*ClockResponse, error)
+	ClockOut(ctx context.Context, storeSlug string, request *ClockRequest) (*ClockResponse, error)
+	StartBreak(ctx context.Context, storeSlug string, request *ClockRequest) (*ClockResponse, error)
+	EndBreak(ctx context.Context, storeSlug string, request *ClockRequest) (*ClockResponse, error)
+}
+
+type LoyaltyService interface {
+	Identify(ctx context.Context, phone string, name string) (*entity.Customer, error)
+	Enroll(ctx context.Context, customer *entity.Customer) error
+	Redemption(customer *entity.Customer, points int64, subtotal int64) (int64, TxHook, error)
+	Accrue(ctx context.Context, order *entity.Order) error
+	Refund(ctx context.Context, order *entity.Order) error
+	Describe(customer *entity.Customer) *CustomerResponse
+}
+
+type CustomerService interface {
+	Register(ctx context.Context, request *CreateCustomerRequest) (*CustomerResponse, error)
+	Lookup(ctx context.Context, request *CustomerLookupRequest) (*CustomerResponse, error)
+	Get(ctx context.Context, id int) (*CustomerResponse, error)
+	Ledger(ctx context.Context, id int) ([]entity.LoyaltyEntry, error)
+	Stamps(ctx context.Context, id int) ([]StampCardResponse, error)
+}
+
+type StampService interface {
+	CreateProgram(ctx context.Context, auth *Auth, request *CreateStampProgramRequest) (*entity.StampProgram, error)
+	UpdateProgram(ctx context.Context, auth *Auth, id int, request *UpdateStampProgramRequest) (*entity.StampProgram, error)
+	ListPrograms(ctx context.Context) ([]entity.StampProgram, error)
+	Cards(ctx context.Context, customerID int) ([]StampCardResponse, error)
+	Redemption(ctx context.Context, customerID int, reward PlaceOrderReward) (TxHook, error)
+	Earn(ctx context.Context, order *entity.Order) error
+	Restore(ctx context.Context, order *entity.Order) error
+}
+
+type MenuService interface {
+	ForStore(ctx context.Context, auth *Auth, storeID int, at time.Time, filter *MenuFilter) (*StoreMenuResponse, error)
+	BySlug(ctx context.Context, slug string, at time.Time, filter *MenuFilter) (*StoreMenuResponse, error)
+	Availability(ctx context.Context, store *entity.Store, at time.Time) (AvailabilityCheck, error)
+	Windows(ctx context.Context, auth *Auth, storeID int) ([]entity.MenuAvailabilityWindow, error)
+	AddWindow(ctx context.Context, auth *Auth, storeID int, request *CreateAvailabilityWindowRequest) (*entity.MenuAvailabilityWindow, error)
+	RemoveWindow(ctx context.Context, auth *Auth, storeID int, id int) error
+	UpdateTags(ctx context.Context, auth *Auth, menuItemID int, request *UpdateMenuItemTagsRequest) (*entity.MenuItem, error)
+}
+
+type CategoryService interface {
+	List(ctx context.Context, request *ListCategoriesRequest) ([]entity.Category, error)
+	Create(ctx context.Context, auth *Auth, request *CreateCategoryRequest) (*entity.Category, error)
+	Update(ctx context.Context, auth *Auth, id int, request *UpdateCategoryRequest) (*entity.Category, error)
+	Remove(ctx context.Context, auth *Auth, id int) error
+	StoreCategories(ctx context.Context, auth *Auth, storeID int) ([]StoreCategoryDetail, error)
+	UpdateStoreCategory(ctx context.Context, auth *Auth, storeID, categoryID int, request *UpdateStoreCategoryRequest) (*StoreCategoryDetail, error)
+	Reorder(ctx context.Context, auth *Auth, storeID int, request *ReorderCategoriesRequest) ([]StoreCategoryDetail, error)
+}
+
+type CatalogService interface {
+	Export(ctx context.Context, auth *Auth, storeID int, request *ExportCatalogRequest, w io.Writer) error
+	Import(ctx context.Context, auth *Auth, storeID int, request *ImportCatalogRequest, body io.Reader) (*ImportResult, error)
+	Clone(ctx context.Context, auth *Auth, storeID int, request *CloneStoreRequest) (*CloneStoreResult, error)
+}
+
+type ImageService interface {
+	UploadMenuItemImage(ctx context.Context, auth *Auth, menuItemID int, upload *multipart.FileHeader) (*MenuItemImage, error)
+	Open(ctx context.Context, key string) (*MediaFile, error)
+}
+
+type PriceService interface {
+	History(ctx context.Context, auth *Auth, menuItemID int, request *PriceHistoryRequest) ([]entity.MenuItemPrice, error)
+	Schedule(ctx context.Context, auth *Auth, menuItemID int, request *SchedulePriceRequest) (*entity.MenuItemPrice, error)
+	Cancel(ctx context.Context, auth *Auth, menuItemID int, id int) error
+	PriceAt(ctx context.Context, auth *Auth, menuItemID int, request *EffectivePriceRequest) (*EffectivePriceResponse, error)
+	ApplyDue(ctx context.Context) error
+}
+
+type CartService interface {
+	Open(ctx context.Context, slug string, tableNumber string) (*CartResponse, error)
+	Get(ctx context.Context, token string) (*CartResponse, error)
+	AddItem(ctx context.Context, token string, request *PlaceOrderItem) (*CartResponse, error)
+	RemoveItem(ctx context.Context, token string, index int) (*CartResponse, error)
+	Checkout(ctx context.Context, token string, request *CheckoutRequest) (*OrderResponse, error)
+}
+
+type PickupService interface {
+	Settings(ctx context.Context, auth *Auth, storeID int) (*entity.PickupSettings, error)
+	UpdateSettings(ctx context.Context, auth *Auth, storeID int, request *UpdatePickupSettingsRequest) (*entity.PickupSettings, error)
+	Slots(ctx context.Context, auth *Auth, storeID int, request *PickupSlotRequest) ([]PickupSlot, error)
+	PublicSlots(ctx context.Context, slug string, request *PickupSlotRequest) ([]PickupSlot, error)
+	// Reserve checks a pickup time and returns the status the order starts
+	// in and the hook that books its slot.
+	Reserve(ctx context.Context, storeID int, pickupAt time.Time) (string, TxHook, error)
+	Release(ctx context.Context) error
+}
+
+type StoreHoursService interface {
+	Location(store *entity.Store) *time.Location
+	DayHours(ctx context.Context, store *entity.Store, day time.Time) (*DayHours, error)
+	IsOpen(ctx context.Context, store *entity.Store, at time.Time) (bool, error)
+	Status(ctx context.Context, slug string) (*StoreStatusResponse, error)
+	Hours(ctx context.Context, auth *Auth, storeID int) ([]entity.StoreOpeningHour, error)
+	UpdateHours(ctx context.Context, auth *Auth, storeID int, request *UpdateOpeningHoursRequest) ([]entity.StoreOpeningHour, error)
+	UpdateTimezone(ctx context.Context, auth *Auth, storeID int, request *UpdateTimezoneRequest) (*entity.Store, error)
+	// Closures, AddClosure and RemoveClosure work on public holidays when
+	// an admin passes storeID 0.
+	Closures(ctx context.Context, auth *Auth, storeID int, request *ClosureListRequest) ([]entity.StoreClosure, error)
+	AddClosure(ctx context.Context, auth *Auth, storeID int, request *CreateClosureRequest) (*entity.StoreClosure, error)
+	RemoveClosure(ctx context.Context, auth *Auth, storeID int, id int) error
+}
@@ -0,0 +1,40 @@
+package model
+
+import "coffee/internal/entity"
+
+type OpenShiftRequest struct {
+	OpeningFloat int64  `json:"opening_float" validate:"min=0"`
+	Note         string `json:"note" validate:"max=500"`
+}
+
+type CashMovementRequest struct {
+	Type   string `json:"type" validate:"required,oneof=pay_in pay_out"`
+	Amount int64  `json:"amount" validate:"required,gt=0"`
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+type CloseShiftRequest struct {
+	CountedAmount *int64 `json:"counted_amount" validate:"required,min=0"`
+	Note          string `json:"note" validate:"max=500"`
+}
+
+type ShiftPaymentTotal struct {
+	Method   string `db:"method" json:"method"`
+	Payments int    `db:"payments" json:"payments"`
+	Amount   int64  `db:"amount" json:"amount"`
+}
+
+// ShiftReport is the drawer reconciliation of a shift. Expected cash is the
+// opening float plus cash sales and pay-ins minus pay-outs.
+type ShiftReport struct {
+	Shift        *entity.CashShift     `json:"shift"`
+	Payments     []ShiftPaymentTotal   `json:"payments"`
+	Movements    []entity.CashMovement `json:"movements"`
+	CashSales    int64                 `json:"cash_sales"`
+	PayIns       int64                 `json:"pay_ins"`
+	PayOuts      int64                 `json:"pay_outs"`
+	ExpectedCash int64                 `json:"expected_cash"`
+	CountedCash  *int64                `json:"counted_cash,omitempty"`
+	Variance     *int64                `json:"variance,omitempty"`
+	Result       string                `json:"result,omitempty"` // over, short or balanced once closed
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"coffee/internal/entity"
+	"time"
+)
+
+type CreateScheduleRequest struct {
+	UserID   int       `json:"user_id" validate:"required"`
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	Note     string    `json:"note" validate:"max=500"`
+}
+
+type ScheduleListRequest struct {
+	StoreID int    `query:"store_id"`
+	From    string `query:"from" validate:"required,datetime=2006-01-02"`
+	To      string `query:"to" validate:"required,datetime=2006-01-02"`
+}
+
+type TimesheetRequest struct {
+	StoreID int    `query:"store_id"`
+	From    string `query:"from" validate:"required,datetime=2006-01-02"`
+	To      string `query:"to" validate:"required,datetime=2006-01-02"`
+	Format  string `query:"format" validate:"omitempty,oneof=json csv"`
+}
+
+// ClockRequest is sent from the store tablet, the PIN stands in for a login.
+type ClockRequest struct {
+	UserID int    `json:"user_id" validate:"required"`
+	Pin    string `json:"pin" validate:"required,min=4,max=12"`
+}
+
+type ClockResponse struct {
+	Entry *entity.TimeEntry      `json:"entry"`
+	Break *entity.TimeEntryBreak `json:"break,omitempty"`
+}
+
+// TimesheetRow is a time entry with its break time already summed up.
+type TimesheetRow struct {
+	EntryID          int        `db:"entry_id" json:"entry_id"`
+	UserID           int        `db:"user_id" json:"user_id"`
+	FullName         string     `db:"full_name" json:"full_name"`
+	ClockInAt        time.Time  `db:"clock_in_at" json:"clock_in_at"`
+	ClockOutAt       *time.Time `db:"clock_out_at" json:"clock_out_at,omitempty"`
+	BreakSeconds     int64      `db:"break_seconds" json:"-"`
+	ScheduledStartAt *time.Time `db:"scheduled_start_at" json:"scheduled_start_at,omitempty"`
+	ScheduledEndAt   *time.Time `db:"scheduled_end_at" json:"scheduled_end_at,omitempty"`
+	BreakMinutes     int64      `db:"-" json:"break_minutes"`
+	WorkedMinutes    int64      `db:"-" json:"worked_minutes"`
+}
+
+type TimesheetUser struct {
+	UserID           int            `json:"user_id"`
+	FullName         string         `json:"full_name"`
+	WorkedMinutes    int64          `json:"worked_minutes"`
+	BreakMinutes     int64          `json:"break_minutes"`
+	ScheduledMinutes int64          `json:"scheduled_minutes"`
+	Entries          []TimesheetRow `json:"entries"`
+}
+
+type TimesheetResponse struct {
+	StoreID int             `json:"store_id"`
+	Period  ReportPeriod    `json:"period"`
+	Users   []TimesheetUser `json:"users"`
+}
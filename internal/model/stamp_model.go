@@ -0,0 +1,29 @@
+package model
+
+import "coffee/internal/entity"
+
+type CreateStampProgramRequest struct {
+	Name           string `json:"name" validate:"required,max=100"`
+	CategoryID     int    `json:"category_id" validate:"required_without=MenuItemID,excluded_with=MenuItemID"`
+	MenuItemID     int    `json:"menu_item_id" validate:"required_without=CategoryID,excluded_with=CategoryID"`
+	StampsRequired int    `json:"stamps_required" validate:"required,min=1,max=100"`
+}
+
+type UpdateStampProgramRequest struct {
+	Name           string `json:"name" validate:"omitempty,max=100"`
+	StampsRequired int    `json:"stamps_required" validate:"omitempty,min=1,max=100"`
+	IsActive       *bool  `json:"is_active"`
+}
+
+// StampMatch pairs a menu item with an active program it counts towards.
+type StampMatch struct {
+	ProgramID  int `db:"program_id"`
+	MenuItemID int `db:"menu_item_id"`
+}
+
+type StampCardResponse struct {
+	Program          *entity.StampProgram `json:"program"`
+	Stamps           int                  `json:"stamps"`
+	RewardsRedeemed  int                  `json:"rewards_redeemed"`
+	RewardsAvailable int                  `json:"rewards_available"`
+}
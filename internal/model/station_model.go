@@ -0,0 +1,42 @@
+package model
+
+import (
+	"coffee/internal/entity"
+	"time"
+)
+
+type CreateStationRequest struct {
+	Name      string `json:"name" validate:"required,max=50"`
+	IsDefault bool   `json:"is_default"`
+}
+
+type UpdateStationRequest struct {
+	Name      string `json:"name" validate:"omitempty,max=50"`
+	IsDefault *bool  `json:"is_default"`
+	IsActive  *bool  `json:"is_active"`
+}
+
+// CreateStationRouteRequest sends a menu item or a category to a station,
+// replacing the route it had before.
+type CreateStationRouteRequest struct {
+	StationID  int `json:"station_id" validate:"required"`
+	MenuItemID int `json:"menu_item_id" validate:"required_without=CategoryID,excluded_with=CategoryID"`
+	CategoryID int `json:"category_id" validate:"required_without=MenuItemID,excluded_with=MenuItemID"`
+}
+
+// StationTicket is an open ticket as shown on a station's screen, with the
+// items the station makes.
+type StationTicket struct {
+	entity.OrderTicket
+	OrderNumber  string            `db:"order_number" json:"order_number"`
+	OrderType    string            `db:"order_type" json:"order_type"`
+	TableNumber  string            `db:"table_number" json:"table_number,omitempty"`
+	CustomerName string            `db:"customer_name" json:"customer_name,omitempty"`
+	PickupAt     *time.Time        `db:"pickup_at" json:"pickup_at,omitempty"`
+	Items        []OrderItemDetail `db:"-" json:"items"`
+}
+
+type BumpTicketResponse struct {
+	Ticket *entity.OrderTicket `json:"ticket"`
+	Order  *entity.Order       `json:"order"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import "coffee/internal/entity"
+
+type UpdateOpeningHoursRequest struct {
+	Hours []OpeningHourRequest `json:"hours" validate:"max=7,unique=Weekday,dive"`
+}
+
+type OpeningHourRequest struct {
+	Weekday  int    `json:"weekday" validate:"min=0,max=6"` // 0 is Sunday
+	OpensAt  string `json:"opens_at" validate:"required,datetime=15:04"`
+	ClosesAt string `json:"closes_at" validate:"required,datetime=15:04"`
+}
+
+type UpdateTimezoneRequest struct {
+	Timezone string `json:"timezone" validate:"required,max=50"`
+}
+
+// CreateClosureRequest closes a day, or shortens it when both times are set.
+type CreateClosureRequest struct {
+	ClosedOn string `json:"closed_on" validate:"required,datetime=2006-01-02"`
+	OpensAt  string `json:"opens_at" validate:"required_with=ClosesAt,omitempty,datetime=15:04"`
+	ClosesAt string `json:"closes_at" validate:"required_with=OpensAt,omitempty,datetime=15:04"`
+	Reason   string `json:"reason" validate:"required,max=100"`
+}
+
+type ClosureListRequest struct {
+	From string `query:"from" validate:"required,datetime=2006-01-02"`
+	To   string `query:"to" validate:"required,datetime=2006-01-02"`
+}
+
+// DayHours are the hours that apply on one store-local day, after closures.
+type DayHours struct {
+	Date     string `json:"date"`
+	OpensAt  string `json:"opens_at,omitempty"`
+	ClosesAt string `json:"closes_at,omitempty"`
+	Closed   bool   `json:"closed"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type StoreStatusResponse struct {
+	Store     *entity.Store             `json:"store"`
+	IsOpenNow bool                      `json:"is_open_now"`
+	Today     *DayHours                 `json:"today"`
+	Week      []entity.StoreOpeningHour `json:"week"`
+	Closures  []entity.StoreClosure     `json:"upcoming_closures"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"coffee/internal/entity"
+	"time"
+)
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url,max=500"`
+	Events []string `json:"events" validate:"required,min=1,unique,dive,oneof=order.created order.status_changed payment.captured"`
+}
+
+type UpdateWebhookRequest struct {
+	URL          string   `json:"url" validate:"omitempty,url,max=500"`
+	Events       []string `json:"events" validate:"omitempty,min=1,unique,dive,oneof=order.created order.status_changed payment.captured"`
+	IsActive     *bool    `json:"is_active"`
+	RotateSecret bool     `json:"rotate_secret"`
+}
+
+type WebhookDeliveryListRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending delivered failed"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=200"`
+}
+
+// WebhookEvent is the body posted to subscribers.
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	StoreID   int       `json:"store_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// DueDelivery is a claimed delivery with where and how to send it.
+type DueDelivery struct {
+	entity.WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"coffee/internal/model"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// FileStorage keeps files on the local filesystem under one root directory.
+// It suits a single server or a shared volume.
+type FileStorage struct {
+	root string
+	log  *logrus.Logger
+}
+
+func NewFileStorage(root string, log *logrus.Logger) model.FileStorage {
+	return &FileStorage{
+		root: root,
+		log:  log,
+	}
+}
+
+// Put writes the file next to its final name first, so readers never see
+// half a file.
+func (s *FileStorage) Put(ctx context.Context, key string, body io.Reader) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		s.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		s.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := io.Copy(temp, body); err != nil {
+		temp.Close()
+		s.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	if err := temp.Close(); err != nil {
+		s.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	if err := os.Chmod(temp.Name(), 0o644); err != nil {
+		s.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	if err := os.Rename(temp.Name(), name); err != nil {
+		s.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (s *FileStorage) Open(ctx context.Context, key string) (io.ReadCloser, *model.StoredFile, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, fiber.ErrNotFound
+		}
+		s.log.Warn(err)
+		return nil, nil, fiber.ErrInternalServerError
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		s.log.Warn(err)
+		return nil, nil, fiber.ErrInternalServerError
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil, fiber.ErrNotFound
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return file, &model.StoredFile{
+		Key:         key,
+		ContentType: contentType,
+		Size:        info.Size(),
+		ModifiedAt:  info.ModTime(),
+	}, nil
+}
+
+// Delete removes the file; one that is already gone is fine.
+func (s *FileStorage) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		s.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// path maps a key to a file under the root. Keys that would leave the root,
+// or name hidden files, do not exist.
+func (s *FileStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || strings.Contains(clean, "/.") {
+		return "", fiber.ErrNotFound
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
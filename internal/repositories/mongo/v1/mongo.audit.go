@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const auditCollection = "audit_log"
+
+type AuditRepo struct {
+	collection *mongo.Collection
+	log        *logrus.Logger
+}
+
+func NewAuditRepo(db *mongo.Database, log *logrus.Logger) model.AuditRepository {
+	collection := db.Collection(auditCollection)
+
+	_, err := collection.Indexes().CreateMany(context.TODO(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "store_id", Value: 1}, {Key: "at", Value: -1}}},
+		{Keys: bson.D{{Key: "entity_type", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "at", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "at", Value: -1}}},
+		{Keys: bson.D{{Key: "request_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Warnf("failed to create %s indexes: %v", auditCollection, err)
+	}
+
+	return &AuditRepo{
+		collection: collection,
+		log:        log,
+	}
+}
+
+func (r *AuditRepo) Store(ctx context.Context, entry *entity.AuditEntry) error {
+	if _, err := r.collection.InsertOne(ctx, entry); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// Search lists matching entries, newest first.
+func (r *AuditRepo) Search(ctx context.Context, filter *model.AuditFilter) ([]entity.AuditEntry, error) {
+	match := bson.M{}
+	if filter.StoreID != nil {
+		match["store_id"] = *filter.StoreID
+	}
+	if filter.EntityType != "" {
+		match["entity_type"] = filter.EntityType
+	}
+	if filter.EntityID != 0 {
+		match["entity_id"] = filter.EntityID
+	}
+	if filter.ActorID != "" {
+		match["actor_id"] = filter.ActorID
+	}
+	if filter.RequestID != "" {
+		match["request_id"] = filter.RequestID
+	}
+	at := bson.M{}
+	if !filter.From.IsZero() {
+		at["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		at["$lt"] = filter.To
+	}
+	if len(at) > 0 {
+		match["at"] = at
+	}
+
+	cursor, err := r.collection.Find(ctx, match,
+		options.Find().SetSort(bson.D{{Key: "at", Value: -1}}).SetLimit(int64(filter.Limit)))
+	if err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	entries := []entity.AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const orderProjectionCollection = "order_projections"
+
+type OrderProjectionRepo struct {
+	collection *mongo.Collection
+	log        *logrus.Logger
+}
+
+func NewOrderProjectionRepo(db *mongo.Database, log *logrus.Logger) model.OrderProjectionRepository {
+	collection := db.Collection(orderProjectionCollection)
+
+	_, err := collection.Indexes().CreateMany(context.TODO(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "store.id", Value: 1}, {Key: "completed_at", Value: 1}}},
+		{Keys: bson.D{{Key: "items.menu_item_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Warnf("failed to create %s indexes: %v", orderProjectionCollection, err)
+	}
+
+	return &OrderProjectionRepo{
+		collection: collection,
+		log:        log,
+	}
+}
+
+// Upsert replaces the projection of an order, so projecting twice is harmless.
+func (r *OrderProjectionRepo) Upsert(ctx context.Context, projection *entity.OrderProjection) error {
+	_, err := r.collection.ReplaceOne(ctx,
+		bson.M{"_id": projection.OrderID},
+		projection,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *OrderProjectionRepo) DailySales(ctx context.Context, storeID int, from, to time.Time, timezone string) ([]model.DailySalesRow, error) {
+	pipeline := mongo.Pipeline{
+		matchCompleted(storeID, from, to),
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$dateToString": bson.M{
+				"format":   "%Y-%m-%d",
+				"date":     "$completed_at",
+				"timezone": timezone,
+			}},
+			"orders":  bson.M{"$sum": 1},
+			"revenue": bson.M{"$sum": "$total"},
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"average_ticket": bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$revenue", "$orders"}}, 0}},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	rows := []model.DailySalesRow{}
+	return rows, r.aggregate(ctx, pipeline, &rows)
+}
+
+func (r *OrderProjectionRepo) TopItems(ctx context.Context, storeID int, from, to time.Time, limit int) ([]model.TopItemRow, error) {
+	pipeline := mongo.Pipeline{
+		matchCompleted(storeID, from, to),
+		{{Key: "$unwind", Value: "$items"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$items.menu_item_id",
+			"name":     bson.M{"$last": "$items.name"},
+			"quantity": bson.M{"$sum": "$items.quantity"},
+			"revenue":  bson.M{"$sum": "$items.subtotal"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "revenue", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	rows := []model.TopItemRow{}
+	return rows, r.aggregate(ctx, pipeline, &rows)
+}
+
+func (r *OrderProjectionRepo) PaymentMethods(ctx context.Context, storeID int, from, to time.Time) ([]model.PaymentMethodRow, error) {
+	pipeline := mongo.Pipeline{
+		matchCompleted(storeID, from, to),
+		{{Key: "$unwind", Value: "$payments"}},
+		{{Key: "$match", Value: bson.M{"payments.status": entity.PaymentCaptured}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$payments.method",
+			"payments": bson.M{"$sum": 1},
+			"amount":   bson.M{"$sum": "$payments.amount"},
+		}}},
+		{{Key: "$sort", Value: bson.M{"amount": -1}}},
+	}
+
+	rows := []model.PaymentMethodRow{}
+	return rows, r.aggregate(ctx, pipeline, &rows)
+}
+
+func (r *OrderProjectionRepo) aggregate(ctx context.Context, pipeline mongo.Pipeline, out interface{}) error {
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := cursor.All(ctx, out); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func matchCompleted(storeID int, from, to time.Time) bson.D {
+	return bson.D{{Key: "$match", Value: bson.M{
+		"store.id":     storeID,
+		"completed_at": bson.M{"$gte": from, "$lt": to},
+	}}}
+}
@@ -0,0 +1,596 @@
+package v1
+
+import (
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// CatalogRepo reads and writes a store's menu as a whole, for bulk import
+// and export.
+type CatalogRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewCatalogRepo(conn *sqlx.DB, log *logrus.Logger) model.CatalogRepository {
+	return &CatalogRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+// categoryPaths names every category by its path from the top of the tree.
+const categoryPaths = `
+	WITH RECURSIVE tree AS (
+		SELECT id, name::text AS path, ''::text AS parent, 0 AS depth FROM categories WHERE parent_id IS NULL
+		UNION ALL
+		SELECT c.id, t.path || '` + model.CategoryPathSeparator + `' || c.name, t.path, t.depth + 1
+		FROM categories c JOIN tree t ON c.parent_id = t.id
+	)`
+
+// Export reads the catalog as the store sees it. Items the store does not
+// sell come out unavailable.
+func (r *CatalogRepo) Export(ctx context.Context, storeID int) (*model.Catalog, error) {
+	catalog := &model.Catalog{
+		Categories: []model.CatalogCategory{},
+		Items:      []model.CatalogItem{},
+		Groups:     []model.CatalogGroup{},
+	}
+
+	// Parents come before their subcategories, so the file imports in order.
+	categories := categoryPaths + `
+		SELECT c.name, t.parent, COALESCE(c.icon, '') AS icon, COALESCE(c.color, '') AS color,
+			COALESCE(c.is_active, true) AS is_active, COALESCE(sc.name, '') AS store_name,
+			COALESCE(sc.is_visible, true) AS is_visible, COALESCE(sc.sort_order, 0) AS sort_order
+		FROM categories c
+		JOIN tree t ON t.id = c.id
+		LEFT JOIN store_categories sc ON sc.category_id = c.id AND sc.store_id = $1
+		ORDER BY t.depth, COALESCE(sc.sort_order, 0), c.id`
+	if err := r.conn.SelectContext(ctx, &catalog.Categories, categories, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	items := categoryPaths + `
+		SELECT mi.name, t.path AS category, COALESCE(mi.description, '') AS description,
+			mi.base_price::bigint AS base_price, sm.price_override::bigint AS price,
+			COALESCE(mi.is_active, true) AS is_active, COALESCE(sm.is_available, sm.id IS NOT NULL) AS is_available,
+			COALESCE(sm.sort_order, 0) AS sort_order, COALESCE(mi.image_url, '') AS image_url, mi.tags, mi.allergens,
+			ARRAY(
+				SELECT cg.name FROM menu_item_customizations mic
+				JOIN customization_groups cg ON cg.id = mic.group_id
+				WHERE mic.menu_item_id = mi.id AND cg.store_id = $1
+				ORDER BY cg.sort_order, cg.id
+			) AS groups
+		FROM menu_items mi
+		JOIN categories c ON c.id = mi.category_id
+		JOIN tree t ON t.id = c.id
+		LEFT JOIN store_menu sm ON sm.menu_item_id = mi.id AND sm.store_id = $1
+		LEFT JOIN store_categories sc ON sc.category_id = c.id AND sc.store_id = $1
+		ORDER BY COALESCE(sc.sort_order, 0), c.id, COALESCE(sm.sort_order, 0), mi.name`
+	if err := r.conn.SelectContext(ctx, &catalog.Items, items, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	groups := `
+		SELECT id, name, COALESCE(is_required, false) AS is_required, COALESCE(sort_order, 0) AS sort_order
+		FROM customization_groups WHERE store_id = $1 ORDER BY sort_order, id`
+	if err := r.conn.SelectContext(ctx, &catalog.Groups, groups, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	options := `
+		SELECT co.group_id, co.label, COALESCE(co.additional_price, 0)::bigint AS additional_price,
+			COALESCE(co.is_available, true) AS is_available, COALESCE(co.sort_order, 0) AS sort_order
+		FROM customization_options co
+		JOIN customization_groups cg ON cg.id = co.group_id
+		WHERE cg.store_id = $1
+		ORDER BY co.sort_order, co.id`
+	all := []model.CatalogOption{}
+	if err := r.conn.SelectContext(ctx, &all, options, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	byGroup := map[int][]model.CatalogOption{}
+	for _, option := range all {
+		byGroup[option.GroupID] = append(byGroup[option.GroupID], option)
+	}
+	for i := range catalog.Groups {
+		catalog.Groups[i].Options = byGroup[catalog.Groups[i].ID]
+		if catalog.Groups[i].Options == nil {
+			catalog.Groups[i].Options = []model.CatalogOption{}
+		}
+	}
+
+	return catalog, nil
+}
+
+// Import writes the catalog in one transaction, matching rows by name, and
+// rolls it back again on a dry run. Price changes go into the price history
+// from now on.
+func (r *CatalogRepo) Import(ctx context.Context, storeID int, catalog *model.Catalog, changedBy int, dryRun bool) (*model.ImportResult, error) {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	result := &model.ImportResult{DryRun: dryRun, Errors: []model.ImportError{}, MenuItemIDs: []int{}}
+
+	// Parents first, wherever they are in the file.
+	order := make([]int, len(catalog.Categories))
+	for i := range order {
+		order[i] = i
+	}
+	depth := func(i int) int {
+		if catalog.Categories[i].Parent == "" {
+			return 0
+		}
+		return strings.Count(catalog.Categories[i].Parent, ">") + 1
+	}
+	sort.SliceStable(order, func(a, b int) bool { return depth(order[a]) < depth(order[b]) })
+
+	categories := map[string]int{}
+	for _, i := range order {
+		category := &catalog.Categories[i]
+		id, created, err := r.importCategory(ctx, tx, storeID, category, categories)
+		if err != nil {
+			return nil, err
+		}
+		countImport(&result.Categories, created)
+		categories[model.CategoryPathKey(model.CategoryPath(category.Parent, category.Name))] = id
+	}
+
+	groups := map[string]int{}
+	for i := range catalog.Groups {
+		group := &catalog.Groups[i]
+		id, created, err := r.importGroup(ctx, tx, storeID, group)
+		if err != nil {
+			return nil, err
+		}
+		countImport(&result.Groups, created)
+		groups[strings.ToLower(group.Name)] = id
+
+		for j := range group.Options {
+			created, err := r.importOption(ctx, tx, id, &group.Options[j])
+			if err != nil {
+				return nil, err
+			}
+			countImport(&result.Options, created)
+		}
+	}
+
+	for i := range catalog.Items {
+		item := &catalog.Items[i]
+		id, created, err := r.importItem(ctx, tx, storeID, item, categories, groups, changedBy)
+		if err != nil {
+			return nil, err
+		}
+		countImport(&result.Items, created)
+		result.MenuItemIDs = append(result.MenuItemIDs, id)
+	}
+
+	if !dryRun {
+		if err := tx.Commit(); err != nil {
+			r.log.Warn(err)
+			return nil, fiber.ErrInternalServerError
+		}
+		result.Applied = true
+	}
+
+	return result, nil
+}
+
+// Clone copies the source store's categories, menu, customization groups
+// with their options and the groups offered on each item into the target, in
+// one transaction. The target must not have a menu yet. Copied price
+// overrides start in the price history now.
+func (r *CatalogRepo) Clone(ctx context.Context, sourceID int, targetID int, skipPriceOverrides bool, changedBy int) (*model.CloneStoreResult, error) {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	// Two clones into the same store must not both find it empty.
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM stores WHERE id = $1 FOR UPDATE`, targetID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	var configured bool
+	existing := `
+		SELECT EXISTS (SELECT 1 FROM store_menu WHERE store_id = $1)
+			OR EXISTS (SELECT 1 FROM store_categories WHERE store_id = $1)
+			OR EXISTS (SELECT 1 FROM customization_groups WHERE store_id = $1)`
+	if err := tx.GetContext(ctx, &configured, existing, targetID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if configured {
+		return nil, fiber.NewError(fiber.StatusConflict, "the target store already has a menu")
+	}
+
+	result := &model.CloneStoreResult{SourceStoreID: sourceID, MenuItemIDs: []int{}}
+
+	categories := `
+		INSERT INTO store_categories (store_id, category_id, name, is_visible, sort_order)
+		SELECT $2, category_id, name, is_visible, sort_order FROM store_categories WHERE store_id = $1`
+	if result.Categories, err = r.execCount(ctx, tx, categories, sourceID, targetID); err != nil {
+		return nil, err
+	}
+
+	menu := `
+		INSERT INTO store_menu (store_id, menu_item_id, price_override, is_available, sort_order)
+		SELECT $2, menu_item_id, CASE WHEN $3 THEN NULL ELSE price_override END, is_available, sort_order
+		FROM store_menu WHERE store_id = $1
+		RETURNING menu_item_id`
+	if err := tx.SelectContext(ctx, &result.MenuItemIDs, menu, sourceID, targetID, skipPriceOverrides); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+	result.Items = len(result.MenuItemIDs)
+
+	prices := `
+		INSERT INTO menu_item_prices (menu_item_id, store_id, price, effective_from, created_by)
+		SELECT menu_item_id, store_id, price_override, NOW(), $2
+		FROM store_menu WHERE store_id = $1 AND price_override IS NOT NULL`
+	if _, err := tx.ExecContext(ctx, prices, targetID, nullableID(changedBy)); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	var groups []int
+	if err := tx.SelectContext(ctx, &groups, `SELECT id FROM customization_groups WHERE store_id = $1 ORDER BY id`, sourceID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	for _, sourceGroupID := range groups {
+		var groupID int
+		group := `
+			INSERT INTO customization_groups (store_id, name, is_required, sort_order)
+			SELECT $2, name, is_required, sort_order FROM customization_groups WHERE id = $1
+			RETURNING id`
+		if err := tx.GetContext(ctx, &groupID, group, sourceGroupID, targetID); err != nil {
+			r.log.Warn(err)
+			return nil, fiber.ErrInternalServerError
+		}
+		result.Groups++
+
+		options := `
+			INSERT INTO customization_options (group_id, label, additional_price, is_available, sort_order)
+			SELECT $2, label, additional_price, is_available, sort_order
+			FROM customization_options WHERE group_id = $1 ORDER BY id`
+		count, err := r.execCount(ctx, tx, options, sourceGroupID, groupID)
+		if err != nil {
+			return nil, err
+		}
+		result.Options += count
+
+		links := `
+			INSERT INTO menu_item_customizations (menu_item_id, group_id, is_default)
+			SELECT menu_item_id, $2, is_default FROM menu_item_customizations WHERE group_id = $1`
+		count, err = r.execCount(ctx, tx, links, sourceGroupID, groupID)
+		if err != nil {
+			return nil, err
+		}
+		result.Links += count
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return result, nil
+}
+
+// execCount runs a statement and tells how many rows it wrote.
+func (r *CatalogRepo) execCount(ctx context.Context, tx *sqlx.Tx, query string, args ...any) (int, error) {
+	written, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	count, err := written.RowsAffected()
+	if err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	return int(count), nil
+}
+
+// importCategory writes the category under its parent, which is in the file
+// before it or already exists. A category is matched among its siblings, so
+// it never moves to another parent.
+func (r *CatalogRepo) importCategory(ctx context.Context, tx *sqlx.Tx, storeID int, category *model.CatalogCategory, categories map[string]int) (int, bool, error) {
+	parentID := 0
+	if category.Parent != "" {
+		var err error
+		parentID, err = r.categoryID(ctx, tx, category.Parent, categories)
+		if err != nil {
+			return 0, false, err
+		}
+		if parentID == 0 {
+			return 0, false, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s: unknown parent category %q", category.Row, category.Parent))
+		}
+	}
+
+	id, err := r.findID(ctx, tx, `SELECT id FROM categories WHERE COALESCE(parent_id, 0) = $1 AND lower(name) = lower($2)`, parentID, category.Name)
+	if err != nil {
+		return 0, false, err
+	}
+
+	created := id == 0
+	if created {
+		query := `
+			INSERT INTO categories (name, parent_id, icon, color, is_active)
+			VALUES ($1, $2, NULLIF($3, ''), COALESCE(NULLIF($4, ''), '#4B3621'), $5)
+			RETURNING id`
+		err = tx.GetContext(ctx, &id, query, category.Name, nullableID(parentID), category.Icon, category.Color, flagOrTrue(category.IsActive))
+	} else {
+		query := `
+			UPDATE categories SET name = $2, icon = NULLIF($3, ''), color = COALESCE(NULLIF($4, ''), color), is_active = $5
+			WHERE id = $1`
+		_, err = tx.ExecContext(ctx, query, id, category.Name, category.Icon, category.Color, flagOrTrue(category.IsActive))
+	}
+	if err != nil {
+		r.log.Warn(err)
+		return 0, false, fiber.ErrInternalServerError
+	}
+
+	store := `
+		INSERT INTO store_categories (store_id, category_id, name, is_visible, sort_order)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		ON CONFLICT (store_id, category_id) DO UPDATE
+		SET name = EXCLUDED.name, is_visible = EXCLUDED.is_visible, sort_order = EXCLUDED.sort_order`
+	if _, err := tx.ExecContext(ctx, store, storeID, id, category.StoreName, flagOrTrue(category.IsVisible), category.SortOrder); err != nil {
+		r.log.Warn(err)
+		return 0, false, fiber.ErrInternalServerError
+	}
+
+	return id, created, nil
+}
+
+func (r *CatalogRepo) importGroup(ctx context.Context, tx *sqlx.Tx, storeID int, group *model.CatalogGroup) (int, bool, error) {
+	id, err := r.findID(ctx, tx, `SELECT id FROM customization_groups WHERE store_id = $1 AND lower(name) = lower($2) ORDER BY id LIMIT 1`, storeID, group.Name)
+	if err != nil {
+		return 0, false, err
+	}
+
+	created := id == 0
+	if created {
+		query := `INSERT INTO customization_groups (store_id, name, is_required, sort_order) VALUES ($1, $2, $3, $4) RETURNING id`
+		err = tx.GetContext(ctx, &id, query, storeID, group.Name, group.IsRequired, group.SortOrder)
+	} else {
+		query := `UPDATE customization_groups SET name = $2, is_required = $3, sort_order = $4 WHERE id = $1`
+		_, err = tx.ExecContext(ctx, query, id, group.Name, group.IsRequired, group.SortOrder)
+	}
+	if err != nil {
+		r.log.Warn(err)
+		return 0, false, fiber.ErrInternalServerError
+	}
+
+	return id, created, nil
+}
+
+func (r *CatalogRepo) importOption(ctx context.Context, tx *sqlx.Tx, groupID int, option *model.CatalogOption) (bool, error) {
+	id, err := r.findID(ctx, tx, `SELECT id FROM customization_options WHERE group_id = $1 AND lower(label) = lower($2) ORDER BY id LIMIT 1`, groupID, option.Label)
+	if err != nil {
+		return false, err
+	}
+
+	created := id == 0
+	if created {
+		query := `
+			INSERT INTO customization_options (group_id, label, additional_price, is_available, sort_order)
+			VALUES ($1, $2, $3, $4, $5)`
+		_, err = tx.ExecContext(ctx, query, groupID, option.Label, option.AdditionalPrice, flagOrTrue(option.IsAvailable), option.SortOrder)
+	} else {
+		query := `
+			UPDATE customization_options SET label = $2, additional_price = $3, is_available = $4, sort_order = $5
+			WHERE id = $1`
+		_, err = tx.ExecContext(ctx, query, id, option.Label, option.AdditionalPrice, flagOrTrue(option.IsAvailable), option.SortOrder)
+	}
+	if err != nil {
+		r.log.Warn(err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	return created, nil
+}
+
+// importItem writes the item, its place on the store's menu and the groups
+// offered on it. Groups of other stores are left alone.
+func (r *CatalogRepo) importItem(ctx context.Context, tx *sqlx.Tx, storeID int, item *model.CatalogItem, categories, groups map[string]int, changedBy int) (int, bool, error) {
+	categoryID, err := r.categoryID(ctx, tx, item.Category, categories)
+	if err != nil {
+		return 0, false, err
+	}
+	if categoryID == 0 {
+		return 0, false, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s: unknown category %q", item.Row, item.Category))
+	}
+
+	groupIDs := make([]int, 0, len(item.Groups))
+	for _, name := range item.Groups {
+		groupID, ok := groups[strings.ToLower(name)]
+		if !ok {
+			id, err := r.findID(ctx, tx, `SELECT id FROM customization_groups WHERE store_id = $1 AND lower(name) = lower($2) ORDER BY id LIMIT 1`, storeID, name)
+			if err != nil {
+				return 0, false, err
+			}
+			if id == 0 {
+				return 0, false, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s: unknown customization group %q", item.Row, name))
+			}
+			groupID = id
+		}
+		groupIDs = append(groupIDs, groupID)
+	}
+
+	var current struct {
+		ID        int   `db:"id"`
+		BasePrice int64 `db:"base_price"`
+	}
+	err = tx.GetContext(ctx, &current, `SELECT id, base_price::bigint AS base_price FROM menu_items WHERE lower(name) = lower($1) ORDER BY id LIMIT 1`, item.Name)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		r.log.Warn(err)
+		return 0, false, fiber.ErrInternalServerError
+	}
+
+	id := current.ID
+	created := id == 0
+	if created {
+		query := `
+			INSERT INTO menu_items (name, description, base_price, category_id, is_active, image_url, tags, allergens)
+			VALUES ($1, NULLIF($2, ''), $3, $4, $5, NULLIF($6, ''), COALESCE($7, '{}'), COALESCE($8, '{}'))
+			RETURNING id`
+		err = tx.GetContext(ctx, &id, query, item.Name, item.Description, item.BasePrice, categoryID, flagOrTrue(item.IsActive), item.ImageURL,
+			item.Tags, item.Allergens)
+	} else {
+		query := `
+			UPDATE menu_items SET name = $2, description = NULLIF($3, ''), base_price = $4, category_id = $5,
+				is_active = $6, image_url = NULLIF($7, ''), tags = COALESCE($8, tags), allergens = COALESCE($9, allergens),
+				updated_at = NOW()
+			WHERE id = $1`
+		_, err = tx.ExecContext(ctx, query, id, item.Name, item.Description, item.BasePrice, categoryID, flagOrTrue(item.IsActive), item.ImageURL,
+			item.Tags, item.Allergens)
+	}
+	if err != nil {
+		r.log.Warn(err)
+		return 0, false, fiber.ErrInternalServerError
+	}
+
+	if created || current.BasePrice != item.BasePrice {
+		if err := r.recordPrice(ctx, tx, id, nil, &item.BasePrice, changedBy); err != nil {
+			return 0, false, err
+		}
+	}
+
+	var override struct {
+		Price *int64 `db:"price"`
+	}
+	err = tx.GetContext(ctx, &override, `SELECT price_override::bigint AS price FROM store_menu WHERE store_id = $1 AND menu_item_id = $2`, storeID, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		r.log.Warn(err)
+		return 0, false, fiber.ErrInternalServerError
+	}
+
+	store := `
+		INSERT INTO store_menu (store_id, menu_item_id, price_override, is_available, sort_order)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (store_id, menu_item_id) DO UPDATE
+		SET price_override = EXCLUDED.price_override, is_available = EXCLUDED.is_available, sort_order = EXCLUDED.sort_order`
+	if _, err := tx.ExecContext(ctx, store, storeID, id, item.Price, flagOrTrue(item.IsAvailable), item.SortOrder); err != nil {
+		r.log.Warn(err)
+		return 0, false, fiber.ErrInternalServerError
+	}
+
+	if !samePrice(override.Price, item.Price) {
+		if err := r.recordPrice(ctx, tx, id, &storeID, item.Price, changedBy); err != nil {
+			return 0, false, err
+		}
+	}
+
+	unlink := `
+		DELETE FROM menu_item_customizations mic USING customization_groups cg
+		WHERE mic.group_id = cg.id AND cg.store_id = $1 AND mic.menu_item_id = $2 AND NOT (cg.id = ANY($3))`
+	if _, err := tx.ExecContext(ctx, unlink, storeID, id, pq.Array(groupIDs)); err != nil {
+		r.log.Warn(err)
+		return 0, false, fiber.ErrInternalServerError
+	}
+	link := `
+		INSERT INTO menu_item_customizations (menu_item_id, group_id)
+		SELECT $1, unnest($2::int[])
+		ON CONFLICT (menu_item_id, group_id) DO NOTHING`
+	if _, err := tx.ExecContext(ctx, link, id, pq.Array(groupIDs)); err != nil {
+		r.log.Warn(err)
+		return 0, false, fiber.ErrInternalServerError
+	}
+
+	return id, created, nil
+}
+
+// recordPrice adds a price that starts now to the history.
+func (r *CatalogRepo) recordPrice(ctx context.Context, tx *sqlx.Tx, menuItemID int, storeID *int, price *int64, changedBy int) error {
+	query := `
+		INSERT INTO menu_item_prices (menu_item_id, store_id, price, effective_from, created_by)
+		VALUES ($1, $2, $3, NOW(), $4)
+		ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, menuItemID, storeID, price, nullableID(changedBy)); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// categoryID finds a category by its path, among those imported so far or
+// else in the tree, 0 when there is none.
+func (r *CatalogRepo) categoryID(ctx context.Context, tx *sqlx.Tx, path string, imported map[string]int) (int, error) {
+	if id, ok := imported[model.CategoryPathKey(path)]; ok {
+		return id, nil
+	}
+
+	id := 0
+	for _, name := range strings.Split(path, ">") {
+		var err error
+		id, err = r.findID(ctx, tx, `SELECT id FROM categories WHERE COALESCE(parent_id, 0) = $1 AND lower(name) = lower($2)`, id, strings.TrimSpace(name))
+		if err != nil || id == 0 {
+			return 0, err
+		}
+	}
+
+	return id, nil
+}
+
+// findID runs a lookup by name, 0 when nothing matches.
+func (r *CatalogRepo) findID(ctx context.Context, tx *sqlx.Tx, query string, args ...any) (int, error) {
+	var id int
+	if err := tx.GetContext(ctx, &id, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	return id, nil
+}
+
+func countImport(count *model.ImportCount, created bool) {
+	if created {
+		count.Created++
+	} else {
+		count.Updated++
+	}
+}
+
+// flagOrTrue reads an optional catalog flag, which defaults to true.
+func flagOrTrue(flag *bool) bool {
+	return flag == nil || *flag
+}
+
+func samePrice(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,249 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+const categoryColumns = `c.id, c.name, COALESCE(c.icon, '') AS icon, COALESCE(c.color, '') AS color,
+	c.parent_id, COALESCE(c.is_active, true) AS is_active, c.created_at`
+
+// storeCategoryColumns are a category with a store's overrides laid over it;
+// the query needs c for the category and sc for the store's row.
+const storeCategoryColumns = categoryColumns + `, COALESCE(sc.name, '') AS store_name,
+	COALESCE(sc.is_visible, true) AS is_visible, COALESCE(sc.sort_order, 0) AS sort_order`
+
+type CategoryRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewCategoryRepo(conn *sqlx.DB, log *logrus.Logger) model.CategoryRepository {
+	return &CategoryRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *CategoryRepo) FindAll(ctx context.Context, includeInactive bool) ([]entity.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + ` FROM categories c
+		WHERE $1 OR COALESCE(c.is_active, true)
+		ORDER BY c.name`
+
+	categories := []entity.Category{}
+	if err := r.conn.SelectContext(ctx, &categories, query, includeInactive); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return categories, nil
+}
+
+func (r *CategoryRepo) FindById(ctx context.Context, id int) (*entity.Category, error) {
+	query := `SELECT ` + categoryColumns + ` FROM categories c WHERE c.id = $1`
+
+	category := new(entity.Category)
+	if err := r.conn.GetContext(ctx, category, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return category, nil
+}
+
+// categoryTreeLock serializes moves in the category tree, so two moves can
+// not each pass the cycle check and together make a cycle.
+const categoryTreeLock = 7240002
+
+// Save inserts a new category or updates an existing one. A category can not
+// move under itself or one of its subcategories.
+func (r *CategoryRepo) Save(ctx context.Context, category *entity.Category) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	if category.ParentID != nil {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLock); err != nil {
+			r.log.Warn(err)
+			return fiber.ErrInternalServerError
+		}
+
+		// UNION stops at a cycle that is already there.
+		var cycle bool
+		ancestors := `
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM categories WHERE id = $1
+				UNION
+				SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)`
+		if err := tx.GetContext(ctx, &cycle, ancestors, *category.ParentID, category.ID); err != nil {
+			r.log.Warn(err)
+			return fiber.ErrInternalServerError
+		}
+		if cycle {
+			return fiber.NewError(fiber.StatusBadRequest, "a category cannot be nested under itself or one of its subcategories")
+		}
+	}
+
+	if category.ID == 0 {
+		query := `
+			INSERT INTO categories (name, icon, color, parent_id, is_active)
+			VALUES ($1, NULLIF($2, ''), $3, $4, $5)
+			RETURNING id, created_at`
+		err = tx.QueryRowxContext(ctx, query, category.Name, category.Icon, category.Color, category.ParentID, category.IsActive).
+			Scan(&category.ID, &category.CreatedAt)
+	} else {
+		query := `
+			UPDATE categories SET name = $1, icon = NULLIF($2, ''), color = $3, parent_id = $4, is_active = $5
+			WHERE id = $6
+			RETURNING id`
+		err = tx.QueryRowxContext(ctx, query, category.Name, category.Icon, category.Color, category.ParentID, category.IsActive, category.ID).
+			Scan(&category.ID)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return fiber.ErrNotFound
+		case isUniqueViolation(err):
+			return fiber.NewError(fiber.StatusConflict, "a category with this name already exists under this parent")
+		case isForeignKeyViolation(err):
+			return fiber.NewError(fiber.StatusNotFound, "unknown parent category")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// Remove deletes a category nothing is filed under anymore, neither menu
+// items nor subcategories. Store overrides go with it.
+func (r *CategoryRepo) Remove(ctx context.Context, id int) error {
+	result, err := r.conn.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return fiber.NewError(fiber.StatusConflict, "the category still has menu items or subcategories; deactivate it instead")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fiber.ErrNotFound
+	}
+
+	return nil
+}
+
+// FindStoreCategories lists the active categories in the order the store's
+// menu shows them, hidden ones included.
+func (r *CategoryRepo) FindStoreCategories(ctx context.Context, storeID int) ([]model.StoreCategoryDetail, error) {
+	query := `
+		SELECT ` + storeCategoryColumns + `
+		FROM categories c
+		LEFT JOIN store_categories sc ON sc.category_id = c.id AND sc.store_id = $1
+		WHERE COALESCE(c.is_active, true)
+		ORDER BY COALESCE(sc.sort_order, 0), c.id`
+
+	categories := []model.StoreCategoryDetail{}
+	if err := r.conn.SelectContext(ctx, &categories, query, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return categories, nil
+}
+
+func (r *CategoryRepo) FindStoreCategory(ctx context.Context, storeID, categoryID int) (*model.StoreCategoryDetail, error) {
+	query := `
+		SELECT ` + storeCategoryColumns + `
+		FROM categories c
+		LEFT JOIN store_categories sc ON sc.category_id = c.id AND sc.store_id = $1
+		WHERE c.id = $2`
+
+	category := new(model.StoreCategoryDetail)
+	if err := r.conn.GetContext(ctx, category, query, storeID, categoryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return category, nil
+}
+
+// SaveStoreCategory writes the store's overrides of a category, creating the
+// store's row on first use.
+func (r *CategoryRepo) SaveStoreCategory(ctx context.Context, category *entity.StoreCategory) error {
+	query := `
+		INSERT INTO store_categories (store_id, category_id, name, is_visible, sort_order)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5)
+		ON CONFLICT (store_id, category_id) DO UPDATE
+			SET name = EXCLUDED.name, is_visible = EXCLUDED.is_visible, sort_order = EXCLUDED.sort_order
+		RETURNING id`
+
+	err := r.conn.QueryRowxContext(ctx, query, category.StoreID, category.CategoryID, category.Name, category.IsVisible, category.SortOrder).
+		Scan(&category.ID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// Reorder numbers the store's categories from 0: the given ones first, in
+// the given order, then every other category in the order it had.
+func (r *CategoryRepo) Reorder(ctx context.Context, storeID int, categoryIDs []int) error {
+	query := `
+		WITH listed AS (
+			SELECT id AS category_id, ord - 1 AS position
+			FROM unnest($2::int[]) WITH ORDINALITY AS t(id, ord)
+		), rest AS (
+			SELECT c.id AS category_id,
+				cardinality($2::int[]) + ROW_NUMBER() OVER (ORDER BY COALESCE(sc.sort_order, 0), c.id) - 1 AS position
+			FROM categories c
+			LEFT JOIN store_categories sc ON sc.category_id = c.id AND sc.store_id = $1
+			WHERE c.id <> ALL($2::int[])
+		)
+		INSERT INTO store_categories (store_id, category_id, sort_order)
+		SELECT $1, category_id, position FROM listed
+		UNION ALL
+		SELECT $1, category_id, position FROM rest
+		ON CONFLICT (store_id, category_id) DO UPDATE SET sort_order = EXCLUDED.sort_order`
+
+	if _, err := r.conn.ExecContext(ctx, query, storeID, pq.Array(categoryIDs)); err != nil {
+		if isForeignKeyViolation(err) {
+			return fiber.NewError(fiber.StatusNotFound, "unknown category")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
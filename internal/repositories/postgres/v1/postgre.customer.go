@@ -0,0 +1,269 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+const customerColumns = `id, phone, COALESCE(full_name, '') AS full_name, COALESCE(email, '') AS email,
+	tier, points_balance, lifetime_spend, created_at, updated_at`
+
+const ledgerColumns = `id, customer_id, order_id, type, points, remaining, expires_at, COALESCE(note, '') AS note, created_at`
+
+type CustomerRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewCustomerRepo(conn *sqlx.DB, log *logrus.Logger) model.CustomerRepository {
+	return &CustomerRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *CustomerRepo) Store(ctx context.Context, customer *entity.Customer) error {
+	query := `
+		INSERT INTO customers (phone, full_name, email, tier)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4)
+		RETURNING id, points_balance, lifetime_spend, created_at, updated_at`
+
+	err := r.conn.QueryRowxContext(ctx, query, customer.Phone, customer.FullName, customer.Email, customer.Tier).
+		Scan(&customer.ID, &customer.PointsBalance, &customer.LifetimeSpend, &customer.CreatedAt, &customer.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fiber.NewError(fiber.StatusConflict, "a customer with this phone number already exists")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *CustomerRepo) FindById(ctx context.Context, id int) (*entity.Customer, error) {
+	return r.findOne(ctx, `SELECT `+customerColumns+` FROM customers WHERE id = $1`, id)
+}
+
+func (r *CustomerRepo) FindByPhone(ctx context.Context, phone string) (*entity.Customer, error) {
+	return r.findOne(ctx, `SELECT `+customerColumns+` FROM customers WHERE phone = $1`, phone)
+}
+
+func (r *CustomerRepo) findOne(ctx context.Context, query string, arg interface{}) (*entity.Customer, error) {
+	customer := new(entity.Customer)
+	if err := r.conn.GetContext(ctx, customer, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return customer, nil
+}
+
+func (r *CustomerRepo) FindLedger(ctx context.Context, customerID int, limit int) ([]entity.LoyaltyEntry, error) {
+	query := `SELECT ` + ledgerColumns + ` FROM loyalty_ledger WHERE customer_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2`
+
+	entries := []entity.LoyaltyEntry{}
+	if err := r.conn.SelectContext(ctx, &entries, query, customerID, limit); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return entries, nil
+}
+
+// Credit adds earned or refunded points and the spend behind them. Earning
+// twice for the same order is a conflict.
+func (r *CustomerRepo) Credit(ctx context.Context, entry *entity.LoyaltyEntry, spend int64, tier string) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO loyalty_ledger (customer_id, order_id, type, points, remaining, expires_at, note)
+		VALUES ($1, $2, $3, $4, $4, $5, NULLIF($6, ''))
+		RETURNING id, remaining, created_at`
+	err = tx.QueryRowxContext(ctx, query, entry.CustomerID, entry.OrderID, entry.Type, entry.Points, entry.ExpiresAt, entry.Note).
+		Scan(&entry.ID, &entry.Remaining, &entry.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fiber.NewError(fiber.StatusConflict, "points for this order were already credited")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	update := `
+		UPDATE customers
+		SET points_balance = points_balance + $1, lifetime_spend = lifetime_spend + $2,
+			tier = COALESCE(NULLIF($3, ''), tier), updated_at = NOW()
+		WHERE id = $4`
+	if _, err := tx.ExecContext(ctx, update, entry.Points, spend, tier, entry.CustomerID); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// ExpirePoints writes off points past their expiry date.
+func (r *CustomerRepo) ExpirePoints(ctx context.Context, customerID int) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	if _, err := r.lockBalance(ctx, tx, customerID); err != nil {
+		return err
+	}
+
+	if _, err := r.expire(ctx, tx, customerID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// RedeemTx spends points inside the caller's transaction, oldest points
+// first, after writing off anything expired.
+func (r *CustomerRepo) RedeemTx(ctx context.Context, tx *sqlx.Tx, customerID int, orderID int, points int64) error {
+	balance, err := r.lockBalance(ctx, tx, customerID)
+	if err != nil {
+		return err
+	}
+
+	expired, err := r.expire(ctx, tx, customerID)
+	if err != nil {
+		return err
+	}
+
+	if balance-expired < points {
+		return fiber.NewError(fiber.StatusConflict, "not enough points")
+	}
+
+	if err := r.consume(ctx, tx, customerID, points); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO loyalty_ledger (customer_id, order_id, type, points) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.ExecContext(ctx, query, customerID, orderID, entity.LoyaltyRedeem, -points); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	update := `UPDATE customers SET points_balance = points_balance - $1, updated_at = NOW() WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, update, points, customerID); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *CustomerRepo) lockBalance(ctx context.Context, tx *sqlx.Tx, customerID int) (int64, error) {
+	var balance int64
+	query := `SELECT points_balance FROM customers WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &balance, query, customerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	return balance, nil
+}
+
+// expire zeroes expired credits and books them as one expire entry. The
+// customer row must already be locked.
+func (r *CustomerRepo) expire(ctx context.Context, tx *sqlx.Tx, customerID int) (int64, error) {
+	var expired int64
+	query := `
+		WITH old AS (
+			SELECT id, remaining FROM loyalty_ledger
+			WHERE customer_id = $1 AND remaining > 0 AND expires_at <= NOW()
+			FOR UPDATE
+		), cleared AS (
+			UPDATE loyalty_ledger l SET remaining = 0 FROM old WHERE l.id = old.id
+		)
+		SELECT COALESCE(SUM(remaining), 0)::bigint FROM old`
+	if err := tx.GetContext(ctx, &expired, query, customerID); err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	if expired == 0 {
+		return 0, nil
+	}
+
+	entry := `INSERT INTO loyalty_ledger (customer_id, type, points, note) VALUES ($1, $2, $3, 'points expired')`
+	if _, err := tx.ExecContext(ctx, entry, customerID, entity.LoyaltyExpire, -expired); err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	update := `UPDATE customers SET points_balance = GREATEST(points_balance - $1, 0), updated_at = NOW() WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, update, expired, customerID); err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	return expired, nil
+}
+
+// consume takes points off the open credits, soonest to expire first.
+func (r *CustomerRepo) consume(ctx context.Context, tx *sqlx.Tx, customerID int, points int64) error {
+	credits := []struct {
+		ID        int   `db:"id"`
+		Remaining int64 `db:"remaining"`
+	}{}
+	query := `
+		SELECT id, remaining FROM loyalty_ledger
+		WHERE customer_id = $1 AND remaining > 0
+		ORDER BY expires_at NULLS LAST, id
+		FOR UPDATE`
+	if err := tx.SelectContext(ctx, &credits, query, customerID); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	update := `UPDATE loyalty_ledger SET remaining = remaining - $1 WHERE id = $2`
+	for _, credit := range credits {
+		if points == 0 {
+			break
+		}
+
+		take := min(points, credit.Remaining)
+		if _, err := tx.ExecContext(ctx, update, take, credit.ID); err != nil {
+			r.log.Warn(err)
+			return fiber.ErrInternalServerError
+		}
+		points -= take
+	}
+
+	return nil
+}
@@ -0,0 +1,358 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+const windowColumns = `id, store_id, menu_item_id, category_id, weekday_mask,
+	COALESCE(to_char(starts_at, 'HH24:MI'), '') AS starts_at, COALESCE(to_char(ends_at, 'HH24:MI'), '') AS ends_at,
+	COALESCE(to_char(valid_from, 'YYYY-MM-DD'), '') AS valid_from, COALESCE(to_char(valid_until, 'YYYY-MM-DD'), '') AS valid_until,
+	created_at`
+
+type MenuRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewMenuRepo(conn *sqlx.DB, log *logrus.Logger) model.MenuRepository {
+	return &MenuRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+const menuItemColumns = `id, name, COALESCE(description, '') AS description, base_price::bigint AS base_price,
+	category_id, COALESCE(is_active, true) AS is_active, COALESCE(image_url, '') AS image_url, tags, allergens,
+	created_at, updated_at`
+
+const priceColumns = `id, menu_item_id, store_id, price::bigint AS price, effective_from, created_by, created_at`
+
+// effectivePrice is the price of menu item mi at store sm at the time in
+// the given parameter: the store's own price from the history, else the base
+// price from the history, else the base price on the item.
+func effectivePrice(at string) string {
+	return `COALESCE(
+		(SELECT p.price FROM menu_item_prices p
+			WHERE p.menu_item_id = mi.id AND p.store_id = sm.store_id AND p.effective_from <= ` + at + `
+			ORDER BY p.effective_from DESC, p.id DESC LIMIT 1),
+		(SELECT p.price FROM menu_item_prices p
+			WHERE p.menu_item_id = mi.id AND p.store_id IS NULL AND p.effective_from <= ` + at + `
+			ORDER BY p.effective_from DESC, p.id DESC LIMIT 1),
+		mi.base_price)::bigint`
+}
+
+// FindStoreItems returns the requested menu items the store sells, priced
+// as they were at the given time.
+func (r *MenuRepo) FindStoreItems(ctx context.Context, storeID int, menuItemIDs []int, at time.Time) ([]model.StoreMenuItem, error) {
+	query := `
+		SELECT mi.id AS menu_item_id, mi.name, mi.category_id,
+			` + effectivePrice("$3") + ` AS price,
+			(COALESCE(sm.is_available, true) AND COALESCE(mi.is_active, true)) AS is_available
+		FROM store_menu sm
+		JOIN menu_items mi ON mi.id = sm.menu_item_id
+		WHERE sm.store_id = $1 AND mi.id = ANY($2)`
+
+	items := []model.StoreMenuItem{}
+	if err := r.conn.SelectContext(ctx, &items, query, storeID, pq.Array(menuItemIDs), at); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return items, nil
+}
+
+// FindItemOptions lists the customization groups of the menu items at a store
+// with their available options. A group without available options comes back
+// once with OptionID 0 so required groups are never silently skipped.
+func (r *MenuRepo) FindItemOptions(ctx context.Context, storeID int, menuItemIDs []int) ([]model.MenuItemOption, error) {
+	query := `
+		SELECT mic.menu_item_id, cg.id AS group_id, cg.name AS group_name,
+			COALESCE(cg.is_required, false) AS is_required,
+			COALESCE(co.id, 0) AS option_id, COALESCE(co.label, '') AS label,
+			COALESCE(co.additional_price, 0)::bigint AS additional_price
+		FROM menu_item_customizations mic
+		JOIN customization_groups cg ON cg.id = mic.group_id AND cg.store_id = $1
+		LEFT JOIN customization_options co ON co.group_id = cg.id AND COALESCE(co.is_available, true)
+		WHERE mic.menu_item_id = ANY($2)
+		ORDER BY mic.menu_item_id, cg.sort_order, cg.id, co.sort_order, co.id`
+
+	options := []model.MenuItemOption{}
+	if err := r.conn.SelectContext(ctx, &options, query, storeID, pq.Array(menuItemIDs)); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return options, nil
+}
+
+// FindStoreMenu lists everything the store sells, in menu order: available
+// items of active, visible categories, priced as at the given time.
+func (r *MenuRepo) FindStoreMenu(ctx context.Context, storeID int, at time.Time) ([]model.MenuEntry, error) {
+	query := `
+		SELECT mi.id AS menu_item_id, mi.name, mi.category_id,
+			` + effectivePrice("$2") + ` AS price,
+			true AS is_available,
+			COALESCE(mi.description, '') AS description, COALESCE(mi.image_url, '') AS image_url,
+			mi.tags, mi.allergens
+		FROM store_menu sm
+		JOIN menu_items mi ON mi.id = sm.menu_item_id
+		JOIN categories c ON c.id = mi.category_id
+		LEFT JOIN store_categories sc ON sc.store_id = sm.store_id AND sc.category_id = c.id
+		WHERE sm.store_id = $1
+			AND COALESCE(sm.is_available, true) AND COALESCE(mi.is_active, true)
+			AND COALESCE(c.is_active, true) AND COALESCE(sc.is_visible, true)
+		ORDER BY COALESCE(sc.sort_order, 0), c.id, sm.sort_order, mi.name`
+
+	entries := []model.MenuEntry{}
+	if err := r.conn.SelectContext(ctx, &entries, query, storeID, at); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return entries, nil
+}
+
+// FindWindows lists the availability windows of every item and category at
+// a store.
+func (r *MenuRepo) FindWindows(ctx context.Context, storeID int) ([]entity.MenuAvailabilityWindow, error) {
+	query := `SELECT ` + windowColumns + ` FROM menu_availability_windows WHERE store_id = $1 ORDER BY id`
+
+	windows := []entity.MenuAvailabilityWindow{}
+	if err := r.conn.SelectContext(ctx, &windows, query, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return windows, nil
+}
+
+func (r *MenuRepo) FindWindow(ctx context.Context, id int) (*entity.MenuAvailabilityWindow, error) {
+	query := `SELECT ` + windowColumns + ` FROM menu_availability_windows WHERE id = $1`
+
+	window := new(entity.MenuAvailabilityWindow)
+	if err := r.conn.GetContext(ctx, window, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return window, nil
+}
+
+func (r *MenuRepo) StoreWindow(ctx context.Context, window *entity.MenuAvailabilityWindow) error {
+	query := `
+		INSERT INTO menu_availability_windows
+			(store_id, menu_item_id, category_id, weekday_mask, starts_at, ends_at, valid_from, valid_until)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::time, NULLIF($6, '')::time, NULLIF($7, '')::date, NULLIF($8, '')::date)
+		RETURNING id, created_at`
+
+	err := r.conn.QueryRowxContext(ctx, query,
+		window.StoreID, window.MenuItemID, window.CategoryID, window.WeekdayMask,
+		window.StartsAt, window.EndsAt, window.ValidFrom, window.ValidUntil).
+		Scan(&window.ID, &window.CreatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *MenuRepo) RemoveWindow(ctx context.Context, id int) error {
+	if _, err := r.conn.ExecContext(ctx, `DELETE FROM menu_availability_windows WHERE id = $1`, id); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// FindPrices lists the price history of a menu item, newest first: the base
+// prices and those of one store, or of every store when storeID is 0.
+func (r *MenuRepo) FindPrices(ctx context.Context, menuItemID int, storeID int) ([]entity.MenuItemPrice, error) {
+	query := `
+		SELECT ` + priceColumns + ` FROM menu_item_prices
+		WHERE menu_item_id = $1 AND ($2 = 0 OR store_id IS NULL OR store_id = $2)
+		ORDER BY effective_from DESC, id DESC`
+
+	prices := []entity.MenuItemPrice{}
+	if err := r.conn.SelectContext(ctx, &prices, query, menuItemID, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return prices, nil
+}
+
+func (r *MenuRepo) FindPrice(ctx context.Context, id int) (*entity.MenuItemPrice, error) {
+	query := `SELECT ` + priceColumns + ` FROM menu_item_prices WHERE id = $1`
+
+	price := new(entity.MenuItemPrice)
+	if err := r.conn.GetContext(ctx, price, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return price, nil
+}
+
+func (r *MenuRepo) StorePrice(ctx context.Context, price *entity.MenuItemPrice) error {
+	query := `
+		INSERT INTO menu_item_prices (menu_item_id, store_id, price, effective_from, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	err := r.conn.QueryRowxContext(ctx, query,
+		price.MenuItemID, price.StoreID, price.Price, price.EffectiveFrom, price.CreatedBy).
+		Scan(&price.ID, &price.CreatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return fiber.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return fiber.NewError(fiber.StatusConflict, "a price already starts at that time")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// RemovePrice cancels a scheduled price. Prices already in effect are
+// history and stay.
+func (r *MenuRepo) RemovePrice(ctx context.Context, id int) error {
+	result, err := r.conn.ExecContext(ctx, `DELETE FROM menu_item_prices WHERE id = $1 AND effective_from > NOW()`, id)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fiber.NewError(fiber.StatusConflict, "the price is already in effect")
+	}
+
+	return nil
+}
+
+// PriceAt is what a menu item cost at a store at the given time, or its base
+// price when storeID is 0.
+func (r *MenuRepo) PriceAt(ctx context.Context, menuItemID int, storeID int, at time.Time) (int64, error) {
+	query := `
+		SELECT ` + effectivePrice("$3") + `
+		FROM menu_items mi, (SELECT NULLIF($2::int, 0) AS store_id) sm
+		WHERE mi.id = $1`
+
+	var price int64
+	if err := r.conn.GetContext(ctx, &price, query, menuItemID, storeID, at); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	return price, nil
+}
+
+// ApplyDuePrices brings base_price and price_override in line with the
+// history once scheduled prices come due, and lists every store item whose
+// price changed.
+func (r *MenuRepo) ApplyDuePrices(ctx context.Context) ([]model.PriceChange, error) {
+	query := `
+		WITH base AS (
+			UPDATE menu_items mi SET base_price = p.price, updated_at = NOW()
+			FROM (
+				SELECT DISTINCT ON (menu_item_id) menu_item_id, price FROM menu_item_prices
+				WHERE store_id IS NULL AND effective_from <= NOW()
+				ORDER BY menu_item_id, effective_from DESC, id DESC
+			) p
+			WHERE mi.id = p.menu_item_id AND mi.base_price IS DISTINCT FROM p.price
+			RETURNING mi.id
+		), store AS (
+			UPDATE store_menu sm SET price_override = p.price
+			FROM (
+				SELECT DISTINCT ON (menu_item_id, store_id) menu_item_id, store_id, price FROM menu_item_prices
+				WHERE store_id IS NOT NULL AND effective_from <= NOW()
+				ORDER BY menu_item_id, store_id, effective_from DESC, id DESC
+			) p
+			WHERE sm.menu_item_id = p.menu_item_id AND sm.store_id = p.store_id
+				AND sm.price_override IS DISTINCT FROM p.price
+			RETURNING sm.store_id, sm.menu_item_id
+		)
+		SELECT sm.store_id, sm.menu_item_id FROM base JOIN store_menu sm ON sm.menu_item_id = base.id
+		UNION
+		SELECT store_id, menu_item_id FROM store
+		ORDER BY store_id, menu_item_id`
+
+	changes := []model.PriceChange{}
+	if err := r.conn.SelectContext(ctx, &changes, query); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return changes, nil
+}
+
+func (r *MenuRepo) FindItem(ctx context.Context, id int) (*entity.MenuItem, error) {
+	query := `SELECT ` + menuItemColumns + ` FROM menu_items WHERE id = $1`
+
+	item := new(entity.MenuItem)
+	if err := r.conn.GetContext(ctx, item, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return item, nil
+}
+
+func (r *MenuRepo) UpdateImage(ctx context.Context, id int, imageURL string) error {
+	query := `UPDATE menu_items SET image_url = NULLIF($2, ''), updated_at = NOW() WHERE id = $1`
+
+	result, err := r.conn.ExecContext(ctx, query, id, imageURL)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fiber.ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdateTags replaces the tags and allergens of a menu item.
+func (r *MenuRepo) UpdateTags(ctx context.Context, id int, tags, allergens []string) error {
+	query := `UPDATE menu_items SET tags = $2, allergens = $3, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.conn.ExecContext(ctx, query, id, pq.Array(tags), pq.Array(allergens))
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fiber.ErrNotFound
+	}
+
+	return nil
+}
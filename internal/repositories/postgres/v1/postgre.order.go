@@ -0,0 +1,327 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+const orderColumns = `id, store_id, order_number, status, order_type, subtotal, discount_total, fee_total, total,
+	customer_id, COALESCE(customer_name, '') AS customer_name, points_redeemed,
+	COALESCE(table_number, '') AS table_number, pickup_at, COALESCE(delivery_address, '') AS delivery_address,
+	COALESCE(customer_note, '') AS customer_note, created_at, updated_at`
+
+type OrderRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewOrderRepo(conn *sqlx.DB, log *logrus.Logger) model.OrderRepository {
+	return &OrderRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *OrderRepo) FindById(ctx context.Context, id int) (*entity.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1`
+
+	order := new(entity.Order)
+	if err := r.conn.GetContext(ctx, order, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return order, nil
+}
+
+// UpdateStatus moves the order to status and records the transition. It fails
+// with a conflict if the order changed status since it was read.
+func (r *OrderRepo) UpdateStatus(ctx context.Context, order *entity.Order, status string, changedBy int, hooks ...model.TxHook) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	previous := order.Status
+	if err := r.UpdateStatusTx(ctx, tx, order, status, changedBy, hooks...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		order.Status = previous
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// UpdateStatusTx is UpdateStatus inside a transaction the caller commits.
+func (r *OrderRepo) UpdateStatusTx(ctx context.Context, tx *sqlx.Tx, order *entity.Order, status string, changedBy int, hooks ...model.TxHook) error {
+	query := `UPDATE orders SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3 RETURNING updated_at`
+	if err := tx.GetContext(ctx, &order.UpdatedAt, query, status, order.ID, order.Status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.NewError(fiber.StatusConflict, "order status was changed by someone else")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	history := `INSERT INTO order_status_histories (order_id, from_status, to_status, changed_by, changed_at) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.ExecContext(ctx, history, order.ID, order.Status, status, nullableID(changedBy), order.UpdatedAt); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	// Hooks see the order as it will be once committed.
+	previous := order.Status
+	order.Status = status
+	for _, hook := range hooks {
+		if err := hook(ctx, tx, order); err != nil {
+			order.Status = previous
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nullableID maps the zero id to NULL for optional foreign keys.
+func nullableID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+const orderItemDetailQuery = `
+	SELECT oi.id, oi.order_id, oi.menu_item_id, oi.quantity, oi.unit_price,
+		oi.customizations, COALESCE(oi.note, '') AS note, oi.stamp_program_id, oi.station_id, oi.created_at,
+		mi.name AS menu_item_name, c.id AS category_id, c.name AS category_name
+	FROM order_items oi
+	JOIN menu_items mi ON mi.id = oi.menu_item_id
+	JOIN categories c ON c.id = mi.category_id`
+
+func (r *OrderRepo) FindItems(ctx context.Context, orderID int) ([]model.OrderItemDetail, error) {
+	query := orderItemDetailQuery + ` WHERE oi.order_id = $1 ORDER BY oi.id`
+
+	items := []model.OrderItemDetail{}
+	if err := r.conn.SelectContext(ctx, &items, query, orderID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return items, nil
+}
+
+func (r *OrderRepo) FindItemsByOrders(ctx context.Context, orderIDs []int) ([]model.OrderItemDetail, error) {
+	query := orderItemDetailQuery + ` WHERE oi.order_id = ANY($1) ORDER BY oi.order_id, oi.id`
+
+	items := []model.OrderItemDetail{}
+	if err := r.conn.SelectContext(ctx, &items, query, pq.Array(orderIDs)); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return items, nil
+}
+
+// FindQueue lists the open orders of a store, all types when orderType is
+// empty.
+func (r *OrderRepo) FindQueue(ctx context.Context, storeID int, orderType string) ([]entity.Order, error) {
+	query := `
+		SELECT ` + orderColumns + ` FROM orders
+		WHERE store_id = $1 AND status IN ('pending', 'preparing', 'ready')
+			AND ($2 = '' OR order_type = $2)
+		ORDER BY COALESCE(pickup_at, created_at), id`
+
+	orders := []entity.Order{}
+	if err := r.conn.SelectContext(ctx, &orders, query, storeID, orderType); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return orders, nil
+}
+
+// FindCompletedAt is when the order was completed, falling back to its last
+// update for orders completed before transitions were recorded.
+func (r *OrderRepo) FindCompletedAt(ctx context.Context, orderID int) (time.Time, error) {
+	query := `
+		SELECT COALESCE(
+			(SELECT MIN(changed_at) FROM order_status_histories WHERE order_id = o.id AND to_status = 'completed'),
+			o.updated_at
+		)
+		FROM orders o WHERE o.id = $1`
+
+	var completedAt time.Time
+	if err := r.conn.GetContext(ctx, &completedAt, query, orderID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return completedAt, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return completedAt, fiber.ErrInternalServerError
+	}
+
+	return completedAt, nil
+}
+
+// FindCompletedIds lists completed orders updated since the given time, oldest
+// first. A zero storeID means every store.
+func (r *OrderRepo) FindCompletedIds(ctx context.Context, storeID int, since time.Time) ([]int, error) {
+	query := `
+		SELECT id FROM orders
+		WHERE status = 'completed' AND updated_at >= $1 AND ($2 = 0 OR store_id = $2)
+		ORDER BY updated_at`
+
+	ids := []int{}
+	if err := r.conn.SelectContext(ctx, &ids, query, since, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return ids, nil
+}
+
+// Store inserts the order with its items and runs the hooks in the same
+// transaction. Order numbers count up per store per day.
+func (r *OrderRepo) Store(ctx context.Context, order *entity.Order, items []entity.OrderItem, hooks ...model.TxHook) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	// The day and the number come from the same counter row, the day in the
+	// store's timezone.
+	var counter struct {
+		Day        time.Time `db:"day"`
+		LastNumber int       `db:"last_number"`
+	}
+	next := `
+		INSERT INTO store_order_counters (store_id, day, last_number)
+		SELECT s.id, (NOW() AT TIME ZONE s.timezone)::date, 1 FROM stores s WHERE s.id = $1
+		ON CONFLICT (store_id, day) DO UPDATE SET last_number = store_order_counters.last_number + 1
+		RETURNING day, last_number`
+	if err := tx.GetContext(ctx, &counter, next, order.StoreID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	order.OrderNumber = fmt.Sprintf("S%d-%s-%04d", order.StoreID, counter.Day.Format("060102"), counter.LastNumber)
+
+	query := `
+		INSERT INTO orders (store_id, order_number, status, subtotal, discount_total, total,
+			customer_id, customer_name, points_redeemed, customer_note, table_number,
+			order_type, fee_total, pickup_at, delivery_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, NULLIF($10, ''), NULLIF($11, ''),
+			$12, $13, $14, NULLIF($15, ''))
+		RETURNING id, created_at, updated_at`
+	err = tx.QueryRowxContext(ctx, query,
+		order.StoreID, order.OrderNumber, order.Status, order.Subtotal, order.Discount, order.Total,
+		order.CustomerID, order.CustomerName, order.PointsRedeemed, order.CustomerNote, order.TableNumber,
+		order.OrderType, order.Fees, order.PickupAt, order.DeliveryAddress).
+		Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fiber.NewError(fiber.StatusConflict, "order number "+order.OrderNumber+" is already taken")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	item := `
+		INSERT INTO order_items (order_id, menu_item_id, quantity, unit_price, customizations, note, stamp_program_id)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+		RETURNING id, created_at`
+	for i := range items {
+		customizations, err := json.Marshal(items[i].CustomizationList())
+		if err != nil || string(customizations) == "null" {
+			customizations = []byte("[]")
+		}
+
+		items[i].OrderID = order.ID
+		err = tx.QueryRowxContext(ctx, item,
+			order.ID, items[i].MenuItemID, items[i].Quantity, items[i].UnitPrice, customizations, items[i].Note, items[i].StampProgramID).
+			Scan(&items[i].ID, &items[i].CreatedAt)
+		if err != nil {
+			r.log.Warn(err)
+			return fiber.ErrInternalServerError
+		}
+	}
+
+	for _, hook := range hooks {
+		if err := hook(ctx, tx, order); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// ReleaseScheduled moves pre-orders whose release time has come into the
+// queue and records the transition. Stores without pickup settings release
+// defaultMinutes before pickup. Every order is released in one transaction
+// with the hooks, so a crash cannot leave a pending order without its event.
+func (r *OrderRepo) ReleaseScheduled(ctx context.Context, defaultMinutes int, hooks ...model.TxHook) ([]int, error) {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED leaves orders a barista is touching to the next run.
+	query := `
+		SELECT ` + orderColumns + ` FROM orders o
+		WHERE o.status = 'scheduled'
+			AND o.pickup_at - make_interval(mins => COALESCE(
+				(SELECT release_minutes FROM store_pickup_settings ps WHERE ps.store_id = o.store_id), $1)) <= NOW()
+		ORDER BY o.pickup_at, o.id
+		FOR UPDATE SKIP LOCKED`
+
+	orders := []entity.Order{}
+	if err := tx.SelectContext(ctx, &orders, query, defaultMinutes); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	ids := make([]int, 0, len(orders))
+	for i := range orders {
+		if err := r.UpdateStatusTx(ctx, tx, &orders[i], entity.OrderPending, 0, hooks...); err != nil {
+			return nil, err
+		}
+		ids = append(ids, orders[i].ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// outboxRelayLock keeps a second relay, e.g. another instance of the API,
+// from publishing the same messages out of order.
+const outboxRelayLock = 7240001
+
+type OutboxRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewOutboxRepo(conn *sqlx.DB, log *logrus.Logger) model.OutboxRepository {
+	return &OutboxRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+// Append writes a message inside the caller's transaction.
+func (r *OutboxRepo) Append(ctx context.Context, tx *sqlx.Tx, message *entity.OutboxMessage) error {
+	query := `
+		INSERT INTO outbox (event, store_id, order_id, payload)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+	err := tx.QueryRowxContext(ctx, query, message.Event, message.StoreID, message.OrderID, string(message.Payload)).
+		Scan(&message.ID, &message.CreatedAt)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// Relay hands up to limit pending messages to publish, oldest first, and
+// marks the published ones sent. It stops at the first message publish
+// fails on, so nothing is published ahead of an earlier message; that one is
+// tried first next time. A crash between publishing and committing publishes
+// the messages again, consumers must expect duplicates.
+func (r *OutboxRepo) Relay(ctx context.Context, limit int, publish func(message *entity.OutboxMessage) error) (int, error) {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.GetContext(ctx, &locked, `SELECT pg_try_advisory_xact_lock($1)`, outboxRelayLock); err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	query := `
+		SELECT id, event, store_id, order_id, payload, attempts, COALESCE(last_error, '') AS last_error, created_at, sent_at
+		FROM outbox WHERE sent_at IS NULL
+		ORDER BY id
+		LIMIT $1`
+	messages := []entity.OutboxMessage{}
+	if err := tx.SelectContext(ctx, &messages, query, limit); err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	sent := []int64{}
+	var failed error
+	for i := range messages {
+		if failed = publish(&messages[i]); failed != nil {
+			update := `UPDATE outbox SET attempts = attempts + 1, last_error = $1 WHERE id = $2`
+			if _, err := tx.ExecContext(ctx, update, failed.Error(), messages[i].ID); err != nil {
+				r.log.Warn(err)
+				return 0, fiber.ErrInternalServerError
+			}
+			break
+		}
+		sent = append(sent, messages[i].ID)
+	}
+
+	if len(sent) > 0 {
+		update := `UPDATE outbox SET sent_at = NOW(), attempts = attempts + 1, last_error = NULL WHERE id = ANY($1)`
+		if _, err := tx.ExecContext(ctx, update, pq.Array(sent)); err != nil {
+			r.log.Warn(err)
+			return 0, fiber.ErrInternalServerError
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	return len(sent), failed
+}
+
+// PurgeSent deletes messages sent before the given time.
+func (r *OutboxRepo) PurgeSent(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.conn.ExecContext(ctx, `DELETE FROM outbox WHERE sent_at < $1`, before)
+	if err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+const paymentColumns = `id, order_id, method, amount, status, COALESCE(reference, '') AS reference,
+	shift_id, received_by, tendered, captured_at, created_at`
+
+type PaymentRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewPaymentRepo(conn *sqlx.DB, log *logrus.Logger) model.PaymentRepository {
+	return &PaymentRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *PaymentRepo) FindByOrderId(ctx context.Context, orderID int) ([]entity.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payments WHERE order_id = $1 ORDER BY id`
+
+	payments := []entity.Payment{}
+	if err := r.conn.SelectContext(ctx, &payments, query, orderID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return payments, nil
+}
+
+// Store captures a payment. The order row is locked while the outstanding
+// balance is checked so two tills can not overpay the same order.
+func (r *PaymentRepo) Store(ctx context.Context, payment *entity.Payment, hooks ...model.PaymentTxHook) (int64, error) {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	var total, captured int64
+	balance := `
+		SELECT o.total::bigint,
+			COALESCE((SELECT SUM(amount) FROM payments WHERE order_id = o.id AND status = 'captured'), 0)::bigint
+		FROM orders o WHERE o.id = $1 FOR UPDATE`
+	if err := tx.QueryRowxContext(ctx, balance, payment.OrderID).Scan(&total, &captured); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	outstanding := total - captured
+	if payment.Amount > outstanding {
+		return 0, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("payment exceeds the outstanding balance of %d", outstanding))
+	}
+
+	// Closing the shift waits for this payment, or this payment for the
+	// close; either way the drawer count includes it or it is refused.
+	if payment.ShiftID != nil {
+		var status string
+		if err := tx.GetContext(ctx, &status, `SELECT status FROM cash_shifts WHERE id = $1 FOR SHARE`, *payment.ShiftID); err != nil {
+			r.log.Warn(err)
+			return 0, fiber.ErrInternalServerError
+		}
+		if status != entity.ShiftOpen {
+			return 0, fiber.NewError(fiber.StatusConflict, "shift is already closed")
+		}
+	}
+
+	query := `
+		INSERT INTO payments (order_id, method, amount, status, reference, shift_id, received_by, tendered)
+		VALUES (:order_id, :method, :amount, :status, NULLIF(:reference, ''), :shift_id, :received_by, :tendered)
+		RETURNING id, captured_at, created_at`
+	rows, err := tx.NamedQuery(query, payment)
+	if err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+	if rows.Next() {
+		err = rows.Scan(&payment.ID, &payment.CapturedAt, &payment.CreatedAt)
+	}
+	rows.Close()
+	if err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	outstanding -= payment.Amount
+	for _, hook := range hooks {
+		if err := hook(ctx, tx, payment, outstanding); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	return outstanding, nil
+}
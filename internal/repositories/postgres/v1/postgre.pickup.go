@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type PickupRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewPickupRepo(conn *sqlx.DB, log *logrus.Logger) model.PickupRepository {
+	return &PickupRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *PickupRepo) FindSettings(ctx context.Context, storeID int) (*entity.PickupSettings, error) {
+	query := `
+		SELECT store_id, slot_minutes, capacity_per_slot, release_minutes, updated_at
+		FROM store_pickup_settings WHERE store_id = $1`
+
+	settings := new(entity.PickupSettings)
+	if err := r.conn.GetContext(ctx, settings, query, storeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return settings, nil
+}
+
+func (r *PickupRepo) SaveSettings(ctx context.Context, settings *entity.PickupSettings) error {
+	query := `
+		INSERT INTO store_pickup_settings (store_id, slot_minutes, capacity_per_slot, release_minutes)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (store_id) DO UPDATE SET slot_minutes = EXCLUDED.slot_minutes,
+			capacity_per_slot = EXCLUDED.capacity_per_slot, release_minutes = EXCLUDED.release_minutes,
+			updated_at = NOW()
+		RETURNING updated_at`
+
+	err := r.conn.QueryRowxContext(ctx, query, settings.StoreID, settings.SlotMinutes, settings.CapacityPerSlot, settings.ReleaseMinutes).
+		Scan(&settings.UpdatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// CountBooked counts the live pre-orders per pickup time in [from, to).
+func (r *PickupRepo) CountBooked(ctx context.Context, storeID int, from, to time.Time) ([]model.PickupSlotCount, error) {
+	query := `
+		SELECT pickup_at, COUNT(*) AS orders FROM orders
+		WHERE store_id = $1 AND pickup_at >= $2 AND pickup_at < $3 AND status <> 'cancelled'
+		GROUP BY pickup_at`
+
+	counts := []model.PickupSlotCount{}
+	if err := r.conn.SelectContext(ctx, &counts, query, storeID, from, to); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return counts, nil
+}
+
+// ReserveTx holds a slot for an order already inserted in tx. Concurrent
+// bookings of the same slot queue up on an advisory lock, so capacity is
+// never oversold.
+func (r *PickupRepo) ReserveTx(ctx context.Context, tx *sqlx.Tx, storeID int, pickupAt time.Time, capacity int) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, storeID, int32(pickupAt.Unix()/60)); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	var booked int
+	query := `SELECT COUNT(*) FROM orders WHERE store_id = $1 AND pickup_at = $2 AND status <> 'cancelled'`
+	if err := tx.GetContext(ctx, &booked, query, storeID, pickupAt); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	// booked includes the order being placed.
+	if booked > capacity {
+		return fiber.NewError(fiber.StatusConflict, "this pickup slot is full")
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+const printerColumns = `id, store_id, name, host, port, paper_width, station_id, prints_receipts, is_active, created_at, updated_at`
+
+const printJobColumns = `j.id, j.printer_id, j.order_id, j.ticket_id, j.kind, j.status, j.attempts,
+	COALESCE(j.last_error, '') AS last_error, j.created_by, j.reprint_of, j.created_at, j.updated_at, j.printed_at`
+
+type PrinterRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewPrinterRepo(conn *sqlx.DB, log *logrus.Logger) model.PrinterRepository {
+	return &PrinterRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *PrinterRepo) FindPrinters(ctx context.Context, storeID int) ([]entity.Printer, error) {
+	query := `SELECT ` + printerColumns + ` FROM printers WHERE store_id = $1 ORDER BY name`
+
+	printers := []entity.Printer{}
+	if err := r.conn.SelectContext(ctx, &printers, query, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return printers, nil
+}
+
+func (r *PrinterRepo) FindPrinter(ctx context.Context, id int) (*entity.Printer, error) {
+	query := `SELECT ` + printerColumns + ` FROM printers WHERE id = $1`
+
+	printer := new(entity.Printer)
+	if err := r.conn.GetContext(ctx, printer, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return printer, nil
+}
+
+// FindReceiptPrinters lists the active printers of a store that print
+// customer receipts.
+func (r *PrinterRepo) FindReceiptPrinters(ctx context.Context, storeID int) ([]entity.Printer, error) {
+	query := `SELECT ` + printerColumns + ` FROM printers WHERE store_id = $1 AND prints_receipts AND is_active ORDER BY id`
+
+	printers := []entity.Printer{}
+	if err := r.conn.SelectContext(ctx, &printers, query, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return printers, nil
+}
+
+// FindStationPrinters lists the active printers attached to the stations.
+func (r *PrinterRepo) FindStationPrinters(ctx context.Context, stationIDs []int) ([]entity.Printer, error) {
+	query := `SELECT ` + printerColumns + ` FROM printers WHERE station_id = ANY($1) AND is_active ORDER BY id`
+
+	printers := []entity.Printer{}
+	if err := r.conn.SelectContext(ctx, &printers, query, pq.Array(stationIDs)); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return printers, nil
+}
+
+// SavePrinter inserts a new printer or updates an existing one.
+func (r *PrinterRepo) SavePrinter(ctx context.Context, printer *entity.Printer) error {
+	var err error
+	if printer.ID == 0 {
+		query := `
+			INSERT INTO printers (store_id, name, host, port, paper_width, station_id, prints_receipts, is_active)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at, updated_at`
+		err = r.conn.QueryRowxContext(ctx, query,
+			printer.StoreID, printer.Name, printer.Host, printer.Port, printer.PaperWidth,
+			printer.StationID, printer.PrintsReceipts, printer.IsActive).
+			Scan(&printer.ID, &printer.CreatedAt, &printer.UpdatedAt)
+	} else {
+		query := `
+			UPDATE printers SET name = $1, host = $2, port = $3, paper_width = $4, station_id = $5,
+				prints_receipts = $6, is_active = $7, updated_at = NOW()
+			WHERE id = $8
+			RETURNING updated_at`
+		err = r.conn.QueryRowxContext(ctx, query,
+			printer.Name, printer.Host, printer.Port, printer.PaperWidth, printer.StationID,
+			printer.PrintsReceipts, printer.IsActive, printer.ID).
+			Scan(&printer.UpdatedAt)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return fiber.ErrNotFound
+		case isUniqueViolation(err):
+			return fiber.NewError(fiber.StatusConflict, "the store already has a printer with this name")
+		case isForeignKeyViolation(err):
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// StoreJob records a print job. When the same printer, order and ticket
+// already have a job, that job is loaded into job instead; reprints always
+// get a job of their own.
+func (r *PrinterRepo) StoreJob(ctx context.Context, job *entity.PrintJob) error {
+	query := `
+		INSERT INTO print_jobs (printer_id, order_id, ticket_id, kind, status, created_by, reprint_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (printer_id, order_id, kind, COALESCE(ticket_id, 0)) WHERE reprint_of IS NULL DO NOTHING
+		RETURNING id, created_at, updated_at`
+
+	err := r.conn.QueryRowxContext(ctx, query, job.PrinterID, job.OrderID, job.TicketID, job.Kind, job.Status, job.CreatedBy, job.ReprintOf).
+		Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		existing := `
+			SELECT ` + printJobColumns + ` FROM print_jobs j
+			WHERE j.printer_id = $1 AND j.order_id = $2 AND j.kind = $3
+				AND COALESCE(j.ticket_id, 0) = COALESCE($4, 0) AND j.reprint_of IS NULL`
+		err = r.conn.GetContext(ctx, job, existing, job.PrinterID, job.OrderID, job.Kind, job.TicketID)
+	}
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *PrinterRepo) FindJob(ctx context.Context, id int) (*entity.PrintJob, error) {
+	query := `SELECT ` + printJobColumns + ` FROM print_jobs j WHERE j.id = $1`
+
+	job := new(entity.PrintJob)
+	if err := r.conn.GetContext(ctx, job, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return job, nil
+}
+
+// FindJobs lists the latest jobs of a store's printers, newest first, all
+// statuses when status is empty.
+func (r *PrinterRepo) FindJobs(ctx context.Context, storeID int, status string, limit int) ([]entity.PrintJob, error) {
+	query := `
+		SELECT ` + printJobColumns + ` FROM print_jobs j
+		JOIN printers p ON p.id = j.printer_id
+		WHERE p.store_id = $1 AND ($2 = '' OR j.status = $2)
+		ORDER BY j.created_at DESC, j.id DESC
+		LIMIT $3`
+
+	jobs := []entity.PrintJob{}
+	if err := r.conn.SelectContext(ctx, &jobs, query, storeID, status, limit); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return jobs, nil
+}
+
+// StartJob claims a queued job for printing and counts the attempt. It
+// returns false when the job is not queued, e.g. it was picked up twice.
+func (r *PrinterRepo) StartJob(ctx context.Context, job *entity.PrintJob) (bool, error) {
+	query := `
+		UPDATE print_jobs SET status = 'printing', attempts = attempts + 1, updated_at = NOW()
+		WHERE id = $1 AND status = 'queued'
+		RETURNING status, attempts, updated_at`
+
+	err := r.conn.QueryRowxContext(ctx, query, job.ID).Scan(&job.Status, &job.Attempts, &job.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		r.log.Warn(err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+// RequeueStale puts jobs that have been queued or printing since before the
+// given time back to queued and returns their ids: their push was lost, or
+// the worker printing them died.
+func (r *PrinterRepo) RequeueStale(ctx context.Context, before time.Time) ([]int, error) {
+	query := `
+		UPDATE print_jobs SET status = 'queued', updated_at = NOW()
+		WHERE status IN ('queued', 'printing') AND updated_at < $1
+		RETURNING id`
+
+	ids := []int{}
+	if err := r.conn.SelectContext(ctx, &ids, query, before); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return ids, nil
+}
+
+// FinishJob records the outcome of an attempt: printed, failed, or queued
+// again for a retry.
+func (r *PrinterRepo) FinishJob(ctx context.Context, job *entity.PrintJob) error {
+	query := `
+		UPDATE print_jobs SET status = $1, last_error = NULLIF($2, ''), updated_at = NOW(),
+			printed_at = CASE WHEN $1 = 'printed' THEN NOW() END
+		WHERE id = $3
+		RETURNING updated_at, printed_at`
+
+	err := r.conn.QueryRowxContext(ctx, query, job.Status, job.LastError, job.ID).Scan(&job.UpdatedAt, &job.PrintedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
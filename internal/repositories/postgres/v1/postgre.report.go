@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"coffee/internal/model"
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type ReportRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewReportRepo(conn *sqlx.DB, log *logrus.Logger) model.ReportRepository {
+	return &ReportRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *ReportRepo) SalesMixByMenuItem(ctx context.Context, storeID int, from, to time.Time) ([]model.SalesMixRow, error) {
+	query := `
+		SELECT mi.id, mi.name, c.id AS parent_id, c.name AS parent_name,
+			SUM(oi.quantity)::bigint AS quantity,
+			SUM(oi.quantity * oi.unit_price)::bigint AS revenue
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN menu_items mi ON mi.id = oi.menu_item_id
+		JOIN categories c ON c.id = mi.category_id
+		WHERE o.store_id = $1 AND o.status = 'completed'
+			AND o.created_at >= $2 AND o.created_at < $3
+		GROUP BY mi.id, mi.name, c.id, c.name`
+
+	rows := []model.SalesMixRow{}
+	if err := r.conn.SelectContext(ctx, &rows, query, storeID, from, to); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return rows, nil
+}
+
+// SalesMixByCustomization counts an option once per unit of the order item it
+// was picked on. Its revenue is the option surcharge only, which is already
+// part of the menu item revenue.
+func (r *ReportRepo) SalesMixByCustomization(ctx context.Context, storeID int, from, to time.Time) ([]model.SalesMixRow, error) {
+	query := `
+		SELECT co.id, co.label AS name, cg.id AS parent_id, cg.name AS parent_name,
+			SUM(oi.quantity)::bigint AS quantity,
+			SUM(oi.quantity * (c->>'additional_price')::numeric)::bigint AS revenue
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		CROSS JOIN LATERAL jsonb_array_elements(
+			CASE WHEN jsonb_typeof(oi.customizations) = 'array' THEN oi.customizations ELSE '[]'::jsonb END
+		) AS c
+		JOIN customization_options co ON co.id = (c->>'option_id')::int
+		JOIN customization_groups cg ON cg.id = co.group_id
+		WHERE o.store_id = $1 AND o.status = 'completed'
+			AND o.created_at >= $2 AND o.created_at < $3
+		GROUP BY co.id, co.label, cg.id, cg.name`
+
+	rows := []model.SalesMixRow{}
+	if err := r.conn.SelectContext(ctx, &rows, query, storeID, from, to); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return rows, nil
+}
+
+// OrderTimelines gives when each order reached every step. A pre-order is
+// pending from its release, an order placed for now from its creation.
+func (r *ReportRepo) OrderTimelines(ctx context.Context, storeID int, from, to time.Time) ([]model.OrderTimelineRow, error) {
+	query := `
+		WITH timeline AS (
+			SELECT h.order_id,
+				MIN(h.changed_at) FILTER (WHERE h.to_status = 'pending') AS pending_at,
+				MIN(h.changed_at) FILTER (WHERE h.to_status = 'preparing') AS preparing_at,
+				MIN(h.changed_at) FILTER (WHERE h.to_status = 'ready') AS ready_at,
+				MIN(h.changed_at) FILTER (WHERE h.to_status = 'completed') AS completed_at,
+				(ARRAY_AGG(h.changed_by ORDER BY h.changed_at) FILTER (WHERE h.to_status = 'preparing'))[1] AS barista_id
+			FROM order_status_histories h
+			JOIN orders o ON o.id = h.order_id
+			WHERE o.store_id = $1 AND o.created_at >= $2 AND o.created_at < $3
+			GROUP BY h.order_id
+		)
+		SELECT o.id AS order_id, COALESCE(t.pending_at, o.created_at) AS pending_at,
+			t.preparing_at, t.ready_at, t.completed_at, t.barista_id, u.full_name AS barista_name
+		FROM orders o
+		JOIN timeline t ON t.order_id = o.id
+		LEFT JOIN users u ON u.id = t.barista_id
+		WHERE o.status <> 'cancelled'`
+
+	rows := []model.OrderTimelineRow{}
+	if err := r.conn.SelectContext(ctx, &rows, query, storeID, from, to); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return rows, nil
+}
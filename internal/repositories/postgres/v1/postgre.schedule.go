@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+const scheduleColumns = `id, store_id, user_id, starts_at, ends_at, COALESCE(note, '') AS note, created_by, created_at`
+
+type ScheduleRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewScheduleRepo(conn *sqlx.DB, log *logrus.Logger) model.ScheduleRepository {
+	return &ScheduleRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+// Store saves a schedule unless it overlaps another one of the same user.
+func (r *ScheduleRepo) Store(ctx context.Context, schedule *entity.StaffSchedule) error {
+	query := `
+		INSERT INTO staff_schedules (store_id, user_id, starts_at, ends_at, note, created_by)
+		SELECT $1, $2, $3, $4, NULLIF($5, ''), $6
+		WHERE NOT EXISTS (
+			SELECT 1 FROM staff_schedules
+			WHERE user_id = $2 AND starts_at < $4 AND ends_at > $3
+		)
+		RETURNING id, created_at`
+
+	err := r.conn.QueryRowxContext(ctx, query,
+		schedule.StoreID, schedule.UserID, schedule.StartsAt, schedule.EndsAt, schedule.Note, schedule.CreatedBy).
+		Scan(&schedule.ID, &schedule.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.NewError(fiber.StatusConflict, "schedule overlaps another shift of this user")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *ScheduleRepo) FindById(ctx context.Context, id int) (*entity.StaffSchedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM staff_schedules WHERE id = $1`
+
+	schedule := new(entity.StaffSchedule)
+	if err := r.conn.GetContext(ctx, schedule, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return schedule, nil
+}
+
+func (r *ScheduleRepo) FindByStore(ctx context.Context, storeID int, from, to time.Time) ([]entity.StaffSchedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + ` FROM staff_schedules
+		WHERE store_id = $1 AND starts_at < $3 AND ends_at > $2
+		ORDER BY starts_at, user_id`
+
+	schedules := []entity.StaffSchedule{}
+	if err := r.conn.SelectContext(ctx, &schedules, query, storeID, from, to); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return schedules, nil
+}
+
+// FindCurrent is the schedule of the user running at the given time, allowing
+// them to clock in up to an hour early. It returns nil when there is none.
+func (r *ScheduleRepo) FindCurrent(ctx context.Context, userID int, at time.Time) (*entity.StaffSchedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + ` FROM staff_schedules
+		WHERE user_id = $1 AND starts_at - INTERVAL '1 hour' <= $2 AND ends_at > $2
+		ORDER BY starts_at LIMIT 1`
+
+	schedule := new(entity.StaffSchedule)
+	if err := r.conn.GetContext(ctx, schedule, query, userID, at); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return schedule, nil
+}
+
+func (r *ScheduleRepo) Remove(ctx context.Context, id int) error {
+	query := `DELETE FROM staff_schedules WHERE id = $1`
+
+	if _, err := r.conn.ExecContext(ctx, query, id); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+const shiftColumns = `id, store_id, opened_by, closed_by, status, opening_float, expected_amount,
+	counted_amount, variance, COALESCE(note, '') AS note, opened_at, closed_at`
+
+type ShiftRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewShiftRepo(conn *sqlx.DB, log *logrus.Logger) model.ShiftRepository {
+	return &ShiftRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *ShiftRepo) Store(ctx context.Context, shift *entity.CashShift) error {
+	query := `
+		INSERT INTO cash_shifts (store_id, opened_by, status, opening_float, note)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING id, opened_at`
+
+	err := r.conn.QueryRowxContext(ctx, query, shift.StoreID, shift.OpenedBy, shift.Status, shift.OpeningFloat, shift.Note).
+		Scan(&shift.ID, &shift.OpenedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fiber.NewError(fiber.StatusConflict, "this store already has an open shift")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *ShiftRepo) FindById(ctx context.Context, id int) (*entity.CashShift, error) {
+	query := `SELECT ` + shiftColumns + ` FROM cash_shifts WHERE id = $1`
+
+	shift := new(entity.CashShift)
+	if err := r.conn.GetContext(ctx, shift, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return shift, nil
+}
+
+func (r *ShiftRepo) FindOpenByStore(ctx context.Context, storeID int) (*entity.CashShift, error) {
+	query := `SELECT ` + shiftColumns + ` FROM cash_shifts WHERE store_id = $1 AND status = 'open'`
+
+	shift := new(entity.CashShift)
+	if err := r.conn.GetContext(ctx, shift, query, storeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "no open shift for this store")
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return shift, nil
+}
+
+// Close stores the count of an open shift. It fails with a conflict if the
+// shift was closed in the meantime.
+// Close closes the shift with the counted cash and stores what the drawer
+// should hold. The shift row is locked first, so a cash payment is either in
+// the expected amount or refused because the shift is closed.
+func (r *ShiftRepo) Close(ctx context.Context, shift *entity.CashShift) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.GetContext(ctx, &status, `SELECT status FROM cash_shifts WHERE id = $1 FOR UPDATE`, shift.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	if status != entity.ShiftOpen {
+		return fiber.NewError(fiber.StatusConflict, "shift is already closed")
+	}
+
+	query := `
+		WITH expected AS (
+			SELECT s.opening_float
+				+ COALESCE((SELECT SUM(amount) FROM payments WHERE shift_id = s.id AND status = 'captured' AND method = 'cash'), 0)
+				+ COALESCE((SELECT SUM(CASE type WHEN 'pay_in' THEN amount ELSE -amount END) FROM cash_movements WHERE shift_id = s.id), 0)
+				AS amount
+			FROM cash_shifts s WHERE s.id = $4
+		)
+		UPDATE cash_shifts
+		SET status = 'closed', closed_by = $1, expected_amount = e.amount, counted_amount = $2,
+			variance = $2 - e.amount, note = COALESCE(NULLIF($3, ''), note), closed_at = NOW()
+		FROM expected e
+		WHERE id = $4
+		RETURNING expected_amount, variance, closed_at`
+
+	err = tx.QueryRowxContext(ctx, query, shift.ClosedBy, shift.CountedAmount, shift.Note, shift.ID).
+		Scan(&shift.ExpectedAmount, &shift.Variance, &shift.ClosedAt)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	shift.Status = entity.ShiftClosed
+	return nil
+}
+
+func (r *ShiftRepo) StoreMovement(ctx context.Context, movement *entity.CashMovement) error {
+	query := `
+		INSERT INTO cash_movements (shift_id, type, amount, reason, created_by)
+		SELECT $1, $2, $3, $4, $5 FROM cash_shifts WHERE id = $1 AND status = 'open'
+		RETURNING id, created_at`
+
+	err := r.conn.QueryRowxContext(ctx, query, movement.ShiftID, movement.Type, movement.Amount, movement.Reason, movement.CreatedBy).
+		Scan(&movement.ID, &movement.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.NewError(fiber.StatusConflict, "shift is already closed")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *ShiftRepo) FindMovements(ctx context.Context, shiftID int) ([]entity.CashMovement, error) {
+	query := `SELECT id, shift_id, type, amount, reason, created_by, created_at FROM cash_movements WHERE shift_id = $1 ORDER BY id`
+
+	movements := []entity.CashMovement{}
+	if err := r.conn.SelectContext(ctx, &movements, query, shiftID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return movements, nil
+}
+
+// PaymentTotals sums captured payments posted to the shift by method.
+func (r *ShiftRepo) PaymentTotals(ctx context.Context, shiftID int) ([]model.ShiftPaymentTotal, error) {
+	query := `
+		SELECT method, COUNT(*) AS payments, SUM(amount)::bigint AS amount
+		FROM payments
+		WHERE shift_id = $1 AND status = 'captured'
+		GROUP BY method
+		ORDER BY method`
+
+	totals := []model.ShiftPaymentTotal{}
+	if err := r.conn.SelectContext(ctx, &totals, query, shiftID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return totals, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
@@ -0,0 +1,221 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+const stampProgramColumns = `id, name, category_id, menu_item_id, stamps_required, is_active, created_at, updated_at`
+
+type StampRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewStampRepo(conn *sqlx.DB, log *logrus.Logger) model.StampRepository {
+	return &StampRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *StampRepo) StoreProgram(ctx context.Context, program *entity.StampProgram) error {
+	query := `
+		INSERT INTO stamp_programs (name, category_id, menu_item_id, stamps_required)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, is_active, created_at, updated_at`
+
+	err := r.conn.QueryRowxContext(ctx, query, program.Name, program.CategoryID, program.MenuItemID, program.StampsRequired).
+		Scan(&program.ID, &program.IsActive, &program.CreatedAt, &program.UpdatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return fiber.NewError(fiber.StatusBadRequest, "category or menu item does not exist")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *StampRepo) UpdateProgram(ctx context.Context, program *entity.StampProgram) error {
+	query := `
+		UPDATE stamp_programs SET name = $1, stamps_required = $2, is_active = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING updated_at`
+
+	err := r.conn.QueryRowxContext(ctx, query, program.Name, program.StampsRequired, program.IsActive, program.ID).
+		Scan(&program.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *StampRepo) FindProgram(ctx context.Context, id int) (*entity.StampProgram, error) {
+	query := `SELECT ` + stampProgramColumns + ` FROM stamp_programs WHERE id = $1`
+
+	program := new(entity.StampProgram)
+	if err := r.conn.GetContext(ctx, program, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return program, nil
+}
+
+func (r *StampRepo) FindPrograms(ctx context.Context) ([]entity.StampProgram, error) {
+	query := `SELECT ` + stampProgramColumns + ` FROM stamp_programs ORDER BY is_active DESC, name, id`
+
+	programs := []entity.StampProgram{}
+	if err := r.conn.SelectContext(ctx, &programs, query); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return programs, nil
+}
+
+// FindMatches lists the active programs each of the menu items counts
+// towards, directly or through its category.
+func (r *StampRepo) FindMatches(ctx context.Context, menuItemIDs []int) ([]model.StampMatch, error) {
+	query := `
+		SELECT sp.id AS program_id, mi.id AS menu_item_id
+		FROM menu_items mi
+		JOIN stamp_programs sp ON sp.is_active
+			AND (sp.menu_item_id = mi.id OR sp.category_id = mi.category_id)
+		WHERE mi.id = ANY($1)`
+
+	matches := []model.StampMatch{}
+	if err := r.conn.SelectContext(ctx, &matches, query, pq.Array(menuItemIDs)); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return matches, nil
+}
+
+func (r *StampRepo) FindCards(ctx context.Context, customerID int) ([]entity.StampCard, error) {
+	query := `
+		SELECT program_id, customer_id, stamps, rewards_redeemed, updated_at
+		FROM stamp_cards WHERE customer_id = $1 ORDER BY program_id`
+
+	cards := []entity.StampCard{}
+	if err := r.conn.SelectContext(ctx, &cards, query, customerID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return cards, nil
+}
+
+// Earn adds the stamps of one order to the customer's cards, keyed by
+// program. Stamps already credited for the order are skipped.
+func (r *StampRepo) Earn(ctx context.Context, customerID int, orderID int, stamps map[int]int) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH earned AS (
+			INSERT INTO stamp_events (program_id, customer_id, order_id, type, stamps)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (program_id, order_id, type) DO NOTHING
+			RETURNING program_id, customer_id, stamps
+		)
+		INSERT INTO stamp_cards (program_id, customer_id, stamps)
+		SELECT program_id, customer_id, stamps FROM earned
+		ON CONFLICT (program_id, customer_id)
+		DO UPDATE SET stamps = stamp_cards.stamps + EXCLUDED.stamps, updated_at = NOW()`
+	for programID, count := range stamps {
+		if _, err := tx.ExecContext(ctx, query, programID, customerID, orderID, entity.StampEarn, count); err != nil {
+			r.log.Warn(err)
+			return fiber.ErrInternalServerError
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// RedeemTx spends one full card inside the caller's transaction.
+func (r *StampRepo) RedeemTx(ctx context.Context, tx *sqlx.Tx, programID int, customerID int, orderID int) error {
+	var stamps, required int
+	query := `
+		SELECT c.stamps, p.stamps_required
+		FROM stamp_cards c
+		JOIN stamp_programs p ON p.id = c.program_id
+		WHERE c.program_id = $1 AND c.customer_id = $2
+		FOR UPDATE OF c`
+	err := tx.QueryRowxContext(ctx, query, programID, customerID).Scan(&stamps, &required)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	if errors.Is(err, sql.ErrNoRows) || stamps < required {
+		return fiber.NewError(fiber.StatusConflict, "not enough stamps for this reward")
+	}
+
+	update := `
+		UPDATE stamp_cards SET stamps = stamps - $1, rewards_redeemed = rewards_redeemed + 1, updated_at = NOW()
+		WHERE program_id = $2 AND customer_id = $3`
+	if _, err := tx.ExecContext(ctx, update, required, programID, customerID); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	event := `INSERT INTO stamp_events (program_id, customer_id, order_id, type, stamps) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.ExecContext(ctx, event, programID, customerID, orderID, entity.StampRedeem, -required); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// Restore gives back the stamps spent on an order. Running it twice for the
+// same order changes nothing.
+func (r *StampRepo) Restore(ctx context.Context, orderID int) error {
+	query := `
+		WITH refunded AS (
+			INSERT INTO stamp_events (program_id, customer_id, order_id, type, stamps)
+			SELECT program_id, customer_id, order_id, $2, -stamps
+			FROM stamp_events WHERE order_id = $1 AND type = $3
+			ON CONFLICT (program_id, order_id, type) DO NOTHING
+			RETURNING program_id, customer_id, stamps
+		)
+		UPDATE stamp_cards c
+		SET stamps = c.stamps + refunded.stamps, rewards_redeemed = c.rewards_redeemed - 1, updated_at = NOW()
+		FROM refunded
+		WHERE c.program_id = refunded.program_id AND c.customer_id = refunded.customer_id`
+
+	if _, err := r.conn.ExecContext(ctx, query, orderID, entity.StampRefund, entity.StampRedeem); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
@@ -0,0 +1,332 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+const stationColumns = `id, store_id, name, is_default, is_active, created_at, updated_at`
+
+const ticketColumns = `t.id, t.order_id, t.station_id, t.status, t.bumped_by, t.bumped_at, t.created_at`
+
+type StationRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewStationRepo(conn *sqlx.DB, log *logrus.Logger) model.StationRepository {
+	return &StationRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *StationRepo) FindStations(ctx context.Context, storeID int) ([]entity.Station, error) {
+	query := `SELECT ` + stationColumns + ` FROM stations WHERE store_id = $1 ORDER BY name`
+
+	stations := []entity.Station{}
+	if err := r.conn.SelectContext(ctx, &stations, query, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return stations, nil
+}
+
+func (r *StationRepo) FindStation(ctx context.Context, id int) (*entity.Station, error) {
+	query := `SELECT ` + stationColumns + ` FROM stations WHERE id = $1`
+
+	station := new(entity.Station)
+	if err := r.conn.GetContext(ctx, station, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return station, nil
+}
+
+// SaveStation inserts a new station or updates an existing one. A default
+// station takes the default over from the store's other stations.
+func (r *StationRepo) SaveStation(ctx context.Context, station *entity.Station) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	if station.IsDefault {
+		clear := `UPDATE stations SET is_default = false, updated_at = NOW() WHERE store_id = $1 AND is_default AND id <> $2`
+		if _, err := tx.ExecContext(ctx, clear, station.StoreID, station.ID); err != nil {
+			r.log.Warn(err)
+			return fiber.ErrInternalServerError
+		}
+	}
+
+	if station.ID == 0 {
+		query := `
+			INSERT INTO stations (store_id, name, is_default, is_active)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at, updated_at`
+		err = tx.QueryRowxContext(ctx, query, station.StoreID, station.Name, station.IsDefault, station.IsActive).
+			Scan(&station.ID, &station.CreatedAt, &station.UpdatedAt)
+	} else {
+		query := `
+			UPDATE stations SET name = $1, is_default = $2, is_active = $3, updated_at = NOW()
+			WHERE id = $4
+			RETURNING updated_at`
+		err = tx.QueryRowxContext(ctx, query, station.Name, station.IsDefault, station.IsActive, station.ID).
+			Scan(&station.UpdatedAt)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return fiber.ErrNotFound
+		case isUniqueViolation(err):
+			return fiber.NewError(fiber.StatusConflict, "the store already has a station with this name")
+		case isForeignKeyViolation(err):
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *StationRepo) FindRoutes(ctx context.Context, storeID int) ([]entity.StationRoute, error) {
+	query := `
+		SELECT id, store_id, station_id, menu_item_id, category_id, created_at
+		FROM station_routes WHERE store_id = $1
+		ORDER BY category_id NULLS LAST, menu_item_id`
+
+	routes := []entity.StationRoute{}
+	if err := r.conn.SelectContext(ctx, &routes, query, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return routes, nil
+}
+
+func (r *StationRepo) FindRoute(ctx context.Context, id int) (*entity.StationRoute, error) {
+	query := `SELECT id, store_id, station_id, menu_item_id, category_id, created_at FROM station_routes WHERE id = $1`
+
+	route := new(entity.StationRoute)
+	if err := r.conn.GetContext(ctx, route, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return route, nil
+}
+
+// StoreRoute replaces the route of the menu item or category at the store.
+func (r *StationRepo) StoreRoute(ctx context.Context, route *entity.StationRoute) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	remove := `
+		DELETE FROM station_routes
+		WHERE store_id = $1 AND (menu_item_id = $2 OR category_id = $3)`
+	if _, err := tx.ExecContext(ctx, remove, route.StoreID, route.MenuItemID, route.CategoryID); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	query := `
+		INSERT INTO station_routes (store_id, station_id, menu_item_id, category_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+	err = tx.QueryRowxContext(ctx, query, route.StoreID, route.StationID, route.MenuItemID, route.CategoryID).
+		Scan(&route.ID, &route.CreatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *StationRepo) RemoveRoute(ctx context.Context, id int) error {
+	if _, err := r.conn.ExecContext(ctx, `DELETE FROM station_routes WHERE id = $1`, id); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// RouteTx sends every item of a new order to its station and opens one
+// ticket per station, inside the caller's transaction. Items of stores
+// without stations get no station and the order no tickets.
+func (r *StationRepo) RouteTx(ctx context.Context, tx *sqlx.Tx, order *entity.Order) error {
+	route := `
+		UPDATE order_items oi SET station_id = COALESCE(
+			(SELECT sr.station_id FROM station_routes sr JOIN stations s ON s.id = sr.station_id AND s.is_active
+				WHERE sr.store_id = $2 AND sr.menu_item_id = oi.menu_item_id),
+			(SELECT sr.station_id FROM station_routes sr JOIN stations s ON s.id = sr.station_id AND s.is_active
+				WHERE sr.store_id = $2 AND sr.category_id = mi.category_id),
+			(SELECT s.id FROM stations s WHERE s.store_id = $2 AND s.is_default AND s.is_active))
+		FROM menu_items mi
+		WHERE mi.id = oi.menu_item_id AND oi.order_id = $1`
+	if _, err := tx.ExecContext(ctx, route, order.ID, order.StoreID); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	tickets := `
+		INSERT INTO order_tickets (order_id, station_id)
+		SELECT DISTINCT order_id, station_id FROM order_items
+		WHERE order_id = $1 AND station_id IS NOT NULL`
+	if _, err := tx.ExecContext(ctx, tickets, order.ID); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *StationRepo) FindTicket(ctx context.Context, id int) (*entity.OrderTicket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM order_tickets t WHERE t.id = $1`
+
+	ticket := new(entity.OrderTicket)
+	if err := r.conn.GetContext(ctx, ticket, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return ticket, nil
+}
+
+func (r *StationRepo) FindTickets(ctx context.Context, orderID int) ([]entity.OrderTicket, error) {
+	query := `SELECT ` + ticketColumns + ` FROM order_tickets t WHERE t.order_id = $1 ORDER BY t.id`
+
+	tickets := []entity.OrderTicket{}
+	if err := r.conn.SelectContext(ctx, &tickets, query, orderID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return tickets, nil
+}
+
+// FindOpenTickets lists the open tickets of a station whose orders are being
+// worked on, in the order the barista queue shows them.
+func (r *StationRepo) FindOpenTickets(ctx context.Context, stationID int) ([]model.StationTicket, error) {
+	query := `
+		SELECT ` + ticketColumns + `, o.order_number, o.order_type,
+			COALESCE(o.table_number, '') AS table_number, COALESCE(o.customer_name, '') AS customer_name, o.pickup_at
+		FROM order_tickets t
+		JOIN orders o ON o.id = t.order_id
+		WHERE t.station_id = $1 AND t.status = 'open' AND o.status IN ('pending', 'preparing')
+		ORDER BY COALESCE(o.pickup_at, o.created_at), o.id`
+
+	tickets := []model.StationTicket{}
+	if err := r.conn.SelectContext(ctx, &tickets, query, stationID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return tickets, nil
+}
+
+func (r *StationRepo) CountOpenTickets(ctx context.Context, orderID int) (int, error) {
+	var open int
+	query := `SELECT COUNT(*) FROM order_tickets WHERE order_id = $1 AND status = 'open'`
+	if err := r.conn.GetContext(ctx, &open, query, orderID); err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	return open, nil
+}
+
+// Bump marks a ticket done and returns how many tickets of its order are
+// still open. The order row is locked and read into order, so that of two
+// stations finishing at once exactly one sees zero, and hook moves the order
+// on before the lock is let go.
+func (r *StationRepo) Bump(ctx context.Context, ticket *entity.OrderTicket, order *entity.Order, bumpedBy int, hook model.BumpTxHook) (int, error) {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	// Concurrent bumps of one order line up here, each seeing the status the
+	// one before it left.
+	lock := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, order, lock, ticket.OrderID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	query := `
+		UPDATE order_tickets SET status = 'done', bumped_by = $1, bumped_at = NOW()
+		WHERE id = $2 AND status = 'open'
+		RETURNING status, bumped_by, bumped_at`
+	err = tx.QueryRowxContext(ctx, query, nullableID(bumpedBy), ticket.ID).
+		Scan(&ticket.Status, &ticket.BumpedBy, &ticket.BumpedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fiber.NewError(fiber.StatusConflict, "ticket is already done")
+		}
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	var open int
+	count := `SELECT COUNT(*) FROM order_tickets WHERE order_id = $1 AND status = 'open'`
+	if err := tx.GetContext(ctx, &open, count, ticket.OrderID); err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	if err := hook(ctx, tx, order, open); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	return open, nil
+}
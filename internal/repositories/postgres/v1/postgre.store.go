@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+const storeColumns = `id, name, COALESCE(location, '') AS location, COALESCE(address, '') AS address,
+	COALESCE(phone, '') AS phone, COALESCE(email, '') AS email, store_slug, timezone, is_active, created_at, updated_at`
+
+const closureColumns = `id, store_id, to_char(closed_on, 'YYYY-MM-DD') AS closed_on,
+	COALESCE(to_char(opens_at, 'HH24:MI'), '') AS opens_at, COALESCE(to_char(closes_at, 'HH24:MI'), '') AS closes_at,
+	reason, created_by, created_at`
+
+type StoreRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewStoreRepo(conn *sqlx.DB, log *logrus.Logger) model.StoreRepository {
+	return &StoreRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *StoreRepo) FindById(ctx context.Context, id int) (*entity.Store, error) {
+	query := `SELECT ` + storeColumns + ` FROM stores WHERE id = $1`
+
+	store := new(entity.Store)
+	if err := r.conn.GetContext(ctx, store, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return store, nil
+}
+
+func (r *StoreRepo) FindBySlug(ctx context.Context, slug string) (*entity.Store, error) {
+	query := `SELECT ` + storeColumns + ` FROM stores WHERE store_slug = $1`
+
+	store := new(entity.Store)
+	if err := r.conn.GetContext(ctx, store, query, slug); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return store, nil
+}
+
+func (r *StoreRepo) FindTable(ctx context.Context, storeID int, tableNumber string) (*entity.StoreTable, error) {
+	query := `
+		SELECT id, store_id, table_number, COALESCE(label, '') AS label, is_active, created_at
+		FROM store_tables WHERE store_id = $1 AND table_number = $2`
+
+	table := new(entity.StoreTable)
+	if err := r.conn.GetContext(ctx, table, query, storeID, tableNumber); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return table, nil
+}
+
+func (r *StoreRepo) FindHours(ctx context.Context, storeID int) ([]entity.StoreOpeningHour, error) {
+	query := `
+		SELECT store_id, weekday, to_char(opens_at, 'HH24:MI') AS opens_at, to_char(closes_at, 'HH24:MI') AS closes_at
+		FROM store_opening_hours WHERE store_id = $1 ORDER BY weekday`
+
+	hours := []entity.StoreOpeningHour{}
+	if err := r.conn.SelectContext(ctx, &hours, query, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return hours, nil
+}
+
+// ReplaceHours swaps the whole week at once; weekdays left out are closed.
+func (r *StoreRepo) ReplaceHours(ctx context.Context, storeID int, hours []entity.StoreOpeningHour) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM store_opening_hours WHERE store_id = $1`, storeID); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	query := `INSERT INTO store_opening_hours (store_id, weekday, opens_at, closes_at) VALUES ($1, $2, $3, $4)`
+	for _, hour := range hours {
+		if _, err := tx.ExecContext(ctx, query, storeID, hour.Weekday, hour.OpensAt, hour.ClosesAt); err != nil {
+			if isForeignKeyViolation(err) {
+				return fiber.ErrNotFound
+			}
+			r.log.Warn(err)
+			return fiber.ErrInternalServerError
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *StoreRepo) UpdateTimezone(ctx context.Context, storeID int, timezone string) error {
+	result, err := r.conn.ExecContext(ctx, `UPDATE stores SET timezone = $1, updated_at = NOW() WHERE id = $2`, timezone, storeID)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fiber.ErrNotFound
+	}
+
+	return nil
+}
+
+// FindClosures lists the closures of a store, public holidays included, in
+// [from, to]. storeID 0 lists public holidays only.
+func (r *StoreRepo) FindClosures(ctx context.Context, storeID int, from, to string) ([]entity.StoreClosure, error) {
+	query := `
+		SELECT ` + closureColumns + ` FROM store_closures
+		WHERE (store_id IS NULL OR store_id = $1) AND closed_on BETWEEN $2 AND $3
+		ORDER BY closed_on, store_id NULLS LAST`
+
+	closures := []entity.StoreClosure{}
+	if err := r.conn.SelectContext(ctx, &closures, query, storeID, from, to); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return closures, nil
+}
+
+func (r *StoreRepo) FindClosure(ctx context.Context, id int) (*entity.StoreClosure, error) {
+	query := `SELECT ` + closureColumns + ` FROM store_closures WHERE id = $1`
+
+	closure := new(entity.StoreClosure)
+	if err := r.conn.GetContext(ctx, closure, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return closure, nil
+}
+
+func (r *StoreRepo) StoreClosure(ctx context.Context, closure *entity.StoreClosure) error {
+	query := `
+		INSERT INTO store_closures (store_id, closed_on, opens_at, closes_at, reason, created_by)
+		VALUES ($1, $2, NULLIF($3, '')::time, NULLIF($4, '')::time, $5, $6)
+		RETURNING id, created_at`
+
+	err := r.conn.QueryRowxContext(ctx, query,
+		closure.StoreID, closure.ClosedOn, closure.OpensAt, closure.ClosesAt, closure.Reason, closure.CreatedBy).
+		Scan(&closure.ID, &closure.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fiber.NewError(fiber.StatusConflict, "this day already has a closure")
+		}
+		if isForeignKeyViolation(err) {
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *StoreRepo) RemoveClosure(ctx context.Context, id int) error {
+	if _, err := r.conn.ExecContext(ctx, `DELETE FROM store_closures WHERE id = $1`, id); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
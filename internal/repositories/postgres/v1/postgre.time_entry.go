@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+const timeEntryColumns = `id, store_id, user_id, schedule_id, clock_in_at, clock_out_at`
+
+type TimeEntryRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewTimeEntryRepo(conn *sqlx.DB, log *logrus.Logger) model.TimeEntryRepository {
+	return &TimeEntryRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *TimeEntryRepo) ClockIn(ctx context.Context, entry *entity.TimeEntry) error {
+	query := `
+		INSERT INTO time_entries (store_id, user_id, schedule_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, clock_in_at`
+
+	err := r.conn.QueryRowxContext(ctx, query, entry.StoreID, entry.UserID, entry.ScheduleID).
+		Scan(&entry.ID, &entry.ClockInAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fiber.NewError(fiber.StatusConflict, "already clocked in")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *TimeEntryRepo) FindOpen(ctx context.Context, userID int) (*entity.TimeEntry, error) {
+	query := `SELECT ` + timeEntryColumns + ` FROM time_entries WHERE user_id = $1 AND clock_out_at IS NULL`
+
+	entry := new(entity.TimeEntry)
+	if err := r.conn.GetContext(ctx, entry, query, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.NewError(fiber.StatusConflict, "not clocked in")
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return entry, nil
+}
+
+// ClockOut closes the entry and any break still running on it.
+func (r *TimeEntryRepo) ClockOut(ctx context.Context, entry *entity.TimeEntry) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE time_entries SET clock_out_at = NOW() WHERE id = $1 AND clock_out_at IS NULL RETURNING clock_out_at`
+	if err := tx.GetContext(ctx, &entry.ClockOutAt, query, entry.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.NewError(fiber.StatusConflict, "not clocked in")
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	breaks := `UPDATE time_entry_breaks SET ended_at = $1 WHERE time_entry_id = $2 AND ended_at IS NULL`
+	if _, err := tx.ExecContext(ctx, breaks, entry.ClockOutAt, entry.ID); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *TimeEntryRepo) StartBreak(ctx context.Context, entryID int) (*entity.TimeEntryBreak, error) {
+	query := `INSERT INTO time_entry_breaks (time_entry_id) VALUES ($1) RETURNING id, time_entry_id, started_at, ended_at`
+
+	brk := new(entity.TimeEntryBreak)
+	if err := r.conn.GetContext(ctx, brk, query, entryID); err != nil {
+		if isUniqueViolation(err) {
+			return nil, fiber.NewError(fiber.StatusConflict, "already on a break")
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return brk, nil
+}
+
+func (r *TimeEntryRepo) EndBreak(ctx context.Context, entryID int) (*entity.TimeEntryBreak, error) {
+	query := `
+		UPDATE time_entry_breaks SET ended_at = NOW()
+		WHERE time_entry_id = $1 AND ended_at IS NULL
+		RETURNING id, time_entry_id, started_at, ended_at`
+
+	brk := new(entity.TimeEntryBreak)
+	if err := r.conn.GetContext(ctx, brk, query, entryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.NewError(fiber.StatusConflict, "not on a break")
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return brk, nil
+}
+
+// Timesheet lists entries clocked in during the period. Breaks still running
+// count up to now.
+func (r *TimeEntryRepo) Timesheet(ctx context.Context, storeID int, from, to time.Time) ([]model.TimesheetRow, error) {
+	query := `
+		SELECT te.id AS entry_id, te.user_id, u.full_name, te.clock_in_at, te.clock_out_at,
+			COALESCE((
+				SELECT SUM(EXTRACT(EPOCH FROM COALESCE(b.ended_at, NOW()) - b.started_at))
+				FROM time_entry_breaks b WHERE b.time_entry_id = te.id
+			), 0)::bigint AS break_seconds,
+			s.starts_at AS scheduled_start_at, s.ends_at AS scheduled_end_at
+		FROM time_entries te
+		JOIN users u ON u.id = te.user_id
+		LEFT JOIN staff_schedules s ON s.id = te.schedule_id
+		WHERE te.store_id = $1 AND te.clock_in_at >= $2 AND te.clock_in_at < $3
+		ORDER BY u.full_name, te.clock_in_at`
+
+	rows := []model.TimesheetRow{}
+	if err := r.conn.SelectContext(ctx, &rows, query, storeID, from, to); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return rows, nil
+}
@@ -1,12 +1,20 @@
 package v1
 
 import (
+	"coffee/internal/entity"
 	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/jmoiron/sqlx"
 	"github.com/sirupsen/logrus"
 )
 
+const userColumns = `id, full_name, COALESCE(email, '') AS email, role, COALESCE(store_id, 0) AS store_id,
+	COALESCE(pin_hash, ''::bytea) AS pin_hash, is_active, must_reset_pin, created_at, updated_at`
+
 type UserRepo struct {
 	conn *sqlx.DB
 	log 	*logrus.Logger
@@ -18,3 +26,18 @@ func NewUserRepo(conn *sqlx.DB, log *logrus.Logger) model.UserRepository {
 		log: log,
 	}
 }
+
+func (r *UserRepo) FindById(ctx context.Context, id int) (*entity.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user := new(entity.User)
+	if err := r.conn.GetContext(ctx, user, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return user, nil
+}
@@ -0,0 +1,245 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+const webhookColumns = `id, store_id, url, secret, events, is_active, created_at, updated_at`
+
+const deliveryColumns = `d.id, d.subscription_id, d.event_id, d.event, d.payload, d.status, d.attempts,
+	d.response_status, COALESCE(d.response_body, '') AS response_body, COALESCE(d.last_error, '') AS last_error,
+	d.next_attempt_at, d.replay_of, d.created_at, d.updated_at, d.delivered_at`
+
+type WebhookRepo struct {
+	conn *sqlx.DB
+	log  *logrus.Logger
+}
+
+func NewWebhookRepo(conn *sqlx.DB, log *logrus.Logger) model.WebhookRepository {
+	return &WebhookRepo{
+		conn: conn,
+		log:  log,
+	}
+}
+
+func (r *WebhookRepo) FindSubscriptions(ctx context.Context, storeID int) ([]entity.WebhookSubscription, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhook_subscriptions WHERE store_id = $1 ORDER BY id`
+
+	subscriptions := []entity.WebhookSubscription{}
+	if err := r.conn.SelectContext(ctx, &subscriptions, query, storeID); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return subscriptions, nil
+}
+
+func (r *WebhookRepo) FindSubscription(ctx context.Context, id int) (*entity.WebhookSubscription, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	subscription := new(entity.WebhookSubscription)
+	if err := r.conn.GetContext(ctx, subscription, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return subscription, nil
+}
+
+// FindSubscribers lists the active subscriptions of a store to an event.
+func (r *WebhookRepo) FindSubscribers(ctx context.Context, storeID int, event string) ([]entity.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookColumns + ` FROM webhook_subscriptions
+		WHERE store_id = $1 AND is_active AND $2 = ANY(events)
+		ORDER BY id`
+
+	subscriptions := []entity.WebhookSubscription{}
+	if err := r.conn.SelectContext(ctx, &subscriptions, query, storeID, event); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return subscriptions, nil
+}
+
+// SaveSubscription inserts a new subscription or updates an existing one.
+func (r *WebhookRepo) SaveSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	var err error
+	if subscription.ID == 0 {
+		query := `
+			INSERT INTO webhook_subscriptions (store_id, url, secret, events, is_active)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at, updated_at`
+		err = r.conn.QueryRowxContext(ctx, query,
+			subscription.StoreID, subscription.URL, subscription.Secret, subscription.Events, subscription.IsActive).
+			Scan(&subscription.ID, &subscription.CreatedAt, &subscription.UpdatedAt)
+	} else {
+		query := `
+			UPDATE webhook_subscriptions SET url = $1, secret = $2, events = $3, is_active = $4, updated_at = NOW()
+			WHERE id = $5
+			RETURNING updated_at`
+		err = r.conn.QueryRowxContext(ctx, query,
+			subscription.URL, subscription.Secret, subscription.Events, subscription.IsActive, subscription.ID).
+			Scan(&subscription.UpdatedAt)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return fiber.ErrNotFound
+		case isForeignKeyViolation(err):
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) RemoveSubscription(ctx context.Context, id int) error {
+	if _, err := r.conn.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// StoreDeliveries records new deliveries. A delivery of an event the
+// subscription already has, other than a replay, is skipped and keeps a zero
+// id.
+func (r *WebhookRepo) StoreDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	tx, err := r.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event, payload, replay_of)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (subscription_id, event_id) WHERE replay_of IS NULL DO NOTHING
+		RETURNING id, status, next_attempt_at, created_at, updated_at`
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		err := tx.QueryRowxContext(ctx, query,
+			delivery.SubscriptionID, delivery.EventID, delivery.Event, string(delivery.Payload), delivery.ReplayOf).
+			Scan(&delivery.ID, &delivery.Status, &delivery.NextAttemptAt, &delivery.CreatedAt, &delivery.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			if isForeignKeyViolation(err) {
+				return fiber.ErrNotFound
+			}
+			r.log.Warn(err)
+			return fiber.ErrInternalServerError
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) FindDelivery(ctx context.Context, id int) (*entity.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d WHERE d.id = $1`
+
+	delivery := new(entity.WebhookDelivery)
+	if err := r.conn.GetContext(ctx, delivery, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return delivery, nil
+}
+
+// FindDeliveries lists the latest deliveries of a subscription, newest first,
+// all statuses when status is empty.
+func (r *WebhookRepo) FindDeliveries(ctx context.Context, subscriptionID int, status string, limit int) ([]entity.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + ` FROM webhook_deliveries d
+		WHERE d.subscription_id = $1 AND ($2 = '' OR d.status = $2)
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $3`
+
+	deliveries := []entity.WebhookDelivery{}
+	if err := r.conn.SelectContext(ctx, &deliveries, query, subscriptionID, status, limit); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDue takes up to limit pending deliveries that are due, counts the
+// attempt and pushes next_attempt_at out by lease, so a dispatcher that dies
+// mid-send leaves the delivery to be picked up again later. Deliveries of
+// paused subscriptions wait.
+func (r *WebhookRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.DueDelivery, error) {
+	query := `
+		WITH due AS (
+			SELECT d.id FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id AND s.is_active
+			WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2), updated_at = NOW()
+		FROM due, webhook_subscriptions s
+		WHERE d.id = due.id AND s.id = d.subscription_id
+		RETURNING ` + deliveryColumns + `, s.url, s.secret`
+
+	deliveries := []model.DueDelivery{}
+	if err := r.conn.SelectContext(ctx, &deliveries, query, limit, lease.Seconds()); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return deliveries, nil
+}
+
+// FinishDelivery records the outcome of an attempt.
+func (r *WebhookRepo) FinishDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, response_status = $2, response_body = NULLIF($3, ''), last_error = NULLIF($4, ''),
+			next_attempt_at = $5, updated_at = NOW(),
+			delivered_at = CASE WHEN $1 = 'delivered' THEN NOW() END
+		WHERE id = $6
+		RETURNING updated_at, delivered_at`
+
+	err := r.conn.QueryRowxContext(ctx, query,
+		delivery.Status, delivery.ResponseStatus, delivery.ResponseBody, delivery.LastError, delivery.NextAttemptAt, delivery.ID).
+		Scan(&delivery.UpdatedAt, &delivery.DeliveredAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.ErrNotFound
+		}
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package v1
+
+import (
+	"coffee/internal/model"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+type CartRepo struct {
+	client *redis.Client
+	log    *logrus.Logger
+	ttl    time.Duration
+}
+
+func NewCartRepo(client *redis.Client, ttl time.Duration, log *logrus.Logger) model.CartRepository {
+	if ttl <= 0 {
+		ttl = 2 * time.Hour
+	}
+
+	return &CartRepo{
+		client: client,
+		log:    log,
+		ttl:    ttl,
+	}
+}
+
+// maxCartUpdateAttempts bounds how often an edit is reapplied when other
+// edits of the same cart keep getting in first.
+const maxCartUpdateAttempts = 5
+
+func cartKey(token string) string {
+	return "cart:" + token
+}
+
+// Save writes the cart and restarts its expiry, so a cart only expires after
+// the guest stops touching it.
+func (r *CartRepo) Save(ctx context.Context, cart *model.Cart) error {
+	cart.ExpiresAt = time.Now().Add(r.ttl)
+
+	raw, err := json.Marshal(cart)
+	if err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	if err := r.client.SetEx(ctx, cartKey(cart.Token), raw, r.ttl).Err(); err != nil {
+		r.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// Update reads the cart, applies change and writes it back, all under WATCH,
+// so of two edits made at the same time neither is lost: the one that loses
+// the race reads the cart again and reapplies its change.
+func (r *CartRepo) Update(ctx context.Context, token string, change func(cart *model.Cart) error) (*model.Cart, error) {
+	key := cartKey(token)
+	for attempt := 0; attempt < maxCartUpdateAttempts; attempt++ {
+		var cart *model.Cart
+		var changeErr error
+		err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+			raw, err := tx.Get(ctx, key).Bytes()
+			if err != nil {
+				return err
+			}
+			cart = new(model.Cart)
+			if err := json.Unmarshal(raw, cart); err != nil {
+				return err
+			}
+			if changeErr = change(cart); changeErr != nil {
+				return changeErr
+			}
+
+			cart.ExpiresAt = time.Now().Add(r.ttl)
+			if raw, err = json.Marshal(cart); err != nil {
+				return err
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.SetEx(ctx, key, raw, r.ttl)
+				return nil
+			})
+			return err
+		}, key)
+
+		switch {
+		case err == nil:
+			return cart, nil
+		case errors.Is(err, redis.TxFailedErr):
+			continue
+		case changeErr != nil:
+			return nil, changeErr
+		case errors.Is(err, redis.Nil):
+			return nil, fiber.NewError(fiber.StatusNotFound, "cart not found or expired")
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return nil, fiber.NewError(fiber.StatusConflict, "the cart is being changed elsewhere, try again")
+}
+
+func (r *CartRepo) Find(ctx context.Context, token string) (*model.Cart, error) {
+	raw, err := r.client.Get(ctx, cartKey(token)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "cart not found or expired")
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	cart := new(model.Cart)
+	if err := json.Unmarshal(raw, cart); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return cart, nil
+}
+
+// Claim takes the cart out of Redis in one step, so of two checkouts of the
+// same cart only one gets it.
+func (r *CartRepo) Claim(ctx context.Context, token string) (*model.Cart, error) {
+	raw, err := r.client.GetDel(ctx, cartKey(token)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, fiber.NewError(fiber.StatusConflict, "cart already checked out")
+		}
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	cart := new(model.Cart)
+	if err := json.Unmarshal(raw, cart); err != nil {
+		r.log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return cart, nil
+}
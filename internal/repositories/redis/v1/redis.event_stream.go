@@ -0,0 +1,182 @@
+package v1
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// EventStream publishes events to a Redis stream and reads them back through
+// consumer groups. The stream is trimmed to about maxLen entries; a consumer
+// that falls further behind than that loses the trimmed events. Events given
+// up on go to the stream's ".dead" twin, trimmed the same way.
+type EventStream struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+	log    *logrus.Logger
+}
+
+func NewEventStream(client *redis.Client, stream string, maxLen int64, log *logrus.Logger) model.EventStream {
+	return &EventStream{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+		log:    log,
+	}
+}
+
+func (s *EventStream) Publish(ctx context.Context, message *entity.OutboxMessage) error {
+	values := map[string]any{
+		"event":      message.Event,
+		"store_id":   message.StoreID,
+		"payload":    string(message.Payload),
+		"created_at": message.CreatedAt.Format(time.RFC3339Nano),
+	}
+	if message.ID != 0 {
+		values["outbox_id"] = message.ID
+		values["order_id"] = message.OrderID
+	}
+
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: values,
+	}).Err()
+}
+
+// CreateGroup starts a group at the end of the stream, so a new group does
+// not replay history. An existing group is left alone.
+func (s *EventStream) CreateGroup(ctx context.Context, group string) error {
+	err := s.client.XGroupCreateMkStream(ctx, s.stream, group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	return nil
+}
+
+// Read waits up to block for events no reader of the group has seen yet.
+func (s *EventStream) Read(ctx context.Context, group string, consumer string, count int, block time.Duration) ([]model.StreamEvent, error) {
+	streams, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{s.stream, ">"},
+		Count:    int64(count),
+		Block:    block,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	events := []model.StreamEvent{}
+	for _, stream := range streams {
+		events = append(events, streamEvents(stream.Messages)...)
+	}
+	for i := range events {
+		events[i].Deliveries = 1
+	}
+
+	return events, nil
+}
+
+func (s *EventStream) Claim(ctx context.Context, group string, consumer string, idle time.Duration, count int) ([]model.StreamEvent, error) {
+	messages, _, err := s.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   s.stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  idle,
+		Start:    "0",
+		Count:    int64(count),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	events := streamEvents(messages)
+	if len(events) == 0 {
+		return events, nil
+	}
+
+	// The claim counted as a delivery; XPENDING says how many came before.
+	pending, err := s.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: s.stream,
+		Group:  group,
+		Start:  events[0].StreamID,
+		End:    events[len(events)-1].StreamID,
+		Count:  int64(len(events)),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make(map[string]int64, len(pending))
+	for _, entry := range pending {
+		deliveries[entry.ID] = entry.RetryCount
+	}
+	for i := range events {
+		events[i].Deliveries = deliveries[events[i].StreamID]
+	}
+
+	return events, nil
+}
+
+func (s *EventStream) Ack(ctx context.Context, group string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return s.client.XAck(ctx, s.stream, group, ids...).Err()
+}
+
+func (s *EventStream) DeadLetter(ctx context.Context, event model.StreamEvent, reason string) error {
+	values := map[string]any{
+		"stream_id":  event.StreamID,
+		"event":      event.Name,
+		"store_id":   event.StoreID,
+		"payload":    string(event.Payload),
+		"created_at": event.CreatedAt.Format(time.RFC3339Nano),
+		"deliveries": event.Deliveries,
+		"reason":     reason,
+	}
+	if event.OutboxID != "" {
+		values["outbox_id"] = event.OutboxID
+	}
+
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream + ".dead",
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: values,
+	}).Err()
+}
+
+func streamEvents(messages []redis.XMessage) []model.StreamEvent {
+	events := make([]model.StreamEvent, 0, len(messages))
+	for _, message := range messages {
+		event := model.StreamEvent{StreamID: message.ID}
+		event.OutboxID, _ = message.Values["outbox_id"].(string)
+		event.Name, _ = message.Values["event"].(string)
+		if storeID, ok := message.Values["store_id"].(string); ok {
+			event.StoreID, _ = strconv.Atoi(storeID)
+		}
+		if payload, ok := message.Values["payload"].(string); ok {
+			event.Payload = []byte(payload)
+		}
+		if createdAt, ok := message.Values["created_at"].(string); ok {
+			event.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"coffee/internal/model"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	printQueueKey = "print:jobs"  // list of job ids ready to print
+	printRetryKey = "print:retry" // job ids scored by when to retry them
+)
+
+// PrintQueue hands print job ids to the print workers. The jobs themselves
+// live in Postgres; Redis only holds what to print next.
+type PrintQueue struct {
+	client *redis.Client
+	log    *logrus.Logger
+}
+
+func NewPrintQueue(client *redis.Client, log *logrus.Logger) model.PrintQueue {
+	return &PrintQueue{
+		client: client,
+		log:    log,
+	}
+}
+
+func (q *PrintQueue) Push(ctx context.Context, jobID int) error {
+	if err := q.client.LPush(ctx, printQueueKey, jobID).Err(); err != nil {
+		q.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// Pop waits up to timeout for the next job. It returns 0 when there is none.
+func (q *PrintQueue) Pop(ctx context.Context, timeout time.Duration) (int, error) {
+	result, err := q.client.BRPop(ctx, timeout, printQueueKey).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return strconv.Atoi(result[1])
+}
+
+func (q *PrintQueue) Retry(ctx context.Context, jobID int, at time.Time) error {
+	if err := q.client.ZAdd(ctx, printRetryKey, redis.Z{Score: float64(at.Unix()), Member: jobID}).Err(); err != nil {
+		q.log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
+
+// PromoteDue moves retries that are due back onto the queue. Several workers
+// may promote at once; only the one whose ZREM succeeds pushes the job.
+func (q *PrintQueue) PromoteDue(ctx context.Context, now time.Time) error {
+	due, err := q.client.ZRangeByScore(ctx, printRetryKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, member := range due {
+		removed, err := q.client.ZRem(ctx, printRetryKey, member).Result()
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			continue
+		}
+		if err := q.client.LPush(ctx, printQueueKey, member).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
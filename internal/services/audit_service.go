@@ -0,0 +1,216 @@
+package services
+
+import (
+	"bytes"
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultAuditLimit = 50
+
+// auditRedacted are fields whose values never go into the log; that they
+// changed is still recorded.
+var auditRedacted = []string{"secret", "password", "pin", "pin_hash"}
+
+// auditIgnored change on every write and say nothing about what changed.
+var auditIgnored = []string{"updated_at"}
+
+// AuditService records administrative changes: who made them, through which
+// request, and field by field what changed.
+type AuditService struct {
+	Repo     model.AuditRepository
+	Validate *validator.Validate
+	Log      *logrus.Logger
+}
+
+func NewAuditService(repo model.AuditRepository, validate *validator.Validate, log *logrus.Logger) model.AuditService {
+	return &AuditService{
+		Repo:     repo,
+		Validate: validate,
+		Log:      log,
+	}
+}
+
+// Record logs a change of one entity. before is nil for a create and after
+// for a delete. An update that changed nothing is not logged. The change is
+// already made, so a failure to log it is only reported.
+func (s *AuditService) Record(ctx context.Context, auth *model.Auth, storeID int, entityType string, entityID int, before, after any) {
+	action := entity.AuditUpdate
+	switch {
+	case isNil(before):
+		action = entity.AuditCreate
+	case isNil(after):
+		action = entity.AuditDelete
+	}
+
+	changes, err := auditChanges(before, after)
+	if err != nil {
+		s.Log.Warnf("failed to diff %s %d for the audit log: %v", entityType, entityID, err)
+		return
+	}
+	if len(changes) == 0 && action == entity.AuditUpdate {
+		return
+	}
+
+	entry := &entity.AuditEntry{
+		At:         time.Now(),
+		ActorID:    auth.Id,
+		ActorRole:  auth.Role,
+		StoreID:    storeID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Changes:    changes,
+		RequestID:  model.RequestID(ctx),
+	}
+	if err := s.Repo.Store(ctx, entry); err != nil {
+		s.Log.Warnf("failed to audit %s of %s %d by %s: %v", action, entityType, entityID, auth.Id, err)
+	}
+}
+
+// Search lists audit entries, newest first. Admins search every store unless
+// they pick one; everyone else sees their own store.
+func (s *AuditService) Search(ctx context.Context, auth *model.Auth, request *model.AuditSearchRequest) ([]entity.AuditEntry, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid audit filter", apperrors.GetValidateMessage(err))
+	}
+
+	filter := &model.AuditFilter{
+		EntityType: request.EntityType,
+		EntityID:   request.EntityID,
+		ActorID:    request.ActorID,
+		RequestID:  request.RequestID,
+		Limit:      request.Limit,
+	}
+	// Both are validated as RFC 3339 already.
+	filter.From, _ = time.Parse(time.RFC3339, request.From)
+	filter.To, _ = time.Parse(time.RFC3339, request.To)
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+
+	if auth.Role == entity.RoleAdmin {
+		if request.StoreID != 0 {
+			filter.StoreID = &request.StoreID
+		}
+	} else {
+		storeID, err := auth.ScopeStore(request.StoreID)
+		if err != nil {
+			return nil, err
+		}
+		filter.StoreID = &storeID
+	}
+
+	return s.Repo.Search(ctx, filter)
+}
+
+// auditChanges compares the JSON of two values field by field, nested fields
+// and list elements by their path.
+func auditChanges(before, after any) ([]entity.AuditChange, error) {
+	old, err := flattenJSON(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := flattenJSON(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(old)+len(updated))
+	for field := range old {
+		fields = append(fields, field)
+	}
+	for field := range updated {
+		if _, ok := old[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+
+	changes := []entity.AuditChange{}
+	for _, field := range fields {
+		leaf := field[strings.LastIndex(field, ".")+1:]
+		if slices.Contains(auditIgnored, leaf) || reflect.DeepEqual(old[field], updated[field]) {
+			continue
+		}
+
+		change := entity.AuditChange{Field: field, Before: old[field], After: updated[field]}
+		if slices.Contains(auditRedacted, leaf) {
+			change.Before, change.After = "[redacted]", "[redacted]"
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+func flattenJSON(value any) (map[string]any, error) {
+	fields := map[string]any{}
+	if isNil(value) {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	flatten(fields, "", tree)
+	return fields, nil
+}
+
+func flatten(fields map[string]any, path string, node any) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch node := node.(type) {
+	case map[string]any:
+		for key, child := range node {
+			flatten(fields, join(key), child)
+		}
+	case []any:
+		if len(node) == 0 {
+			fields[path] = node
+		}
+		for i, child := range node {
+			flatten(fields, join(strconv.Itoa(i)), child)
+		}
+	case json.Number:
+		if number, err := node.Int64(); err == nil {
+			fields[path] = number
+		} else {
+			fields[path], _ = node.Float64()
+		}
+	default:
+		fields[path] = node
+	}
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"coffee/internal/utils"
+	"context"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const maxCartLines = 50
+
+// CartService runs the guest carts of QR table ordering. Every change is
+// priced right away so the guest sees errors while browsing, and the order
+// is priced again on checkout.
+type CartService struct {
+	Carts    model.CartRepository
+	Stores   model.StoreRepository
+	Orders   model.OrderService
+	Validate *validator.Validate
+	Log      *logrus.Logger
+}
+
+func NewCartService(carts model.CartRepository, stores model.StoreRepository, orders model.OrderService, validate *validator.Validate, log *logrus.Logger) model.CartService {
+	return &CartService{
+		Carts:    carts,
+		Stores:   stores,
+		Orders:   orders,
+		Validate: validate,
+		Log:      log,
+	}
+}
+
+func (s *CartService) Open(ctx context.Context, slug string, tableNumber string) (*model.CartResponse, error) {
+	store, err := s.Stores.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !store.IsActive {
+		return nil, fiber.ErrNotFound
+	}
+
+	table, err := s.Stores.FindTable(ctx, store.ID, tableNumber)
+	if err != nil {
+		if errors.Is(err, fiber.ErrNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "unknown table")
+		}
+		return nil, err
+	}
+	if !table.IsActive {
+		return nil, fiber.NewError(fiber.StatusConflict, "this table is not taking orders")
+	}
+
+	token, err := utils.RandomToken(24)
+	if err != nil {
+		s.Log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	cart := &model.Cart{
+		Token:       token,
+		StoreID:     store.ID,
+		StoreSlug:   store.StoreSlug,
+		TableNumber: table.TableNumber,
+		Items:       []model.PlaceOrderItem{},
+	}
+	if err := s.Carts.Save(ctx, cart); err != nil {
+		return nil, err
+	}
+
+	return s.price(ctx, cart)
+}
+
+func (s *CartService) Get(ctx context.Context, token string) (*model.CartResponse, error) {
+	cart, err := s.Carts.Find(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.price(ctx, cart)
+}
+
+func (s *CartService) AddItem(ctx context.Context, token string, request *model.PlaceOrderItem) (*model.CartResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid item", apperrors.GetValidateMessage(err))
+	}
+
+	var response *model.CartResponse
+	_, err := s.Carts.Update(ctx, token, func(cart *model.Cart) error {
+		if len(cart.Items) >= maxCartLines {
+			return fiber.NewError(fiber.StatusBadRequest, "the cart is full")
+		}
+
+		cart.Items = append(cart.Items, *request)
+		var err error
+		response, err = s.price(ctx, cart)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *CartService) RemoveItem(ctx context.Context, token string, index int) (*model.CartResponse, error) {
+	cart, err := s.Carts.Update(ctx, token, func(cart *model.Cart) error {
+		if index < 0 || index >= len(cart.Items) {
+			return fiber.NewError(fiber.StatusNotFound, "no such cart item")
+		}
+
+		cart.Items = append(cart.Items[:index], cart.Items[index+1:]...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.price(ctx, cart)
+}
+
+// Checkout claims the cart and places the order, so a second submit of the
+// same cart fails with a conflict instead of ordering twice.
+func (s *CartService) Checkout(ctx context.Context, token string, request *model.CheckoutRequest) (*model.OrderResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid checkout", apperrors.GetValidateMessage(err))
+	}
+
+	cart, err := s.Carts.Find(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "the cart is empty")
+	}
+
+	cart, err = s.Carts.Claim(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.Orders.PlaceGuest(ctx, cart.StoreID, cart.TableNumber, &model.PlaceOrderRequest{
+		CustomerPhone: request.CustomerPhone,
+		CustomerName:  request.CustomerName,
+		CustomerNote:  request.CustomerNote,
+		Items:         cart.Items,
+	})
+	if err != nil {
+		// Give the cart back so the guest can fix it and try again.
+		if saveErr := s.Carts.Save(ctx, cart); saveErr != nil {
+			s.Log.Warnf("failed to restore cart %s: %v", token, saveErr)
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (s *CartService) price(ctx context.Context, cart *model.Cart) (*model.CartResponse, error) {
+	response := &model.CartResponse{Cart: cart, Items: []entity.OrderItem{}}
+	if len(cart.Items) == 0 {
+		return response, nil
+	}
+
+	items, subtotal, err := s.Orders.Quote(ctx, cart.StoreID, cart.Items)
+	if err != nil {
+		return nil, err
+	}
+	response.Items = items
+	response.Subtotal = subtotal
+
+	return response, nil
+}
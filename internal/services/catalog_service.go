@@ -0,0 +1,270 @@
+package services
+
+import (
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"coffee/internal/model/converter"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// CatalogService moves a store's whole menu in and out as CSV or JSON, and
+// copies it between stores. An import is checked row by row first and only
+// written when every row is fine; a dry run stops after telling what would
+// change.
+type CatalogService struct {
+	Repo     model.CatalogRepository
+	Stores   model.StoreRepository
+	Events   model.EventBus
+	Audit    model.AuditService
+	Validate *validator.Validate
+	Log      *logrus.Logger
+}
+
+func NewCatalogService(repo model.CatalogRepository, stores model.StoreRepository, events model.EventBus, audit model.AuditService, validate *validator.Validate, log *logrus.Logger) model.CatalogService {
+	return &CatalogService{
+		Repo:     repo,
+		Stores:   stores,
+		Events:   events,
+		Audit:    audit,
+		Validate: validate,
+		Log:      log,
+	}
+}
+
+func (s *CatalogService) Export(ctx context.Context, auth *model.Auth, storeID int, request *model.ExportCatalogRequest, w io.Writer) error {
+	if err := s.Validate.Struct(request); err != nil {
+		return apperrors.NewBadRequest("invalid export", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := s.store(ctx, auth, storeID)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := s.Repo.Export(ctx, storeID)
+	if err != nil {
+		return err
+	}
+
+	if request.Format == "csv" {
+		return converter.CatalogToCSV(w, catalog)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(catalog)
+}
+
+func (s *CatalogService) Import(ctx context.Context, auth *model.Auth, storeID int, request *model.ImportCatalogRequest, body io.Reader) (*model.ImportResult, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid import", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := s.store(ctx, auth, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var catalog *model.Catalog
+	var rowErrors []model.ImportError
+	if request.Format == "csv" {
+		catalog, rowErrors, err = converter.CatalogFromCSV(body)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("not a catalog CSV: %v", err))
+		}
+	} else {
+		catalog = new(model.Catalog)
+		if err := json.NewDecoder(body).Decode(catalog); err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("not a catalog JSON: %v", err))
+		}
+		numberRows(catalog)
+	}
+
+	for i := range catalog.Items {
+		item := &catalog.Items[i]
+		if item.Tags != nil {
+			item.Tags = labels(item.Tags)
+		}
+		if item.Allergens != nil {
+			item.Allergens = labels(item.Allergens)
+		}
+	}
+
+	current, err := s.Repo.Export(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	rowErrors = append(rowErrors, s.check(catalog, current)...)
+	if len(rowErrors) > 0 {
+		return &model.ImportResult{DryRun: request.DryRun, Errors: rowErrors}, nil
+	}
+
+	result, err := s.Repo.Import(ctx, storeID, catalog, auth.UserID(), request.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Applied {
+		return result, nil
+	}
+
+	s.Audit.Record(ctx, auth, storeID, "menu_import", storeID, nil, result)
+	for _, id := range result.MenuItemIDs {
+		event := &model.MenuItemChanged{MenuItemID: id, Reason: "import"}
+		if err := s.Events.Publish(ctx, storeID, event); err != nil {
+			s.Log.Warnf("failed to publish import of menu item %d: %v", id, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Clone sets up the store's menu as a copy of another store's.
+func (s *CatalogService) Clone(ctx context.Context, auth *model.Auth, storeID int, request *model.CloneStoreRequest) (*model.CloneStoreResult, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid clone", apperrors.GetValidateMessage(err))
+	}
+	if request.SourceStoreID == storeID {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "a store cannot be cloned into itself")
+	}
+
+	storeID, err := s.store(ctx, auth, storeID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.Stores.FindById(ctx, request.SourceStoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.Repo.Clone(ctx, source.ID, storeID, request.SkipPriceOverrides, auth.UserID())
+	if err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(ctx, auth, storeID, "store_clone", storeID, nil, result)
+	for _, id := range result.MenuItemIDs {
+		event := &model.MenuItemChanged{MenuItemID: id, Reason: "clone"}
+		if err := s.Events.Publish(ctx, storeID, event); err != nil {
+			s.Log.Warnf("failed to publish clone of menu item %d: %v", id, err)
+		}
+	}
+
+	return result, nil
+}
+
+// check validates every row and that names are unique and refer to
+// categories and groups that exist, in the file or already in the store.
+// Categories are told apart by their path.
+func (s *CatalogService) check(catalog, current *model.Catalog) []model.ImportError {
+	problems := []model.ImportError{}
+	invalid := func(row string, err error) {
+		for _, field := range apperrors.GetValidateMessage(err) {
+			problems = append(problems, model.ImportError{Row: row, Field: field.Field, Message: field.Message})
+		}
+	}
+	duplicate := func(seen map[string]string, row, field, name string) {
+		key := strings.ToLower(name)
+		if first, ok := seen[key]; ok {
+			problems = append(problems, model.ImportError{Row: row, Field: field, Message: fmt.Sprintf("%q is already in %s", name, first)})
+			return
+		}
+		seen[key] = row
+	}
+
+	knownCategories := map[string]bool{}
+	for _, category := range current.Categories {
+		knownCategories[model.CategoryPathKey(model.CategoryPath(category.Parent, category.Name))] = true
+	}
+
+	categories := map[string]string{}
+	for _, category := range catalog.Categories {
+		if err := s.Validate.Struct(category); err != nil {
+			invalid(category.Row, err)
+		}
+		duplicate(categories, category.Row, "name", model.CategoryPathKey(model.CategoryPath(category.Parent, category.Name)))
+	}
+	for _, category := range catalog.Categories {
+		parent := model.CategoryPathKey(category.Parent)
+		if _, ok := categories[parent]; category.Parent != "" && !ok && !knownCategories[parent] {
+			problems = append(problems, model.ImportError{Row: category.Row, Field: "parent", Message: fmt.Sprintf("unknown category %q", category.Parent)})
+		}
+	}
+
+	groups := map[string]string{}
+	for _, group := range catalog.Groups {
+		if err := s.Validate.Struct(group); err != nil {
+			invalid(group.Row, err)
+		}
+		duplicate(groups, group.Row, "name", group.Name)
+
+		labels := map[string]string{}
+		for _, option := range group.Options {
+			if err := s.Validate.Struct(option); err != nil {
+				invalid(option.Row, err)
+			}
+			duplicate(labels, option.Row, "label", option.Label)
+		}
+	}
+
+	knownGroups := map[string]bool{}
+	for _, group := range current.Groups {
+		knownGroups[strings.ToLower(group.Name)] = true
+	}
+
+	items := map[string]string{}
+	for _, item := range catalog.Items {
+		if err := s.Validate.Struct(item); err != nil {
+			invalid(item.Row, err)
+		}
+		duplicate(items, item.Row, "name", item.Name)
+
+		category := model.CategoryPathKey(item.Category)
+		if _, ok := categories[category]; item.Category != "" && !ok && !knownCategories[category] {
+			problems = append(problems, model.ImportError{Row: item.Row, Field: "category", Message: fmt.Sprintf("unknown category %q", item.Category)})
+		}
+		for _, name := range item.Groups {
+			group := strings.ToLower(name)
+			if _, ok := groups[group]; name != "" && !ok && !knownGroups[group] {
+				problems = append(problems, model.ImportError{Row: item.Row, Field: "groups", Message: fmt.Sprintf("unknown customization group %q", name)})
+			}
+		}
+	}
+
+	return problems
+}
+
+// store scopes the request to a store that exists.
+func (s *CatalogService) store(ctx context.Context, auth *model.Auth, storeID int) (int, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.Stores.FindById(ctx, storeID); err != nil {
+		return 0, err
+	}
+
+	return storeID, nil
+}
+
+// numberRows names the rows of a JSON catalog after their place in it.
+func numberRows(catalog *model.Catalog) {
+	for i := range catalog.Categories {
+		catalog.Categories[i].Row = fmt.Sprintf("categories[%d]", i)
+	}
+	for i := range catalog.Items {
+		catalog.Items[i].Row = fmt.Sprintf("items[%d]", i)
+	}
+	for i := range catalog.Groups {
+		catalog.Groups[i].Row = fmt.Sprintf("groups[%d]", i)
+		for j := range catalog.Groups[i].Options {
+			catalog.Groups[i].Options[j].Row = fmt.Sprintf("groups[%d].options[%d]", i, j)
+		}
+	}
+}
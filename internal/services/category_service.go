@@ -0,0 +1,222 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// CategoryService manages the global category tree and how each store shows
+// it: under its own names, with categories hidden, or in its own order.
+type CategoryService struct {
+	Repo     model.CategoryRepository
+	Stores   model.StoreRepository
+	Events   model.EventBus
+	Audit    model.AuditService
+	Validate *validator.Validate
+	Log      *logrus.Logger
+}
+
+func NewCategoryService(repo model.CategoryRepository, stores model.StoreRepository, events model.EventBus, audit model.AuditService, validate *validator.Validate, log *logrus.Logger) model.CategoryService {
+	return &CategoryService{
+		Repo:     repo,
+		Stores:   stores,
+		Events:   events,
+		Audit:    audit,
+		Validate: validate,
+		Log:      log,
+	}
+}
+
+func (s *CategoryService) List(ctx context.Context, request *model.ListCategoriesRequest) ([]entity.Category, error) {
+	return s.Repo.FindAll(ctx, request.IncludeInactive)
+}
+
+func (s *CategoryService) Create(ctx context.Context, auth *model.Auth, request *model.CreateCategoryRequest) (*entity.Category, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid category", apperrors.GetValidateMessage(err))
+	}
+
+	category := &entity.Category{
+		Name:     request.Name,
+		Icon:     request.Icon,
+		Color:    request.Color,
+		IsActive: true,
+	}
+	if category.Color == "" {
+		category.Color = entity.DefaultCategoryColor
+	}
+	if request.ParentID != 0 {
+		category.ParentID = &request.ParentID
+	}
+
+	if err := s.Repo.Save(ctx, category); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, 0, "category", category.ID, nil, category)
+
+	return category, nil
+}
+
+func (s *CategoryService) Update(ctx context.Context, auth *model.Auth, id int, request *model.UpdateCategoryRequest) (*entity.Category, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid category", apperrors.GetValidateMessage(err))
+	}
+
+	category, err := s.Repo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *category
+	if request.Name != "" {
+		category.Name = request.Name
+	}
+	if request.Icon != nil {
+		category.Icon = *request.Icon
+	}
+	if request.Color != "" {
+		category.Color = request.Color
+	}
+	if request.IsActive != nil {
+		category.IsActive = *request.IsActive
+	}
+	if request.ParentID != nil {
+		category.ParentID = nil
+		if *request.ParentID != 0 {
+			category.ParentID = request.ParentID
+		}
+	}
+
+	if err := s.Repo.Save(ctx, category); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, 0, "category", category.ID, &before, category)
+	s.publish(ctx, 0, category.ID, "category")
+
+	return category, nil
+}
+
+func (s *CategoryService) Remove(ctx context.Context, auth *model.Auth, id int) error {
+	category, err := s.Repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Repo.Remove(ctx, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, auth, 0, "category", category.ID, category, nil)
+
+	return nil
+}
+
+func (s *CategoryService) StoreCategories(ctx context.Context, auth *model.Auth, storeID int) ([]model.StoreCategoryDetail, error) {
+	storeID, err := s.store(ctx, auth, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repo.FindStoreCategories(ctx, storeID)
+}
+
+func (s *CategoryService) UpdateStoreCategory(ctx context.Context, auth *model.Auth, storeID, categoryID int, request *model.UpdateStoreCategoryRequest) (*model.StoreCategoryDetail, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid store category", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := s.store(ctx, auth, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.Repo.FindStoreCategory(ctx, storeID, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *current
+	if request.Name != nil {
+		current.StoreName = *request.Name
+	}
+	if request.IsVisible != nil {
+		current.IsVisible = *request.IsVisible
+	}
+
+	override := &entity.StoreCategory{
+		StoreID:    storeID,
+		CategoryID: current.ID,
+		Name:       current.StoreName,
+		IsVisible:  current.IsVisible,
+		SortOrder:  current.SortOrder,
+	}
+	if err := s.Repo.SaveStoreCategory(ctx, override); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, storeID, "store_category", override.ID, &before, current)
+	s.publish(ctx, storeID, current.ID, "category")
+
+	return current, nil
+}
+
+// Reorder moves the given categories to the top of the store's menu.
+func (s *CategoryService) Reorder(ctx context.Context, auth *model.Auth, storeID int, request *model.ReorderCategoriesRequest) ([]model.StoreCategoryDetail, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid category order", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := s.store(ctx, auth, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.Repo.FindStoreCategories(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repo.Reorder(ctx, storeID, request.CategoryIDs); err != nil {
+		return nil, err
+	}
+	after, err := s.Repo.FindStoreCategories(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Audit.Record(ctx, auth, storeID, "category_order", storeID, categoryOrder(before), categoryOrder(after))
+	s.publish(ctx, storeID, 0, "category_order")
+
+	return after, nil
+}
+
+// store scopes the request to a store that exists.
+func (s *CategoryService) store(ctx context.Context, auth *model.Auth, storeID int) (int, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.Stores.FindById(ctx, storeID); err != nil {
+		return 0, err
+	}
+
+	return storeID, nil
+}
+
+func (s *CategoryService) publish(ctx context.Context, storeID, categoryID int, reason string) {
+	event := &model.MenuItemChanged{CategoryID: categoryID, Reason: reason}
+	if err := s.Events.Publish(ctx, storeID, event); err != nil {
+		s.Log.Warnf("failed to publish change of category %d: %v", categoryID, err)
+	}
+}
+
+// categoryOrder is what the audit log keeps of a store's category order.
+func categoryOrder(categories []model.StoreCategoryDetail) map[string][]int {
+	ids := make([]int, 0, len(categories))
+	for _, category := range categories {
+		ids = append(ids, category.ID)
+	}
+	return map[string][]int{"category_ids": ids}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"coffee/internal/utils"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+type CustomerService struct {
+	Customers model.CustomerRepository
+	Loyalty   model.LoyaltyService
+	Cards     model.StampService
+	Validate  *validator.Validate
+	Log       *logrus.Logger
+}
+
+func NewCustomerService(customers model.CustomerRepository, loyalty model.LoyaltyService, stamps model.StampService, validate *validator.Validate, log *logrus.Logger) model.CustomerService {
+	return &CustomerService{
+		Customers: customers,
+		Loyalty:   loyalty,
+		Cards:     stamps,
+		Validate:  validate,
+		Log:       log,
+	}
+}
+
+func (s *CustomerService) Register(ctx context.Context, request *model.CreateCustomerRequest) (*model.CustomerResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid customer", apperrors.GetValidateMessage(err))
+	}
+
+	customer := &entity.Customer{
+		Phone:    utils.NormalizePhone(request.Phone),
+		FullName: request.FullName,
+		Email:    request.Email,
+	}
+
+	if err := s.Loyalty.Enroll(ctx, customer); err != nil {
+		return nil, err
+	}
+
+	return s.Loyalty.Describe(customer), nil
+}
+
+func (s *CustomerService) Lookup(ctx context.Context, request *model.CustomerLookupRequest) (*model.CustomerResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid phone number", apperrors.GetValidateMessage(err))
+	}
+
+	customer, err := s.Customers.FindByPhone(ctx, utils.NormalizePhone(request.Phone))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, customer.ID)
+}
+
+// Get expires outdated points first so the balance shown is spendable.
+func (s *CustomerService) Get(ctx context.Context, id int) (*model.CustomerResponse, error) {
+	if err := s.Customers.ExpirePoints(ctx, id); err != nil {
+		return nil, err
+	}
+
+	customer, err := s.Customers.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Loyalty.Describe(customer), nil
+}
+
+func (s *CustomerService) Ledger(ctx context.Context, id int) ([]entity.LoyaltyEntry, error) {
+	if _, err := s.Customers.FindById(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.Customers.FindLedger(ctx, id, 100)
+}
+
+func (s *CustomerService) Stamps(ctx context.Context, id int) ([]model.StampCardResponse, error) {
+	if _, err := s.Customers.FindById(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.Cards.Cards(ctx, id)
+}
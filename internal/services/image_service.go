@@ -0,0 +1,225 @@
+package services
+
+import (
+	"bytes"
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"coffee/internal/utils"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	_ "image/gif"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// imageTypes are the uploads we take, with the extension they are stored
+// under.
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// ImageService takes menu item photos, makes thumbnails of them and hands the
+// files out again. Every upload gets new file names, so clients may cache
+// them for good.
+type ImageService struct {
+	Menu      model.MenuRepository
+	Storage   model.FileStorage
+	Events    model.EventBus
+	Audit     model.AuditService
+	Log       *logrus.Logger
+	BaseURL   string
+	MaxBytes  int64
+	MaxPixels int
+	Widths    []int
+	MaxAge    time.Duration
+}
+
+func NewImageService(menu model.MenuRepository, storage model.FileStorage, events model.EventBus, audit model.AuditService, viper *viper.Viper, log *logrus.Logger) model.ImageService {
+	return &ImageService{
+		Menu:      menu,
+		Storage:   storage,
+		Events:    events,
+		Audit:     audit,
+		Log:       log,
+		BaseURL:   strings.TrimSuffix(viper.GetString("media.base_url"), "/"),
+		MaxBytes:  viper.GetInt64("media.max_upload_bytes"),
+		MaxPixels: viper.GetInt("media.max_pixels"),
+		Widths:    viper.GetIntSlice("media.thumbnail_widths"),
+		MaxAge:    viper.GetDuration("media.cache_max_age"),
+	}
+}
+
+// UploadMenuItemImage replaces the photo of a menu item. The old files are
+// removed once the item points at the new ones.
+func (s *ImageService) UploadMenuItemImage(ctx context.Context, auth *model.Auth, menuItemID int, upload *multipart.FileHeader) (*model.MenuItemImage, error) {
+	item, err := s.Menu.FindItem(ctx, menuItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.read(upload)
+	if err != nil {
+		return nil, err
+	}
+
+	extension, ok := imageTypes[http.DetectContentType(data)]
+	if !ok {
+		return nil, apperrors.NewUnsupportedMediaType("images must be JPEG, PNG or GIF")
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "the image cannot be read")
+	}
+	if config.Width*config.Height > s.MaxPixels {
+		return nil, apperrors.NewPayloadTooLarge(fmt.Sprintf("images may have at most %d pixels", s.MaxPixels))
+	}
+	picture, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "the image cannot be read")
+	}
+
+	token, err := utils.RandomToken(8)
+	if err != nil {
+		s.Log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+	key := fmt.Sprintf("menu-items/%d/%s%s", item.ID, token, extension)
+
+	if err := s.Storage.Put(ctx, key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	result := &model.MenuItemImage{
+		MenuItemID: item.ID,
+		URL:        s.url(key),
+		Width:      config.Width,
+		Height:     config.Height,
+		Thumbnails: []model.ImageVariant{},
+	}
+
+	for _, width := range s.Widths {
+		if width >= config.Width {
+			continue
+		}
+		thumbnail := utils.ResizeToWidth(picture, width)
+
+		var encoded bytes.Buffer
+		if extension == ".jpg" {
+			err = jpeg.Encode(&encoded, thumbnail, &jpeg.Options{Quality: 85})
+		} else {
+			err = png.Encode(&encoded, thumbnail)
+		}
+		if err != nil {
+			s.Log.Warn(err)
+			return nil, fiber.ErrInternalServerError
+		}
+
+		variant := thumbnailKey(key, width)
+		if err := s.Storage.Put(ctx, variant, &encoded); err != nil {
+			return nil, err
+		}
+		result.Thumbnails = append(result.Thumbnails, model.ImageVariant{
+			Width:  width,
+			Height: thumbnail.Bounds().Dy(),
+			URL:    s.url(variant),
+		})
+	}
+
+	if err := s.Menu.UpdateImage(ctx, item.ID, result.URL); err != nil {
+		return nil, err
+	}
+
+	before := *item
+	item.ImageURL = result.URL
+	s.Audit.Record(ctx, auth, 0, "menu_item", item.ID, &before, item)
+	if err := s.Events.Publish(ctx, 0, &model.MenuItemChanged{MenuItemID: item.ID, Reason: "image"}); err != nil {
+		s.Log.Warnf("failed to publish image change of menu item %d: %v", item.ID, err)
+	}
+	s.remove(ctx, before)
+
+	return result, nil
+}
+
+// Open hands out a stored file. Keys are never reused, so it may be cached
+// as long as MaxAge says.
+func (s *ImageService) Open(ctx context.Context, key string) (*model.MediaFile, error) {
+	body, file, err := s.Storage.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MediaFile{StoredFile: *file, Body: body, MaxAge: s.MaxAge}, nil
+}
+
+// read loads the upload, refusing anything over the size limit.
+func (s *ImageService) read(upload *multipart.FileHeader) ([]byte, error) {
+	tooLarge := apperrors.NewPayloadTooLarge(fmt.Sprintf("images may be at most %d bytes", s.MaxBytes))
+	if upload.Size > s.MaxBytes {
+		return nil, tooLarge
+	}
+
+	file, err := upload.Open()
+	if err != nil {
+		s.Log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, s.MaxBytes+1))
+	if err != nil {
+		s.Log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if int64(len(data)) > s.MaxBytes {
+		return nil, tooLarge
+	}
+
+	return data, nil
+}
+
+// remove deletes the files of a replaced image, if they are ours.
+func (s *ImageService) remove(ctx context.Context, item entity.MenuItem) {
+	key, ok := strings.CutPrefix(item.ImageURL, s.BaseURL+"/")
+	if !ok || item.ImageURL == "" {
+		return
+	}
+
+	keys := []string{key}
+	for _, width := range s.Widths {
+		keys = append(keys, thumbnailKey(key, width))
+	}
+	for _, key := range keys {
+		if err := s.Storage.Delete(ctx, key); err != nil {
+			s.Log.Warnf("failed to remove old image %s of menu item %d: %v", key, item.ID, err)
+		}
+	}
+}
+
+func (s *ImageService) url(key string) string {
+	return s.BaseURL + "/" + key
+}
+
+// thumbnailKey names a thumbnail after its image: photo.jpg is photo-320.jpg
+// at 320 pixels wide. Thumbnails of anything but JPEG are PNG.
+func thumbnailKey(key string, width int) string {
+	extension := path.Ext(key)
+	base := strings.TrimSuffix(key, extension)
+	if extension != ".jpg" {
+		extension = ".png"
+	}
+	return fmt.Sprintf("%s-%d%s", base, width, extension)
+}
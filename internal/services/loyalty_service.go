@@ -0,0 +1,199 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type loyaltyTier struct {
+	Name       string  `mapstructure:"name"`
+	MinSpend   int64   `mapstructure:"min_spend"`
+	Multiplier float64 `mapstructure:"multiplier"`
+}
+
+// LoyaltyService holds the points rules: customers earn points per rupiah of
+// completed orders, scaled by their tier, and spend them as an order discount.
+type LoyaltyService struct {
+	Customers        model.CustomerRepository
+	Log              *logrus.Logger
+	RupiahPerPoint   int64
+	PointValue       int64
+	MaxRedeemPercent int64
+	ExpiryMonths     int
+	Tiers            []loyaltyTier // ascending by MinSpend
+}
+
+func NewLoyaltyService(customers model.CustomerRepository, viper *viper.Viper, log *logrus.Logger) model.LoyaltyService {
+	tiers := []loyaltyTier{}
+	if err := viper.UnmarshalKey("loyalty.tiers", &tiers); err != nil || len(tiers) == 0 {
+		log.Warnf("invalid loyalty.tiers, using a single tier: %v", err)
+		tiers = []loyaltyTier{{Name: "bronze", Multiplier: 1}}
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinSpend < tiers[j].MinSpend })
+
+	return &LoyaltyService{
+		Customers:        customers,
+		Log:              log,
+		RupiahPerPoint:   max(viper.GetInt64("loyalty.rupiah_per_point"), 1),
+		PointValue:       viper.GetInt64("loyalty.point_value"),
+		MaxRedeemPercent: viper.GetInt64("loyalty.max_redeem_percent"),
+		ExpiryMonths:     viper.GetInt("loyalty.expiry_months"),
+		Tiers:            tiers,
+	}
+}
+
+// Identify finds the customer by phone number, signing them up on first use.
+func (s *LoyaltyService) Identify(ctx context.Context, phone string, name string) (*entity.Customer, error) {
+	phone = utils.NormalizePhone(phone)
+
+	customer, err := s.Customers.FindByPhone(ctx, phone)
+	if err == nil {
+		return customer, nil
+	}
+	if !errors.Is(err, fiber.ErrNotFound) {
+		return nil, err
+	}
+
+	customer = &entity.Customer{
+		Phone:    phone,
+		FullName: name,
+	}
+	if err := s.Enroll(ctx, customer); err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// Enroll stores a new customer in the entry tier.
+func (s *LoyaltyService) Enroll(ctx context.Context, customer *entity.Customer) error {
+	customer.Tier = s.Tiers[0].Name
+	return s.Customers.Store(ctx, customer)
+}
+
+// Redemption prices spending points on an order and returns the hook that
+// books it in the order's transaction. The balance itself is checked there,
+// under lock.
+func (s *LoyaltyService) Redemption(customer *entity.Customer, points int64, subtotal int64) (int64, model.TxHook, error) {
+	if s.PointValue <= 0 {
+		return 0, nil, fiber.NewError(fiber.StatusConflict, "points can not be redeemed")
+	}
+
+	discount := points * s.PointValue
+	limit := subtotal * s.MaxRedeemPercent / 100
+	if discount > limit {
+		return 0, nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("at most %d points can be redeemed on this order", limit/s.PointValue))
+	}
+
+	hook := func(ctx context.Context, tx *sqlx.Tx, order *entity.Order) error {
+		return s.Customers.RedeemTx(ctx, tx, customer.ID, order.ID, points)
+	}
+
+	return discount, hook, nil
+}
+
+// Accrue credits the points of a completed order. Fees do not earn points.
+// Crediting an order twice is a no-op.
+func (s *LoyaltyService) Accrue(ctx context.Context, order *entity.Order) error {
+	spend := order.Total - order.Fees
+	if order.CustomerID == nil || spend <= 0 {
+		return nil
+	}
+
+	customer, err := s.Customers.FindById(ctx, *order.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	tier := s.tierFor(customer.LifetimeSpend)
+	points := int64(math.Floor(float64(spend/s.RupiahPerPoint) * tier.Multiplier))
+	newTier := s.tierFor(customer.LifetimeSpend + spend)
+
+	entry := &entity.LoyaltyEntry{
+		CustomerID: customer.ID,
+		OrderID:    &order.ID,
+		Type:       entity.LoyaltyEarn,
+		Points:     points,
+		ExpiresAt:  s.expiry(),
+		Note:       order.OrderNumber,
+	}
+
+	err = s.Customers.Credit(ctx, entry, spend, newTier.Name)
+	if isConflict(err) {
+		return nil
+	}
+
+	return err
+}
+
+// Refund gives back points redeemed on an order that was cancelled.
+func (s *LoyaltyService) Refund(ctx context.Context, order *entity.Order) error {
+	if order.CustomerID == nil || order.PointsRedeemed <= 0 {
+		return nil
+	}
+
+	entry := &entity.LoyaltyEntry{
+		CustomerID: *order.CustomerID,
+		OrderID:    &order.ID,
+		Type:       entity.LoyaltyRefund,
+		Points:     order.PointsRedeemed,
+		ExpiresAt:  s.expiry(),
+		Note:       order.OrderNumber + " cancelled",
+	}
+
+	return s.Customers.Credit(ctx, entry, 0, "")
+}
+
+// Describe adds tier progress and the worth of the balance to a customer.
+func (s *LoyaltyService) Describe(customer *entity.Customer) *model.CustomerResponse {
+	response := &model.CustomerResponse{
+		Customer:      customer,
+		PointValue:    s.PointValue,
+		RedeemableIDR: customer.PointsBalance * s.PointValue,
+	}
+
+	for _, tier := range s.Tiers {
+		if tier.MinSpend > customer.LifetimeSpend {
+			response.NextTier = tier.Name
+			response.SpendToNext = tier.MinSpend - customer.LifetimeSpend
+			break
+		}
+	}
+
+	return response
+}
+
+func (s *LoyaltyService) tierFor(spend int64) loyaltyTier {
+	tier := s.Tiers[0]
+	for _, candidate := range s.Tiers {
+		if spend >= candidate.MinSpend {
+			tier = candidate
+		}
+	}
+	return tier
+}
+
+func (s *LoyaltyService) expiry() *time.Time {
+	if s.ExpiryMonths <= 0 {
+		return nil
+	}
+	expiresAt := time.Now().AddDate(0, s.ExpiryMonths, 0)
+	return &expiresAt
+}
+
+func isConflict(err error) bool {
+	var fiberErr *fiber.Error
+	return errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusConflict
+}
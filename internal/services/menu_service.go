@@ -0,0 +1,465 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// MenuService builds a store's effective menu: store prices, the store's
+// category tree and only what can be ordered at a given time.
+type MenuService struct {
+	Menu       model.MenuRepository
+	Stores     model.StoreRepository
+	Categories model.CategoryRepository
+	Hours      model.StoreHoursService
+	Events     model.EventBus
+	Audit      model.AuditService
+	Validate   *validator.Validate
+	Log        *logrus.Logger
+}
+
+func NewMenuService(menu model.MenuRepository, stores model.StoreRepository, categories model.CategoryRepository, hours model.StoreHoursService, events model.EventBus, audit model.AuditService, validate *validator.Validate, log *logrus.Logger) model.MenuService {
+	return &MenuService{
+		Menu:       menu,
+		Stores:     stores,
+		Categories: categories,
+		Hours:      hours,
+		Events:     events,
+		Audit:      audit,
+		Validate:   validate,
+		Log:        log,
+	}
+}
+
+func (s *MenuService) ForStore(ctx context.Context, auth *model.Auth, storeID int, at time.Time, filter *model.MenuFilter) (*model.StoreMenuResponse, error) {
+	if err := s.checkFilter(filter); err != nil {
+		return nil, err
+	}
+
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := s.Stores.FindById(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.build(ctx, store, at, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StoreMenuResponse{Store: store, Categories: categories}, nil
+}
+
+// BySlug is the public menu behind the table QR codes. Inactive stores look
+// the same as unknown ones.
+func (s *MenuService) BySlug(ctx context.Context, slug string, at time.Time, filter *model.MenuFilter) (*model.StoreMenuResponse, error) {
+	if err := s.checkFilter(filter); err != nil {
+		return nil, err
+	}
+
+	store, err := s.Stores.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !store.IsActive {
+		return nil, fiber.ErrNotFound
+	}
+
+	categories, err := s.build(ctx, store, at, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StoreMenuResponse{Store: store, Categories: categories}, nil
+}
+
+// build lists what the store sells at the given time, at today's prices:
+// the ones an order placed now pays.
+func (s *MenuService) build(ctx context.Context, store *entity.Store, at time.Time, filter *model.MenuFilter) ([]model.MenuCategory, error) {
+	all, err := s.Menu.FindStoreMenu(ctx, store.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := s.Availability(ctx, store, at)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]model.MenuEntry, 0, len(all))
+	ids := make([]int, 0, len(all))
+	for _, entry := range all {
+		if available(entry.MenuItemID, entry.CategoryID) && filterMatches(filter, entry) {
+			entries = append(entries, entry)
+			ids = append(ids, entry.MenuItemID)
+		}
+	}
+
+	options, err := s.Menu.FindItemOptions(ctx, store.ID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// Options come sorted by item, group and option, so groups can be built
+	// by appending.
+	groups := map[int][]model.MenuOptionGroup{}
+	for _, option := range options {
+		itemGroups := groups[option.MenuItemID]
+		if len(itemGroups) == 0 || itemGroups[len(itemGroups)-1].ID != option.GroupID {
+			itemGroups = append(itemGroups, model.MenuOptionGroup{
+				ID:         option.GroupID,
+				Name:       option.GroupName,
+				IsRequired: option.IsRequired,
+				Options:    []model.MenuOption{},
+			})
+		}
+		if option.OptionID != 0 {
+			last := &itemGroups[len(itemGroups)-1]
+			last.Options = append(last.Options, model.MenuOption{
+				ID:              option.OptionID,
+				Label:           option.Label,
+				AdditionalPrice: option.AdditionalPrice,
+			})
+		}
+		groups[option.MenuItemID] = itemGroups
+	}
+
+	items := map[int][]model.MenuEntry{}
+	for _, entry := range entries {
+		entry.Groups = groups[entry.MenuItemID]
+		if entry.Groups == nil {
+			entry.Groups = []model.MenuOptionGroup{}
+		}
+		items[entry.CategoryID] = append(items[entry.CategoryID], entry)
+	}
+
+	categories, err := s.Categories.FindStoreCategories(ctx, store.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return menuTree(categories, items), nil
+}
+
+// menuTree nests the store's categories under their parents, in the store's
+// order. A hidden or inactive category hides everything under it, and
+// categories with nothing to sell, not even further down, are left out.
+func menuTree(categories []model.StoreCategoryDetail, items map[int][]model.MenuEntry) []model.MenuCategory {
+	listed := map[int]bool{}
+	for _, category := range categories {
+		listed[category.ID] = true
+	}
+
+	roots := []model.StoreCategoryDetail{}
+	children := map[int][]model.StoreCategoryDetail{}
+	for _, category := range categories {
+		switch {
+		case category.ParentID == nil:
+			roots = append(roots, category)
+		case listed[*category.ParentID]:
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var nest func(level []model.StoreCategoryDetail) []model.MenuCategory
+	nest = func(level []model.StoreCategoryDetail) []model.MenuCategory {
+		nodes := []model.MenuCategory{}
+		for _, category := range level {
+			if !category.IsVisible {
+				continue
+			}
+
+			node := model.MenuCategory{
+				ID:       category.ID,
+				Name:     category.Name,
+				Icon:     category.Icon,
+				Color:    category.Color,
+				Items:    items[category.ID],
+				Children: nest(children[category.ID]),
+			}
+			if category.StoreName != "" {
+				node.Name = category.StoreName
+			}
+			if len(node.Items) == 0 && len(node.Children) == 0 {
+				continue
+			}
+			if node.Items == nil {
+				node.Items = []model.MenuEntry{}
+			}
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	return nest(roots)
+}
+
+// UpdateTags replaces the tags and allergens of a menu item everywhere it is
+// sold.
+func (s *MenuService) UpdateTags(ctx context.Context, auth *model.Auth, menuItemID int, request *model.UpdateMenuItemTagsRequest) (*entity.MenuItem, error) {
+	request.Tags = labels(request.Tags)
+	request.Allergens = labels(request.Allergens)
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid menu item tags", apperrors.GetValidateMessage(err))
+	}
+
+	item, err := s.Menu.FindItem(ctx, menuItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Menu.UpdateTags(ctx, item.ID, request.Tags, request.Allergens); err != nil {
+		return nil, err
+	}
+
+	before := *item
+	item.Tags = request.Tags
+	item.Allergens = request.Allergens
+	s.Audit.Record(ctx, auth, 0, "menu_item", item.ID, &before, item)
+	if err := s.Events.Publish(ctx, 0, &model.MenuItemChanged{MenuItemID: item.ID, CategoryID: item.CategoryID, Reason: "tags"}); err != nil {
+		s.Log.Warnf("failed to publish tag change of menu item %d: %v", item.ID, err)
+	}
+
+	return item, nil
+}
+
+// checkFilter tidies up the values of a menu filter and checks them.
+func (s *MenuService) checkFilter(filter *model.MenuFilter) error {
+	filter.Tags = labels(filter.Tags)
+	filter.ExcludeAllergens = labels(filter.ExcludeAllergens)
+	if err := s.Validate.Struct(filter); err != nil {
+		return apperrors.NewBadRequest("invalid menu filter", apperrors.GetValidateMessage(err))
+	}
+
+	return nil
+}
+
+// filterMatches tells whether a menu entry has every tag the filter asks
+// for and none of the allergens it excludes.
+func filterMatches(filter *model.MenuFilter, entry model.MenuEntry) bool {
+	for _, tag := range filter.Tags {
+		if !slices.Contains(entry.Tags, tag) {
+			return false
+		}
+	}
+	for _, allergen := range filter.ExcludeAllergens {
+		if slices.Contains(entry.Allergens, allergen) {
+			return false
+		}
+	}
+	return true
+}
+
+// labels splits comma separated tags or allergens and lowercases them,
+// dropping blanks and repeats.
+func labels(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		for _, label := range strings.Split(value, ",") {
+			label = strings.ToLower(strings.TrimSpace(label))
+			if label != "" && !slices.Contains(result, label) {
+				result = append(result, label)
+			}
+		}
+	}
+	return result
+}
+
+// Availability resolves the store's availability windows at one moment in the
+// store's timezone. An item is sold when its own windows, its category's
+// windows and those of every category above it each have one that matches,
+// or none at all.
+func (s *MenuService) Availability(ctx context.Context, store *entity.Store, at time.Time) (model.AvailabilityCheck, error) {
+	windows, err := s.Menu.FindWindows(ctx, store.ID)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := s.Categories.FindStoreCategories(ctx, store.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	local := at.In(s.Hours.Location(store))
+	items := map[int]bool{}
+	categories := map[int]bool{}
+	for _, window := range windows {
+		open := windowMatches(window, local)
+		switch {
+		case window.MenuItemID != nil:
+			items[*window.MenuItemID] = items[*window.MenuItemID] || open
+		case window.CategoryID != nil:
+			categories[*window.CategoryID] = categories[*window.CategoryID] || open
+		}
+	}
+
+	parents := map[int]*int{}
+	for _, category := range tree {
+		parents[category.ID] = category.ParentID
+	}
+
+	return func(menuItemID, categoryID int) bool {
+		if open, limited := items[menuItemID]; limited && !open {
+			return false
+		}
+		// The walk is bounded in case the tree ever has a cycle.
+		ancestor := &categoryID
+		for depth := 0; ancestor != nil && depth <= len(parents); depth++ {
+			if open, limited := categories[*ancestor]; limited && !open {
+				return false
+			}
+			ancestor = parents[*ancestor]
+		}
+		return true
+	}, nil
+}
+
+func (s *MenuService) Windows(ctx context.Context, auth *model.Auth, storeID int) ([]entity.MenuAvailabilityWindow, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	windows, err := s.Menu.FindWindows(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range windows {
+		windows[i].Weekdays = weekdaysOf(windows[i].WeekdayMask)
+	}
+
+	return windows, nil
+}
+
+func (s *MenuService) AddWindow(ctx context.Context, auth *model.Auth, storeID int, request *model.CreateAvailabilityWindowRequest) (*entity.MenuAvailabilityWindow, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid availability window", apperrors.GetValidateMessage(err))
+	}
+	if request.StartsAt != "" {
+		if err := checkOpeningTimes(request.StartsAt, request.EndsAt); err != nil {
+			return nil, err
+		}
+	}
+	if request.ValidFrom != "" && request.ValidUntil != "" && request.ValidUntil < request.ValidFrom {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "valid_until is before valid_from")
+	}
+
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	window := &entity.MenuAvailabilityWindow{
+		StoreID:    storeID,
+		StartsAt:   request.StartsAt,
+		EndsAt:     request.EndsAt,
+		ValidFrom:  request.ValidFrom,
+		ValidUntil: request.ValidUntil,
+	}
+	if request.MenuItemID != 0 {
+		window.MenuItemID = &request.MenuItemID
+	} else {
+		window.CategoryID = &request.CategoryID
+	}
+	for _, weekday := range request.Weekdays {
+		window.WeekdayMask |= 1 << weekday
+	}
+	window.Weekdays = weekdaysOf(window.WeekdayMask)
+
+	if err := s.Menu.StoreWindow(ctx, window); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, storeID, "availability_window", window.ID, nil, window)
+	s.windowChanged(ctx, window)
+
+	return window, nil
+}
+
+func (s *MenuService) RemoveWindow(ctx context.Context, auth *model.Auth, storeID int, id int) error {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return err
+	}
+
+	window, err := s.Menu.FindWindow(ctx, id)
+	if err != nil {
+		return err
+	}
+	if window.StoreID != storeID {
+		return fiber.ErrNotFound
+	}
+
+	if err := s.Menu.RemoveWindow(ctx, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, auth, storeID, "availability_window", window.ID, window, nil)
+	s.windowChanged(ctx, window)
+
+	return nil
+}
+
+// windowChanged tells the rest of the app that what the window covers may
+// have come on or gone off the menu.
+func (s *MenuService) windowChanged(ctx context.Context, window *entity.MenuAvailabilityWindow) {
+	event := &model.MenuItemChanged{Reason: "availability"}
+	if window.MenuItemID != nil {
+		event.MenuItemID = *window.MenuItemID
+	}
+	if window.CategoryID != nil {
+		event.CategoryID = *window.CategoryID
+	}
+
+	if err := s.Events.Publish(ctx, window.StoreID, event); err != nil {
+		s.Log.Warnf("failed to publish change of availability window %d: %v", window.ID, err)
+	}
+}
+
+// windowMatches checks a window against a store-local time. Times of day are
+// half open, dates are inclusive.
+func windowMatches(window entity.MenuAvailabilityWindow, local time.Time) bool {
+	if window.WeekdayMask != 0 && window.WeekdayMask&(1<<int(local.Weekday())) == 0 {
+		return false
+	}
+
+	date := local.Format(time.DateOnly)
+	if window.ValidFrom != "" && date < window.ValidFrom {
+		return false
+	}
+	if window.ValidUntil != "" && date > window.ValidUntil {
+		return false
+	}
+
+	if window.StartsAt == "" {
+		return true
+	}
+	starts, startErr := clockMinutes(window.StartsAt)
+	ends, endErr := clockMinutes(window.EndsAt)
+	if startErr != nil || endErr != nil {
+		return false
+	}
+	minute := local.Hour()*60 + local.Minute()
+
+	return minute >= starts && minute < ends
+}
+
+func weekdaysOf(mask int) []int {
+	weekdays := []int{}
+	for weekday := 0; weekday < 7; weekday++ {
+		if mask&(1<<weekday) != 0 {
+			weekdays = append(weekdays, weekday)
+		}
+	}
+	return weekdays
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// priceItems turns requested lines into order items priced for the store.
+// The unit price is the store price of the menu item plus the surcharge of
+// every picked option, at the price in effect when the order is placed even
+// for a later pickup. Items must be on the menu at the given time.
+func (s *OrderService) priceItems(ctx context.Context, storeID int, at time.Time, lines []model.PlaceOrderItem) ([]entity.OrderItem, int64, error) {
+	store, err := s.Stores.FindById(ctx, storeID)
+	if err != nil {
+		return nil, 0, err
+	}
+	onMenu, err := s.Menus.Availability(ctx, store, at)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]int, 0, len(lines))
+	for _, line := range lines {
+		ids = append(ids, line.MenuItemID)
+	}
+
+	menu, err := s.Menu.FindStoreItems(ctx, storeID, ids, time.Now())
+	if err != nil {
+		return nil, 0, err
+	}
+	byItem := map[int]model.StoreMenuItem{}
+	for _, item := range menu {
+		byItem[item.MenuItemID] = item
+	}
+
+	options, err := s.Menu.FindItemOptions(ctx, storeID, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	itemOptions := map[int]map[int]model.MenuItemOption{}
+	requiredGroups := map[int]map[int]string{}
+	for _, option := range options {
+		if option.IsRequired {
+			if requiredGroups[option.MenuItemID] == nil {
+				requiredGroups[option.MenuItemID] = map[int]string{}
+			}
+			requiredGroups[option.MenuItemID][option.GroupID] = option.GroupName
+		}
+		if option.OptionID == 0 {
+			continue
+		}
+		if itemOptions[option.MenuItemID] == nil {
+			itemOptions[option.MenuItemID] = map[int]model.MenuItemOption{}
+		}
+		itemOptions[option.MenuItemID][option.OptionID] = option
+	}
+
+	items := make([]entity.OrderItem, 0, len(lines))
+	var subtotal int64
+	for _, line := range lines {
+		menuItem, ok := byItem[line.MenuItemID]
+		if !ok {
+			return nil, 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("menu item %d is not sold at this store", line.MenuItemID))
+		}
+		if !menuItem.IsAvailable {
+			return nil, 0, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("%s is not available", menuItem.Name))
+		}
+		if !onMenu(menuItem.MenuItemID, menuItem.CategoryID) {
+			return nil, 0, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("%s is not on the menu at %s", menuItem.Name, at.In(s.Hours.Location(store)).Format("Mon 15:04")))
+		}
+
+		unitPrice := menuItem.Price
+		picked := map[int]bool{}
+		chosenGroups := map[int]bool{}
+		customizations := make([]entity.OrderItemCustomization, 0, len(line.OptionIDs))
+		for _, optionID := range line.OptionIDs {
+			option, ok := itemOptions[line.MenuItemID][optionID]
+			if !ok || picked[optionID] {
+				return nil, 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("option %d can not be added to %s", optionID, menuItem.Name))
+			}
+			picked[optionID] = true
+			chosenGroups[option.GroupID] = true
+
+			unitPrice += option.AdditionalPrice
+			customizations = append(customizations, entity.OrderItemCustomization{
+				GroupID:         option.GroupID,
+				OptionID:        option.OptionID,
+				Label:           option.Label,
+				AdditionalPrice: option.AdditionalPrice,
+			})
+		}
+
+		for groupID, name := range requiredGroups[line.MenuItemID] {
+			if !chosenGroups[groupID] {
+				return nil, 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s needs a choice of %s", menuItem.Name, name))
+			}
+		}
+
+		items = append(items, entity.OrderItem{
+			MenuItemID:     line.MenuItemID,
+			Quantity:       line.Quantity,
+			UnitPrice:      unitPrice,
+			Customizations: customizations,
+			Note:           line.Note,
+		})
+		subtotal += unitPrice * int64(line.Quantity)
+	}
+
+	return items, subtotal, nil
+}
+
+// rewardItems adds the free items paid for with stamp cards. They are priced
+// like any other line to check availability and options, then zeroed.
+func (s *OrderService) rewardItems(ctx context.Context, storeID int, at time.Time, customerID int, rewards []model.PlaceOrderReward) ([]entity.OrderItem, []model.TxHook, error) {
+	if len(rewards) == 0 {
+		return nil, nil, nil
+	}
+
+	lines := make([]model.PlaceOrderItem, 0, len(rewards))
+	hooks := make([]model.TxHook, 0, len(rewards))
+	for _, reward := range rewards {
+		hook, err := s.Stamps.Redemption(ctx, customerID, reward)
+		if err != nil {
+			return nil, nil, err
+		}
+		hooks = append(hooks, hook)
+		lines = append(lines, model.PlaceOrderItem{
+			MenuItemID: reward.MenuItemID,
+			Quantity:   1,
+			OptionIDs:  reward.OptionIDs,
+			Note:       reward.Note,
+		})
+	}
+
+	items, _, err := s.priceItems(ctx, storeID, at, lines)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range items {
+		items[i].UnitPrice = 0
+		items[i].StampProgramID = &rewards[i].ProgramID
+	}
+
+	return items, hooks, nil
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// orderTransitions lists the statuses an order may move to from each status.
+var orderTransitions = map[string][]string{
+	entity.OrderScheduled: {entity.OrderPending, entity.OrderCancelled},
+	entity.OrderPending:   {entity.OrderPreparing, entity.OrderCancelled},
+	entity.OrderPreparing: {entity.OrderReady, entity.OrderCancelled},
+	entity.OrderReady:     {entity.OrderCompleted, entity.OrderCancelled},
+}
+
+type OrderService struct {
+	Repo       model.OrderRepository
+	Menu       model.MenuRepository
+	Menus      model.MenuService
+	Stores     model.StoreRepository
+	Tickets    model.StationRepository
+	Hours      model.StoreHoursService
+	Pickup     model.PickupService
+	Loyalty    model.LoyaltyService
+	Stamps     model.StampService
+	Projector  model.ProjectionService
+	Outbox     model.OutboxRepository
+	Validate   *validator.Validate
+	Log        *logrus.Logger
+	OrderTypes map[string]orderTypeRule
+}
+
+func NewOrderService(repo model.OrderRepository, menu model.MenuRepository, menus model.MenuService, stores model.StoreRepository, tickets model.StationRepository, hours model.StoreHoursService, pickup model.PickupService, loyalty model.LoyaltyService, stamps model.StampService, projector model.ProjectionService, outbox model.OutboxRepository, validate *validator.Validate, viper *viper.Viper, log *logrus.Logger) model.OrderService {
+	return &OrderService{
+		Repo:       repo,
+		Menu:       menu,
+		Menus:      menus,
+		Stores:     stores,
+		Tickets:    tickets,
+		Hours:      hours,
+		Pickup:     pickup,
+		Loyalty:    loyalty,
+		Stamps:     stamps,
+		Projector:  projector,
+		Outbox:     outbox,
+		Validate:   validate,
+		Log:        log,
+		OrderTypes: loadOrderTypeRules(viper, log),
+	}
+}
+
+func (s *OrderService) Place(ctx context.Context, auth *model.Auth, request *model.PlaceOrderRequest) (*model.OrderResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid order", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(request.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.place(ctx, &entity.Order{StoreID: storeID}, request)
+}
+
+// PlaceGuest places an order sent from a table without a login. Nobody has
+// proven they own the phone number, so points and stamps can be earned but
+// not spent.
+func (s *OrderService) PlaceGuest(ctx context.Context, storeID int, tableNumber string, request *model.PlaceOrderRequest) (*model.OrderResponse, error) {
+	request.OrderType = entity.OrderDineIn
+	request.TableNumber = tableNumber
+
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid order", apperrors.GetValidateMessage(err))
+	}
+	if request.RedeemPoints > 0 || len(request.Rewards) > 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "rewards can only be redeemed at the counter")
+	}
+
+	return s.place(ctx, &entity.Order{StoreID: storeID}, request)
+}
+
+// Quote prices items the way Place would, without storing anything.
+func (s *OrderService) Quote(ctx context.Context, storeID int, lines []model.PlaceOrderItem) ([]entity.OrderItem, int64, error) {
+	return s.priceItems(ctx, storeID, time.Now(), lines)
+}
+
+func (s *OrderService) place(ctx context.Context, order *entity.Order, request *model.PlaceOrderRequest) (*model.OrderResponse, error) {
+	storeID := order.StoreID
+
+	// Pickup orders are made for the pickup time, so that is when the items
+	// have to be on the menu.
+	at := time.Now()
+	if request.OrderType == entity.OrderPickup && request.PickupAt != nil {
+		at = *request.PickupAt
+	}
+
+	items, subtotal, err := s.priceItems(ctx, storeID, at, request.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Status = entity.OrderPending
+	order.Subtotal = subtotal
+	order.CustomerName = request.CustomerName
+	order.CustomerNote = request.CustomerNote
+	hooks := []model.TxHook{}
+
+	if request.CustomerPhone != "" {
+		customer, err := s.Loyalty.Identify(ctx, request.CustomerPhone, request.CustomerName)
+		if err != nil {
+			return nil, err
+		}
+		order.CustomerID = &customer.ID
+		if order.CustomerName == "" {
+			order.CustomerName = customer.FullName
+		}
+
+		if request.RedeemPoints > 0 {
+			discount, hook, err := s.Loyalty.Redemption(customer, request.RedeemPoints, subtotal)
+			if err != nil {
+				return nil, err
+			}
+			order.Discount = discount
+			order.PointsRedeemed = request.RedeemPoints
+			hooks = append(hooks, hook)
+		}
+
+		rewards, rewardHooks, err := s.rewardItems(ctx, storeID, at, *order.CustomerID, request.Rewards)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, rewards...)
+		hooks = append(hooks, rewardHooks...)
+	} else if request.RedeemPoints > 0 || len(request.Rewards) > 0 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "redeeming rewards needs the customer's phone number")
+	}
+
+	reserve, err := s.applyOrderType(ctx, order, request, items)
+	if err != nil {
+		return nil, err
+	}
+	if reserve != nil {
+		hooks = append(hooks, reserve)
+	}
+	order.Total = order.Subtotal - order.Discount + order.Fees
+	hooks = append(hooks, s.Tickets.RouteTx, outboxHook(s.Outbox, func(order *entity.Order) model.DomainEvent {
+		return &model.OrderPlaced{Order: order, Items: items}
+	}))
+
+	if err := s.Repo.Store(ctx, order, items, hooks...); err != nil {
+		return nil, err
+	}
+
+	return &model.OrderResponse{Order: order, Items: items}, nil
+}
+
+func (s *OrderService) UpdateStatus(ctx context.Context, auth *model.Auth, id int, request *model.UpdateOrderStatusRequest) (*entity.Order, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid order status", apperrors.GetValidateMessage(err))
+	}
+
+	order, err := s.Repo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := auth.ScopeStore(order.StoreID); err != nil {
+		return nil, err
+	}
+
+	if request.Status == entity.OrderReady {
+		open, err := s.Tickets.CountOpenTickets(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		if open > 0 {
+			return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("order still has %d open tickets", open))
+		}
+	}
+
+	if err := s.transition(ctx, order, request.Status, auth.UserID()); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// BumpTicket marks one station's part of an order done. The first bump starts
+// a pending order and the last one makes it ready.
+func (s *OrderService) BumpTicket(ctx context.Context, auth *model.Auth, ticketID int) (*model.BumpTicketResponse, error) {
+	ticket, err := s.Tickets.FindTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.Repo.FindById(ctx, ticket.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := auth.ScopeStore(order.StoreID); err != nil {
+		return nil, err
+	}
+
+	// The order is read again under its lock, so the status decided on here
+	// is never one a concurrent bump already moved past.
+	advance := func(ctx context.Context, tx *sqlx.Tx, order *entity.Order, open int) error {
+		if order.Status != entity.OrderPending && order.Status != entity.OrderPreparing {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("tickets of a %s order can not be bumped", order.Status))
+		}
+		if order.Status == entity.OrderPending {
+			if err := s.transitionTx(ctx, tx, order, entity.OrderPreparing, auth.UserID()); err != nil {
+				return err
+			}
+		}
+		if open == 0 {
+			return s.transitionTx(ctx, tx, order, entity.OrderReady, auth.UserID())
+		}
+		return nil
+	}
+
+	if _, err := s.Tickets.Bump(ctx, ticket, order, auth.UserID(), advance); err != nil {
+		return nil, err
+	}
+
+	return &model.BumpTicketResponse{Ticket: ticket, Order: order}, nil
+}
+
+// transition moves an order to its next status and runs what follows from
+// the new status.
+func (s *OrderService) transition(ctx context.Context, order *entity.Order, status string, userID int) error {
+	changed, err := s.statusChange(order, status)
+	if err != nil {
+		return err
+	}
+	if err := s.Repo.UpdateStatus(ctx, order, status, userID, changed); err != nil {
+		return err
+	}
+
+	// The status change is committed; follow-up failures are logged instead
+	// of failing the request. Projections are caught up by a rebuild.
+	switch order.Status {
+	case entity.OrderCompleted:
+		if err := s.Loyalty.Accrue(ctx, order); err != nil {
+			s.Log.Warnf("failed to credit points for order %d: %v", order.ID, err)
+		}
+		if err := s.Stamps.Earn(ctx, order); err != nil {
+			s.Log.Warnf("failed to credit stamps for order %d: %v", order.ID, err)
+		}
+		if err := s.Projector.Project(ctx, order.ID); err != nil {
+			s.Log.Warnf("failed to project order %d: %v", order.ID, err)
+		}
+	case entity.OrderCancelled:
+		if err := s.Loyalty.Refund(ctx, order); err != nil {
+			s.Log.Warnf("failed to refund points for order %d: %v", order.ID, err)
+		}
+		if err := s.Stamps.Restore(ctx, order); err != nil {
+			s.Log.Warnf("failed to restore stamps for order %d: %v", order.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// transitionTx moves an order to its next status inside tx. It is for the
+// statuses that have nothing to follow up once committed.
+func (s *OrderService) transitionTx(ctx context.Context, tx *sqlx.Tx, order *entity.Order, status string, userID int) error {
+	changed, err := s.statusChange(order, status)
+	if err != nil {
+		return err
+	}
+	return s.Repo.UpdateStatusTx(ctx, tx, order, status, userID, changed)
+}
+
+// statusChange checks that the order may go to status and returns the hook
+// that records the change in the outbox.
+func (s *OrderService) statusChange(order *entity.Order, status string) (model.TxHook, error) {
+	if !slices.Contains(orderTransitions[order.Status], status) {
+		return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("order can not go from %s to %s", order.Status, status))
+	}
+
+	from := order.Status
+	return outboxHook(s.Outbox, func(order *entity.Order) model.DomainEvent {
+		return &model.OrderStatusChanged{Order: order, From: from, To: status}
+	}), nil
+}
+
+// Queue lists the open orders of a store, oldest first, optionally of one
+// order type. Pickup orders line up by their pickup time.
+func (s *OrderService) Queue(ctx context.Context, auth *model.Auth, request *model.OrderQueueRequest) ([]model.QueueOrder, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid queue filter", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(request.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.Repo.FindQueue(ctx, storeID, request.Type)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return []model.QueueOrder{}, nil
+	}
+
+	ids := make([]int, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+	items, err := s.Repo.FindItemsByOrders(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byOrder := map[int][]model.OrderItemDetail{}
+	for _, item := range items {
+		byOrder[item.OrderID] = append(byOrder[item.OrderID], item)
+	}
+
+	queue := make([]model.QueueOrder, 0, len(orders))
+	for i := range orders {
+		queue = append(queue, model.QueueOrder{Order: &orders[i], Items: byOrder[orders[i].ID]})
+	}
+
+	return queue, nil
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// orderTypeRule is the config of one order type under order_types. Types
+// are taken unless disabled.
+type orderTypeRule struct {
+	Disabled       bool  `mapstructure:"disabled"`
+	FeePerOrder    int64 `mapstructure:"fee_per_order"`
+	FeePerItem     int64 `mapstructure:"fee_per_item"` // packaging, per cup
+	MinLeadMinutes int   `mapstructure:"min_lead_minutes"`
+	MaxDaysAhead   int   `mapstructure:"max_days_ahead"`
+}
+
+func loadOrderTypeRules(viper *viper.Viper, log *logrus.Logger) map[string]orderTypeRule {
+	rules := map[string]orderTypeRule{}
+	if err := viper.UnmarshalKey("order_types", &rules); err != nil {
+		log.Warnf("invalid order_types, taking every type without fees: %v", err)
+		return map[string]orderTypeRule{}
+	}
+
+	return rules
+}
+
+// applyOrderType copies the fields of the requested type onto the order,
+// checks them and works out the type's fees. Pre-orders come back with the
+// hook that books their pickup slot.
+func (s *OrderService) applyOrderType(ctx context.Context, order *entity.Order, request *model.PlaceOrderRequest, items []entity.OrderItem) (model.TxHook, error) {
+	order.OrderType = request.OrderType
+	if order.OrderType == "" {
+		order.OrderType = entity.OrderTakeaway
+	}
+
+	rule := s.OrderTypes[order.OrderType]
+	if rule.Disabled {
+		return nil, fiber.NewError(fiber.StatusConflict, fmt.Sprintf("%s orders are not taken", order.OrderType))
+	}
+
+	// Pre-orders are checked against the hours of their pickup day instead.
+	if order.OrderType != entity.OrderPickup {
+		if err := s.checkOpen(ctx, order.StoreID); err != nil {
+			return nil, err
+		}
+	}
+
+	var reserve model.TxHook
+	switch order.OrderType {
+	case entity.OrderDineIn:
+		table, err := s.Stores.FindTable(ctx, order.StoreID, request.TableNumber)
+		if errors.Is(err, fiber.ErrNotFound) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "unknown table")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !table.IsActive {
+			return nil, fiber.NewError(fiber.StatusConflict, "this table is not taking orders")
+		}
+		order.TableNumber = table.TableNumber
+	case entity.OrderPickup:
+		earliest := time.Now().Add(time.Duration(rule.MinLeadMinutes) * time.Minute)
+		if request.PickupAt.Before(earliest) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("pickup must be at least %d minutes from now", rule.MinLeadMinutes))
+		}
+		if rule.MaxDaysAhead > 0 && request.PickupAt.After(time.Now().AddDate(0, 0, rule.MaxDaysAhead)) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("pickup can be at most %d days ahead", rule.MaxDaysAhead))
+		}
+		pickupAt := request.PickupAt.UTC()
+		order.PickupAt = &pickupAt
+
+		status, hook, err := s.Pickup.Reserve(ctx, order.StoreID, pickupAt)
+		if err != nil {
+			return nil, err
+		}
+		order.Status = status
+		reserve = hook
+	case entity.OrderDelivery:
+		order.DeliveryAddress = request.DeliveryAddress
+	}
+
+	cups := 0
+	for _, item := range items {
+		cups += item.Quantity
+	}
+	order.Fees = rule.FeePerOrder + rule.FeePerItem*int64(cups)
+
+	return reserve, nil
+}
+
+func (s *OrderService) checkOpen(ctx context.Context, storeID int) error {
+	store, err := s.Stores.FindById(ctx, storeID)
+	if err != nil {
+		return err
+	}
+
+	open, err := s.Hours.IsOpen(ctx, store, time.Now())
+	if err != nil {
+		return err
+	}
+	if !open {
+		return fiber.NewError(fiber.StatusConflict, "the store is closed")
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+)
+
+// outboxHook writes an event about the order to the outbox in the same
+// transaction as the change, so the event exists exactly when the change
+// does. event builds it once the rows it describes are written.
+func outboxHook(outbox model.OutboxRepository, event func(order *entity.Order) model.DomainEvent) model.TxHook {
+	return func(ctx context.Context, tx *sqlx.Tx, order *entity.Order) error {
+		return appendOutbox(ctx, outbox, tx, order, event(order))
+	}
+}
+
+func appendOutbox(ctx context.Context, outbox model.OutboxRepository, tx *sqlx.Tx, order *entity.Order, data model.DomainEvent) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fiber.ErrInternalServerError
+	}
+
+	return outbox.Append(ctx, tx, &entity.OutboxMessage{
+		Event:   data.EventName(),
+		StoreID: order.StoreID,
+		OrderID: order.ID,
+		Payload: payload,
+	})
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentService struct {
+	Payments model.PaymentRepository
+	Orders   model.OrderRepository
+	Shifts   model.ShiftRepository
+	Outbox   model.OutboxRepository
+	Validate *validator.Validate
+	Log      *logrus.Logger
+}
+
+func NewPaymentService(payments model.PaymentRepository, orders model.OrderRepository, shifts model.ShiftRepository, outbox model.OutboxRepository, validate *validator.Validate, log *logrus.Logger) model.PaymentService {
+	return &PaymentService{
+		Payments: payments,
+		Orders:   orders,
+		Shifts:   shifts,
+		Outbox:   outbox,
+		Validate: validate,
+		Log:      log,
+	}
+}
+
+// Capture records a payment against an order. Cash needs an open shift at the
+// order's store and is posted to that shift's drawer.
+func (s *PaymentService) Capture(ctx context.Context, auth *model.Auth, orderID int, request *model.CapturePaymentRequest) (*model.PaymentResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid payment", apperrors.GetValidateMessage(err))
+	}
+
+	order, err := s.Orders.FindById(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := auth.ScopeStore(order.StoreID); err != nil {
+		return nil, err
+	}
+
+	if order.Status == entity.OrderCancelled {
+		return nil, fiber.NewError(fiber.StatusConflict, "order is cancelled")
+	}
+
+	userID := auth.UserID()
+	payment := &entity.Payment{
+		OrderID:    order.ID,
+		Method:     request.Method,
+		Amount:     request.Amount,
+		Status:     entity.PaymentCaptured,
+		Reference:  request.Reference,
+		ReceivedBy: nullableUser(userID),
+	}
+
+	var change int64
+	if request.Method == entity.PaymentCash {
+		shift, err := s.Shifts.FindOpenByStore(ctx, order.StoreID)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusConflict, "open a shift before taking cash")
+		}
+		payment.ShiftID = &shift.ID
+
+		if request.Tendered > 0 {
+			payment.Tendered = &request.Tendered
+			change = request.Tendered - request.Amount
+		}
+	}
+
+	captured := func(ctx context.Context, tx *sqlx.Tx, payment *entity.Payment, outstanding int64) error {
+		return appendOutbox(ctx, s.Outbox, tx, order,
+			&model.PaymentCaptured{Payment: payment, Change: change, Outstanding: outstanding})
+	}
+	outstanding, err := s.Payments.Store(ctx, payment, captured)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaymentResponse{
+		Payment:     payment,
+		Change:      change,
+		Outstanding: outstanding,
+	}, nil
+}
+
+func nullableUser(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
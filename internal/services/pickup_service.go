@@ -0,0 +1,264 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// PickupService hands out pre-order pickup slots. Slots start at opening time
+// and repeat every SlotMinutes until closing; each takes a limited number of
+// orders.
+type PickupService struct {
+	Repo     model.PickupRepository
+	Orders   model.OrderRepository
+	Stores   model.StoreRepository
+	Hours    model.StoreHoursService
+	Outbox   model.OutboxRepository
+	Audit    model.AuditService
+	Validate *validator.Validate
+	Log      *logrus.Logger
+	Defaults entity.PickupSettings
+}
+
+func NewPickupService(repo model.PickupRepository, orders model.OrderRepository, stores model.StoreRepository, hours model.StoreHoursService, outbox model.OutboxRepository, audit model.AuditService, validate *validator.Validate, viper *viper.Viper, log *logrus.Logger) model.PickupService {
+	return &PickupService{
+		Repo:     repo,
+		Orders:   orders,
+		Stores:   stores,
+		Hours:    hours,
+		Outbox:   outbox,
+		Audit:    audit,
+		Validate: validate,
+		Log:      log,
+		Defaults: entity.PickupSettings{
+			SlotMinutes:     max(viper.GetInt("pickup.slot_minutes"), 5),
+			CapacityPerSlot: viper.GetInt("pickup.capacity_per_slot"),
+			ReleaseMinutes:  viper.GetInt("pickup.release_minutes"),
+		},
+	}
+}
+
+func (s *PickupService) Settings(ctx context.Context, auth *model.Auth, storeID int) (*entity.PickupSettings, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.settings(ctx, storeID)
+}
+
+func (s *PickupService) UpdateSettings(ctx context.Context, auth *model.Auth, storeID int, request *model.UpdatePickupSettingsRequest) (*entity.PickupSettings, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid pickup settings", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.settings(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &entity.PickupSettings{
+		StoreID:         storeID,
+		SlotMinutes:     request.SlotMinutes,
+		CapacityPerSlot: request.CapacityPerSlot,
+		ReleaseMinutes:  request.ReleaseMinutes,
+	}
+	if err := s.Repo.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, storeID, "pickup_settings", storeID, before, settings)
+
+	return settings, nil
+}
+
+func (s *PickupService) Slots(ctx context.Context, auth *model.Auth, storeID int, request *model.PickupSlotRequest) ([]model.PickupSlot, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := s.Stores.FindById(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.slots(ctx, store, request)
+}
+
+func (s *PickupService) PublicSlots(ctx context.Context, slug string, request *model.PickupSlotRequest) ([]model.PickupSlot, error) {
+	store, err := s.Stores.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !store.IsActive {
+		return nil, fiber.ErrNotFound
+	}
+
+	return s.slots(ctx, store, request)
+}
+
+func (s *PickupService) slots(ctx context.Context, store *entity.Store, request *model.PickupSlotRequest) ([]model.PickupSlot, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid date", apperrors.GetValidateMessage(err))
+	}
+	day, err := time.ParseInLocation(time.DateOnly, request.Date, s.Hours.Location(store))
+	if err != nil {
+		return nil, fiber.ErrBadRequest
+	}
+
+	settings, err := s.settings(ctx, store.ID)
+	if err != nil {
+		return nil, err
+	}
+	starts, err := s.dayStarts(ctx, store, day, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := []model.PickupSlot{}
+	if len(starts) == 0 {
+		return slots, nil
+	}
+
+	last := starts[len(starts)-1]
+	counts, err := s.Repo.CountBooked(ctx, store.ID, starts[0], last.Add(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	booked := map[int64]int{}
+	for _, count := range counts {
+		booked[count.PickupAt.Unix()] = count.Orders
+	}
+
+	now := time.Now()
+	for _, start := range starts {
+		taken := booked[start.Unix()]
+		slots = append(slots, model.PickupSlot{
+			StartsAt:  start,
+			Capacity:  settings.CapacityPerSlot,
+			Booked:    taken,
+			Available: taken < settings.CapacityPerSlot && start.After(now),
+		})
+	}
+
+	return slots, nil
+}
+
+func (s *PickupService) Reserve(ctx context.Context, storeID int, pickupAt time.Time) (string, model.TxHook, error) {
+	store, err := s.Stores.FindById(ctx, storeID)
+	if err != nil {
+		return "", nil, err
+	}
+	if !store.IsActive {
+		return "", nil, fiber.NewError(fiber.StatusConflict, "this store is not taking orders")
+	}
+	settings, err := s.settings(ctx, storeID)
+	if err != nil {
+		return "", nil, err
+	}
+	if settings.CapacityPerSlot == 0 {
+		return "", nil, fiber.NewError(fiber.StatusConflict, "this store does not take pre-orders")
+	}
+
+	starts, err := s.dayStarts(ctx, store, pickupAt.In(s.Hours.Location(store)), settings)
+	if err != nil {
+		return "", nil, err
+	}
+
+	valid := false
+	for _, start := range starts {
+		if start.Equal(pickupAt) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return "", nil, fiber.NewError(fiber.StatusBadRequest,
+			fmt.Sprintf("pickup must be at the start of a %d minute slot while the store is open", settings.SlotMinutes))
+	}
+
+	hook := func(ctx context.Context, tx *sqlx.Tx, order *entity.Order) error {
+		return s.Repo.ReserveTx(ctx, tx, storeID, pickupAt, settings.CapacityPerSlot)
+	}
+
+	status := entity.OrderPending
+	if time.Until(pickupAt) > time.Duration(settings.ReleaseMinutes)*time.Minute {
+		status = entity.OrderScheduled
+	}
+
+	return status, hook, nil
+}
+
+// Release sends due pre-orders to the barista queue. It runs on a timer.
+// Tickets are printed by the printer subscriber off the status change.
+func (s *PickupService) Release(ctx context.Context) error {
+	released := outboxHook(s.Outbox, func(order *entity.Order) model.DomainEvent {
+		return &model.OrderStatusChanged{Order: order, From: entity.OrderScheduled, To: entity.OrderPending}
+	})
+	ids, err := s.Orders.ReleaseScheduled(ctx, s.Defaults.ReleaseMinutes, released)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		s.Log.Infof("released %d scheduled orders: %v", len(ids), ids)
+	}
+
+	return nil
+}
+
+func (s *PickupService) settings(ctx context.Context, storeID int) (*entity.PickupSettings, error) {
+	settings, err := s.Repo.FindSettings(ctx, storeID)
+	if errors.Is(err, fiber.ErrNotFound) {
+		defaults := s.Defaults
+		defaults.StoreID = storeID
+		return &defaults, nil
+	}
+
+	return settings, err
+}
+
+// dayStarts lists the slot start times of one store-local day, closures
+// applied. It is empty when the store is closed that day.
+func (s *PickupService) dayStarts(ctx context.Context, store *entity.Store, day time.Time, settings *entity.PickupSettings) ([]time.Time, error) {
+	hours, err := s.Hours.DayHours(ctx, store, day)
+	if err != nil {
+		return nil, err
+	}
+
+	starts := []time.Time{}
+	if hours.Closed {
+		return starts, nil
+	}
+
+	opens, err := clockMinutes(hours.OpensAt)
+	if err != nil {
+		return nil, err
+	}
+	closes, err := clockMinutes(hours.ClosesAt)
+	if err != nil {
+		return nil, err
+	}
+
+	location := day.Location()
+	for minute := opens; minute < closes; minute += settings.SlotMinutes {
+		starts = append(starts, time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, location))
+	}
+
+	return starts, nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// PriceService keeps the price history of menu items. Prices never change in
+// place: every change is a new entry from some moment on, so what an item
+// cost at any past time can still be told.
+type PriceService struct {
+	Menu     model.MenuRepository
+	Events   model.EventBus
+	Audit    model.AuditService
+	Validate *validator.Validate
+	Log      *logrus.Logger
+}
+
+func NewPriceService(menu model.MenuRepository, events model.EventBus, audit model.AuditService, validate *validator.Validate, log *logrus.Logger) model.PriceService {
+	return &PriceService{
+		Menu:     menu,
+		Events:   events,
+		Audit:    audit,
+		Validate: validate,
+		Log:      log,
+	}
+}
+
+func (s *PriceService) History(ctx context.Context, auth *model.Auth, menuItemID int, request *model.PriceHistoryRequest) ([]entity.MenuItemPrice, error) {
+	storeID := request.StoreID
+	if storeID != 0 || auth.Role != entity.RoleAdmin {
+		scoped, err := auth.ScopeStore(storeID)
+		if err != nil {
+			return nil, err
+		}
+		storeID = scoped
+	}
+
+	return s.Menu.FindPrices(ctx, menuItemID, storeID)
+}
+
+// Schedule adds a price to the history. A price that starts now is applied
+// right away; later ones wait for ApplyDue.
+func (s *PriceService) Schedule(ctx context.Context, auth *model.Auth, menuItemID int, request *model.SchedulePriceRequest) (*entity.MenuItemPrice, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid price", apperrors.GetValidateMessage(err))
+	}
+
+	now := time.Now()
+	price := &entity.MenuItemPrice{
+		MenuItemID:    menuItemID,
+		Price:         request.Price,
+		EffectiveFrom: now,
+		CreatedBy:     nullableUser(auth.UserID()),
+	}
+	if request.EffectiveFrom != "" {
+		from, err := time.Parse(time.RFC3339, request.EffectiveFrom)
+		if err != nil {
+			return nil, fiber.ErrBadRequest
+		}
+		if from.Before(now) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "effective_from is in the past")
+		}
+		price.EffectiveFrom = from
+	}
+
+	if request.StoreID != 0 || auth.Role != entity.RoleAdmin {
+		scoped, err := auth.ScopeStore(request.StoreID)
+		if err != nil {
+			return nil, err
+		}
+		price.StoreID = &scoped
+	}
+	if price.StoreID == nil && price.Price == nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "the base price cannot be empty")
+	}
+
+	if err := s.Menu.StorePrice(ctx, price); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, priceStore(price), "menu_item_price", price.ID, nil, price)
+
+	if !price.EffectiveFrom.After(now) {
+		if err := s.ApplyDue(ctx); err != nil {
+			s.Log.Warnf("failed to apply price %d: %v", price.ID, err)
+		}
+	}
+
+	return price, nil
+}
+
+// Cancel drops a scheduled price before it starts.
+func (s *PriceService) Cancel(ctx context.Context, auth *model.Auth, menuItemID int, id int) error {
+	price, err := s.Menu.FindPrice(ctx, id)
+	if err != nil {
+		return err
+	}
+	if price.MenuItemID != menuItemID {
+		return fiber.ErrNotFound
+	}
+
+	switch {
+	case price.StoreID == nil:
+		if auth.Role != entity.RoleAdmin {
+			return fiber.ErrNotFound
+		}
+	default:
+		if _, err := auth.ScopeStore(*price.StoreID); err != nil {
+			return fiber.ErrNotFound
+		}
+	}
+
+	if err := s.Menu.RemovePrice(ctx, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, auth, priceStore(price), "menu_item_price", price.ID, price, nil)
+
+	return nil
+}
+
+// PriceAt tells what the item cost, or will cost, at a store at some time.
+func (s *PriceService) PriceAt(ctx context.Context, auth *model.Auth, menuItemID int, request *model.EffectivePriceRequest) (*model.EffectivePriceResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid price filter", apperrors.GetValidateMessage(err))
+	}
+
+	at := time.Now()
+	if request.At != "" {
+		parsed, err := time.Parse(time.RFC3339, request.At)
+		if err != nil {
+			return nil, fiber.ErrBadRequest
+		}
+		at = parsed
+	}
+
+	storeID := request.StoreID
+	if storeID != 0 || auth.Role != entity.RoleAdmin {
+		scoped, err := auth.ScopeStore(storeID)
+		if err != nil {
+			return nil, err
+		}
+		storeID = scoped
+	}
+
+	price, err := s.Menu.PriceAt(ctx, menuItemID, storeID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.EffectivePriceResponse{
+		MenuItemID: menuItemID,
+		StoreID:    storeID,
+		At:         at,
+		Price:      price,
+	}, nil
+}
+
+// ApplyDue is the scheduler job that puts scheduled prices into effect and
+// tells every store whose prices moved.
+func (s *PriceService) ApplyDue(ctx context.Context) error {
+	changes, err := s.Menu.ApplyDuePrices(ctx)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		s.Log.Infof("prices changed on %d store items", len(changes))
+	}
+
+	for _, change := range changes {
+		event := &model.MenuItemChanged{MenuItemID: change.MenuItemID, Reason: "price"}
+		if err := s.Events.Publish(ctx, change.StoreID, event); err != nil {
+			s.Log.Warnf("failed to publish price change of item %d at store %d: %v", change.MenuItemID, change.StoreID, err)
+		}
+	}
+
+	return nil
+}
+
+// priceStore is the store a price belongs to, 0 for a base price.
+func priceStore(price *entity.MenuItemPrice) int {
+	if price.StoreID == nil {
+		return 0
+	}
+	return *price.StoreID
+}
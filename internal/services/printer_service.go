@@ -0,0 +1,314 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultPrintJobLimit = 50
+
+// PrinterService manages a store's printers and queues what they print:
+// receipts when an order is placed and station tickets once the order is
+// pending, i.e. right away or when a pre-order is released.
+type PrinterService struct {
+	Repo     model.PrinterRepository
+	Queue    model.PrintQueue
+	Stations model.StationRepository
+	Audit    model.AuditService
+	Validate *validator.Validate
+	Log      *logrus.Logger
+}
+
+func NewPrinterService(repo model.PrinterRepository, queue model.PrintQueue, stations model.StationRepository, audit model.AuditService, validate *validator.Validate, log *logrus.Logger) model.PrinterService {
+	return &PrinterService{
+		Repo:     repo,
+		Queue:    queue,
+		Stations: stations,
+		Audit:    audit,
+		Validate: validate,
+		Log:      log,
+	}
+}
+
+func (s *PrinterService) Printers(ctx context.Context, auth *model.Auth, storeID int) ([]entity.Printer, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repo.FindPrinters(ctx, storeID)
+}
+
+func (s *PrinterService) CreatePrinter(ctx context.Context, auth *model.Auth, storeID int, request *model.CreatePrinterRequest) (*entity.Printer, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid printer", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	printer := &entity.Printer{
+		StoreID:        storeID,
+		Name:           request.Name,
+		Host:           request.Host,
+		Port:           request.Port,
+		PaperWidth:     request.PaperWidth,
+		PrintsReceipts: request.PrintsReceipts,
+		IsActive:       true,
+	}
+	if printer.Port == 0 {
+		printer.Port = 9100
+	}
+	if printer.PaperWidth == 0 {
+		printer.PaperWidth = 80
+	}
+	if err := s.attachStation(ctx, printer, request.StationID); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.SavePrinter(ctx, printer); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, storeID, "printer", printer.ID, nil, printer)
+
+	return printer, nil
+}
+
+func (s *PrinterService) UpdatePrinter(ctx context.Context, auth *model.Auth, storeID int, id int, request *model.UpdatePrinterRequest) (*entity.Printer, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid printer", apperrors.GetValidateMessage(err))
+	}
+
+	printer, err := s.storePrinter(ctx, auth, storeID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *printer
+	if request.Name != "" {
+		printer.Name = request.Name
+	}
+	if request.Host != "" {
+		printer.Host = request.Host
+	}
+	if request.Port != 0 {
+		printer.Port = request.Port
+	}
+	if request.PaperWidth != 0 {
+		printer.PaperWidth = request.PaperWidth
+	}
+	if request.StationID != nil {
+		if err := s.attachStation(ctx, printer, *request.StationID); err != nil {
+			return nil, err
+		}
+	}
+	if request.PrintsReceipts != nil {
+		printer.PrintsReceipts = *request.PrintsReceipts
+	}
+	if request.IsActive != nil {
+		printer.IsActive = *request.IsActive
+	}
+
+	if err := s.Repo.SavePrinter(ctx, printer); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, printer.StoreID, "printer", printer.ID, &before, printer)
+
+	return printer, nil
+}
+
+func (s *PrinterService) Jobs(ctx context.Context, auth *model.Auth, storeID int, request *model.PrintJobListRequest) ([]entity.PrintJob, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid print job filter", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultPrintJobLimit
+	}
+
+	return s.Repo.FindJobs(ctx, storeID, request.Status, limit)
+}
+
+// Reprint queues a copy of a job on the same printer.
+func (s *PrinterService) Reprint(ctx context.Context, auth *model.Auth, jobID int) (*entity.PrintJob, error) {
+	original, err := s.Repo.FindJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	printer, err := s.Repo.FindPrinter(ctx, original.PrinterID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := auth.ScopeStore(printer.StoreID); err != nil {
+		return nil, err
+	}
+	if !printer.IsActive {
+		return nil, fiber.NewError(fiber.StatusConflict, "printer is not active")
+	}
+
+	job := &entity.PrintJob{
+		PrinterID: original.PrinterID,
+		OrderID:   original.OrderID,
+		TicketID:  original.TicketID,
+		Kind:      original.Kind,
+		CreatedBy: nullableUser(auth.UserID()),
+		ReprintOf: &original.ID,
+	}
+	if err := s.enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// QueueReceipt prints the order's receipt on every receipt printer of its
+// store.
+func (s *PrinterService) QueueReceipt(ctx context.Context, order *entity.Order) error {
+	printers, err := s.Repo.FindReceiptPrinters(ctx, order.StoreID)
+	if err != nil {
+		return err
+	}
+
+	for _, printer := range printers {
+		job := &entity.PrintJob{PrinterID: printer.ID, OrderID: order.ID, Kind: entity.PrintReceipt}
+		if err := s.enqueue(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// QueueTickets prints each ticket of the order on its station's printers.
+func (s *PrinterService) QueueTickets(ctx context.Context, orderID int) error {
+	tickets, err := s.Stations.FindTickets(ctx, orderID)
+	if err != nil || len(tickets) == 0 {
+		return err
+	}
+
+	stationIDs := make([]int, 0, len(tickets))
+	for _, ticket := range tickets {
+		stationIDs = append(stationIDs, ticket.StationID)
+	}
+	printers, err := s.Repo.FindStationPrinters(ctx, stationIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, ticket := range tickets {
+		for _, printer := range printers {
+			if *printer.StationID != ticket.StationID {
+				continue
+			}
+			job := &entity.PrintJob{PrinterID: printer.ID, OrderID: orderID, TicketID: &ticket.ID, Kind: entity.PrintTicket}
+			if err := s.enqueue(ctx, job); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// OrderPlaced prints the receipt of a new order, and its tickets unless it is
+// a pre-order.
+func (s *PrinterService) OrderPlaced(ctx context.Context, event *model.Event) error {
+	placed, ok := event.Data.(*model.OrderPlaced)
+	if !ok {
+		return nil
+	}
+
+	if err := s.QueueReceipt(ctx, placed.Order); err != nil {
+		return err
+	}
+	if placed.Order.Status == entity.OrderPending {
+		return s.QueueTickets(ctx, placed.Order.ID)
+	}
+
+	return nil
+}
+
+// OrderStatusChanged prints the tickets of a pre-order once it is released.
+func (s *PrinterService) OrderStatusChanged(ctx context.Context, event *model.Event) error {
+	changed, ok := event.Data.(*model.OrderStatusChanged)
+	if !ok || changed.To != entity.OrderPending {
+		return nil
+	}
+
+	return s.QueueTickets(ctx, changed.Order.ID)
+}
+
+// enqueue stores the job and hands it to the workers. A job Redis did not
+// take is marked failed so it shows up for a reprint. A job stored by an
+// earlier delivery of the same event is pushed again while it is still
+// queued, in case that delivery died before the push; the worker starts a
+// job only once.
+func (s *PrinterService) enqueue(ctx context.Context, job *entity.PrintJob) error {
+	job.Status = entity.PrintQueued
+	if err := s.Repo.StoreJob(ctx, job); err != nil {
+		return err
+	}
+	if job.Status != entity.PrintQueued {
+		return nil
+	}
+
+	if err := s.Queue.Push(ctx, job.ID); err != nil {
+		job.Status = entity.PrintFailed
+		job.LastError = "print queue unavailable"
+		if err := s.Repo.FinishJob(ctx, job); err != nil {
+			s.Log.Warnf("failed to mark print job %d failed: %v", job.ID, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *PrinterService) attachStation(ctx context.Context, printer *entity.Printer, stationID int) error {
+	if stationID == 0 {
+		printer.StationID = nil
+		return nil
+	}
+
+	station, err := s.Stations.FindStation(ctx, stationID)
+	if err != nil {
+		return err
+	}
+	if station.StoreID != printer.StoreID {
+		return fiber.ErrNotFound
+	}
+	printer.StationID = &station.ID
+
+	return nil
+}
+
+func (s *PrinterService) storePrinter(ctx context.Context, auth *model.Auth, storeID int, id int) (*entity.Printer, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	printer, err := s.Repo.FindPrinter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if printer.StoreID != storeID {
+		return nil, fiber.ErrNotFound
+	}
+
+	return printer, nil
+}
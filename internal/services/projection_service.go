@@ -0,0 +1,147 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// ProjectionService copies completed orders from Postgres into the Mongo
+// reporting store so analytics never touch the OLTP tables.
+type ProjectionService struct {
+	Orders      model.OrderRepository
+	Payments    model.PaymentRepository
+	Stores      model.StoreRepository
+	Projections model.OrderProjectionRepository
+	Validate    *validator.Validate
+	Log         *logrus.Logger
+	Location    *time.Location
+}
+
+func NewProjectionService(orders model.OrderRepository, payments model.PaymentRepository, stores model.StoreRepository, projections model.OrderProjectionRepository, validate *validator.Validate, viper *viper.Viper, log *logrus.Logger) model.ProjectionService {
+	return &ProjectionService{
+		Orders:      orders,
+		Payments:    payments,
+		Stores:      stores,
+		Projections: projections,
+		Validate:    validate,
+		Log:         log,
+		Location:    appLocation(viper, log),
+	}
+}
+
+func (s *ProjectionService) Project(ctx context.Context, orderID int) error {
+	order, err := s.Orders.FindById(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != entity.OrderCompleted {
+		return fiber.NewError(fiber.StatusConflict, "only completed orders are projected")
+	}
+
+	store, err := s.Stores.FindById(ctx, order.StoreID)
+	if err != nil {
+		return err
+	}
+
+	items, err := s.Orders.FindItems(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	payments, err := s.Payments.FindByOrderId(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	completedAt, err := s.Orders.FindCompletedAt(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	projection := &entity.OrderProjection{
+		OrderID:      order.ID,
+		OrderNumber:  order.OrderNumber,
+		Store:        entity.ProjectedStore{ID: store.ID, Name: store.Name, Slug: store.StoreSlug},
+		Status:       order.Status,
+		OrderType:    order.OrderType,
+		Fees:         order.Fees,
+		Total:        order.Total,
+		CustomerNote: order.CustomerNote,
+		Items:        make([]entity.ProjectedItem, 0, len(items)),
+		Payments:     make([]entity.ProjectedPayment, 0, len(payments)),
+		CreatedAt:    order.CreatedAt,
+		CompletedAt:  completedAt,
+		ProjectedAt:  time.Now(),
+	}
+
+	for _, item := range items {
+		customizations := item.CustomizationList()
+		if customizations == nil {
+			customizations = []entity.OrderItemCustomization{}
+		}
+
+		projection.Items = append(projection.Items, entity.ProjectedItem{
+			MenuItemID:     item.MenuItemID,
+			Name:           item.MenuItemName,
+			CategoryID:     item.CategoryID,
+			CategoryName:   item.CategoryName,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			Subtotal:       item.UnitPrice * int64(item.Quantity),
+			Customizations: customizations,
+			Note:           item.Note,
+		})
+	}
+
+	for _, payment := range payments {
+		projection.Payments = append(projection.Payments, entity.ProjectedPayment{
+			ID:         payment.ID,
+			Method:     payment.Method,
+			Amount:     payment.Amount,
+			Status:     payment.Status,
+			CapturedAt: payment.CapturedAt,
+		})
+	}
+
+	return s.Projections.Upsert(ctx, projection)
+}
+
+// Rebuild re-projects every completed order updated since the start of the
+// given day in the app timezone. It keeps going past failures so one bad
+// order does not block a backfill.
+func (s *ProjectionService) Rebuild(ctx context.Context, request *model.RebuildProjectionRequest) (*model.RebuildProjectionResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid rebuild request", apperrors.GetValidateMessage(err))
+	}
+
+	since, err := time.ParseInLocation(time.DateOnly, request.Since, s.Location)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "since must be a YYYY-MM-DD date")
+	}
+
+	ids, err := s.Orders.FindCompletedIds(ctx, request.StoreID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	response := new(model.RebuildProjectionResponse)
+	for _, id := range ids {
+		if err := s.Project(ctx, id); err != nil {
+			s.Log.Warnf("failed to project order %d: %v", id, err)
+			response.Failed++
+			continue
+		}
+		response.Projected++
+	}
+
+	return response, nil
+}
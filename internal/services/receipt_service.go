@@ -0,0 +1,166 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"context"
+	"math"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// ReceiptService gathers what receipts and station tickets print. Rendering
+// them for a printer is up to the caller.
+type ReceiptService struct {
+	Orders     model.OrderRepository
+	Payments   model.PaymentRepository
+	Stores     model.StoreRepository
+	Stations   model.StationRepository
+	Hours      model.StoreHoursService
+	Log        *logrus.Logger
+	TaxName    string
+	TaxPercent float64
+	Footer     string
+}
+
+func NewReceiptService(orders model.OrderRepository, payments model.PaymentRepository, stores model.StoreRepository, stations model.StationRepository, hours model.StoreHoursService, viper *viper.Viper, log *logrus.Logger) model.ReceiptService {
+	return &ReceiptService{
+		Orders:     orders,
+		Payments:   payments,
+		Stores:     stores,
+		Stations:   stations,
+		Hours:      hours,
+		Log:        log,
+		TaxName:    viper.GetString("receipt.tax_name"),
+		TaxPercent: viper.GetFloat64("receipt.tax_percent"),
+		Footer:     viper.GetString("receipt.footer"),
+	}
+}
+
+func (s *ReceiptService) Receipt(ctx context.Context, auth *model.Auth, orderID int) (*model.Receipt, error) {
+	order, err := s.Orders.FindById(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := auth.ScopeStore(order.StoreID); err != nil {
+		return nil, err
+	}
+
+	return s.receipt(ctx, order)
+}
+
+// ForOrder is Receipt without the store check, for the print workers.
+func (s *ReceiptService) ForOrder(ctx context.Context, orderID int) (*model.Receipt, error) {
+	order, err := s.Orders.FindById(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.receipt(ctx, order)
+}
+
+func (s *ReceiptService) Ticket(ctx context.Context, auth *model.Auth, ticketID int) (*model.TicketSlip, error) {
+	ticket, err := s.Stations.FindTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.Orders.FindById(ctx, ticket.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := auth.ScopeStore(order.StoreID); err != nil {
+		return nil, err
+	}
+
+	return s.slip(ctx, ticket, order)
+}
+
+// ForTicket is Ticket without the store check, for the print workers.
+func (s *ReceiptService) ForTicket(ctx context.Context, ticketID int) (*model.TicketSlip, error) {
+	ticket, err := s.Stations.FindTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.Orders.FindById(ctx, ticket.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.slip(ctx, ticket, order)
+}
+
+func (s *ReceiptService) receipt(ctx context.Context, order *entity.Order) (*model.Receipt, error) {
+	store, err := s.Stores.FindById(ctx, order.StoreID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.Orders.FindItems(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.Payments.FindByOrderId(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Receipt{
+		Store:    store,
+		Order:    order,
+		Items:    items,
+		Payments: payments,
+		Tax:      s.includedTax(order),
+		IssuedAt: order.CreatedAt.In(s.Hours.Location(store)),
+		Footer:   s.Footer,
+	}, nil
+}
+
+func (s *ReceiptService) slip(ctx context.Context, ticket *entity.OrderTicket, order *entity.Order) (*model.TicketSlip, error) {
+	store, err := s.Stores.FindById(ctx, order.StoreID)
+	if err != nil {
+		return nil, err
+	}
+	station, err := s.Stations.FindStation(ctx, ticket.StationID)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.Orders.FindItems(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []model.OrderItemDetail{}
+	for _, item := range all {
+		if item.StationID != nil && *item.StationID == station.ID {
+			items = append(items, item)
+		}
+	}
+
+	location := s.Hours.Location(store)
+	slip := &model.TicketSlip{
+		Station:  station,
+		Ticket:   ticket,
+		Order:    order,
+		Items:    items,
+		PlacedAt: order.CreatedAt.In(location),
+	}
+	if order.PickupAt != nil {
+		pickupAt := order.PickupAt.In(location)
+		slip.PickupAt = &pickupAt
+	}
+
+	return slip, nil
+}
+
+// includedTax is the tax contained in what the customer pays for the items.
+// Order fees are not taxed.
+func (s *ReceiptService) includedTax(order *entity.Order) model.ReceiptTax {
+	tax := model.ReceiptTax{Name: s.TaxName, Percent: s.TaxPercent}
+	if s.TaxPercent <= 0 {
+		return tax
+	}
+
+	taxable := order.Total - order.Fees
+	tax.Amount = int64(math.Round(float64(taxable) * s.TaxPercent / (100 + s.TaxPercent)))
+	return tax
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// reportShift is a named time-of-day window from report.shifts. A window whose
+// end is before its start runs past midnight.
+type reportShift struct {
+	Name  string `mapstructure:"name"`
+	Start string `mapstructure:"start"`
+	End   string `mapstructure:"end"`
+}
+
+// PrepTimes measures how long orders sit in each status, overall, per hour of
+// day and per barista, and counts orders each barista started per shift.
+func (s *ReportService) PrepTimes(ctx context.Context, auth *model.Auth, request *model.PrepTimeRequest) (*model.PrepTimeResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid report filter", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(request.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := parsePeriod(request.From, request.To, s.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Repo.OrderTimelines(ctx, storeID, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+
+	all := new(stageSamples)
+	hours := map[int]*stageSamples{}
+	baristas := map[int]*baristaSamples{}
+	shifts := map[shiftKey]*model.ShiftThroughput{}
+
+	for _, row := range rows {
+		all.add(row)
+		hourlySamples(hours, row.PendingAt.In(s.Location).Hour()).add(row)
+
+		if row.BaristaID == nil {
+			continue
+		}
+
+		barista, ok := baristas[*row.BaristaID]
+		if !ok {
+			barista = &baristaSamples{id: *row.BaristaID, hours: map[int]*stageSamples{}}
+			if row.BaristaName != nil {
+				barista.name = *row.BaristaName
+			}
+			baristas[*row.BaristaID] = barista
+		}
+		barista.all.add(row)
+		hourlySamples(barista.hours, row.PendingAt.In(s.Location).Hour()).add(row)
+
+		if row.PreparingAt == nil {
+			continue
+		}
+		date, shift := s.shiftOf(row.PreparingAt.In(s.Location))
+		key := shiftKey{date, shift, barista.id}
+		if _, ok := shifts[key]; !ok {
+			shifts[key] = &model.ShiftThroughput{Date: date, Shift: shift, UserID: barista.id, FullName: barista.name}
+		}
+		shifts[key].Orders++
+	}
+
+	response := &model.PrepTimeResponse{
+		StoreID:  storeID,
+		Period:   model.ReportPeriod{From: period.From, To: period.To},
+		Orders:   all.orders,
+		Stages:   all.stats(),
+		Hours:    hourlyStats(hours),
+		Baristas: make([]model.BaristaPrepStats, 0, len(baristas)),
+		Shifts:   make([]model.ShiftThroughput, 0, len(shifts)),
+	}
+
+	for _, barista := range baristas {
+		response.Baristas = append(response.Baristas, model.BaristaPrepStats{
+			UserID:   barista.id,
+			FullName: barista.name,
+			Orders:   barista.all.orders,
+			Stages:   barista.all.stats(),
+			Hours:    hourlyStats(barista.hours),
+		})
+	}
+	sort.Slice(response.Baristas, func(i, j int) bool {
+		return response.Baristas[i].Orders > response.Baristas[j].Orders
+	})
+
+	for _, shift := range shifts {
+		response.Shifts = append(response.Shifts, *shift)
+	}
+	sort.Slice(response.Shifts, func(i, j int) bool {
+		a, b := response.Shifts[i], response.Shifts[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Shift != b.Shift {
+			return a.Shift < b.Shift
+		}
+		return a.UserID < b.UserID
+	})
+
+	return response, nil
+}
+
+// shiftOf names the configured shift t falls in. Time after midnight in an
+// overnight shift counts towards the day the shift started.
+func (s *ReportService) shiftOf(t time.Time) (string, string) {
+	clock := t.Hour()*60 + t.Minute()
+
+	for _, shift := range s.Shifts {
+		start, startErr := clockMinutes(shift.Start)
+		end, endErr := clockMinutes(shift.End)
+		if startErr != nil || endErr != nil {
+			continue
+		}
+
+		switch {
+		case start <= end && clock >= start && clock < end:
+			return t.Format(time.DateOnly), shift.Name
+		case start > end && clock >= start:
+			return t.Format(time.DateOnly), shift.Name
+		case start > end && clock < end:
+			return t.AddDate(0, 0, -1).Format(time.DateOnly), shift.Name
+		}
+	}
+
+	return t.Format(time.DateOnly), "unscheduled"
+}
+
+func clockMinutes(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+type shiftKey struct {
+	date   string
+	shift  string
+	userID int
+}
+
+type baristaSamples struct {
+	id    int
+	name  string
+	all   stageSamples
+	hours map[int]*stageSamples
+}
+
+// stageSamples collects stage durations in seconds.
+type stageSamples struct {
+	orders  int
+	queue   []float64
+	prep    []float64
+	handoff []float64
+}
+
+func (s *stageSamples) add(row model.OrderTimelineRow) {
+	s.orders++
+
+	if row.PreparingAt != nil {
+		s.queue = append(s.queue, row.PreparingAt.Sub(row.PendingAt).Seconds())
+	}
+	if row.PreparingAt != nil && row.ReadyAt != nil {
+		s.prep = append(s.prep, row.ReadyAt.Sub(*row.PreparingAt).Seconds())
+	}
+	if row.ReadyAt != nil && row.CompletedAt != nil {
+		s.handoff = append(s.handoff, row.CompletedAt.Sub(*row.ReadyAt).Seconds())
+	}
+}
+
+func (s *stageSamples) stats() model.PrepStages {
+	return model.PrepStages{
+		PendingToPreparing: stageStats(s.queue),
+		PreparingToReady:   stageStats(s.prep),
+		ReadyToCompleted:   stageStats(s.handoff),
+	}
+}
+
+func hourlySamples(hours map[int]*stageSamples, hour int) *stageSamples {
+	samples, ok := hours[hour]
+	if !ok {
+		samples = new(stageSamples)
+		hours[hour] = samples
+	}
+	return samples
+}
+
+func hourlyStats(hours map[int]*stageSamples) []model.HourlyPrepStats {
+	out := make([]model.HourlyPrepStats, 0, len(hours))
+	for hour, samples := range hours {
+		out = append(out, model.HourlyPrepStats{Hour: hour, Orders: samples.orders, Stages: samples.stats()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Hour < out[j].Hour })
+
+	return out
+}
+
+func stageStats(samples []float64) model.StageStats {
+	if len(samples) == 0 {
+		return model.StageStats{}
+	}
+
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+
+	total := 0.0
+	for _, sample := range sorted {
+		total += sample
+	}
+
+	return model.StageStats{
+		Count:   len(sorted),
+		Average: roundTenth(total / float64(len(sorted))),
+		P50:     roundTenth(percentile(sorted, 50)),
+		P90:     roundTenth(percentile(sorted, 90)),
+		P95:     roundTenth(percentile(sorted, 95)),
+	}
+}
+
+// percentile interpolates linearly between the closest ranks of sorted.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func roundTenth(value float64) float64 {
+	return math.Round(value*10) / 10
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type ReportService struct {
+	Repo        model.ReportRepository
+	Projections model.OrderProjectionRepository
+	Validate    *validator.Validate
+	Log         *logrus.Logger
+	Location    *time.Location
+	Shifts      []reportShift
+}
+
+func NewReportService(repo model.ReportRepository, projections model.OrderProjectionRepository, validate *validator.Validate, viper *viper.Viper, log *logrus.Logger) model.ReportService {
+	shifts := []reportShift{}
+	if err := viper.UnmarshalKey("report.shifts", &shifts); err != nil {
+		log.Warnf("invalid report.shifts: %v", err)
+	}
+
+	return &ReportService{
+		Repo:        repo,
+		Projections: projections,
+		Validate:    validate,
+		Log:         log,
+		Location:    appLocation(viper, log),
+		Shifts:      shifts,
+	}
+}
+
+// SalesMix ranks menu items and customization options for the requested days
+// (both ends inclusive) and compares them with the period of the same length
+// right before it.
+func (s *ReportService) SalesMix(ctx context.Context, auth *model.Auth, request *model.SalesMixRequest) (*model.SalesMixResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid report filter", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(request.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := parsePeriod(request.From, request.To, s.Location)
+	if err != nil {
+		return nil, err
+	}
+	previous := period.Previous()
+
+	items, err := s.Repo.SalesMixByMenuItem(ctx, storeID, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	previousItems, err := s.Repo.SalesMixByMenuItem(ctx, storeID, previous.From, previous.To)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := s.Repo.SalesMixByCustomization(ctx, storeID, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+	previousOptions, err := s.Repo.SalesMixByCustomization(ctx, storeID, previous.From, previous.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SalesMixResponse{
+		StoreID:        storeID,
+		Period:         model.ReportPeriod{From: period.From, To: period.To},
+		PreviousPeriod: model.ReportPeriod{From: previous.From, To: previous.To},
+		Items:          compareSalesMix(items, previousItems, request.Sort),
+		Customizations: compareSalesMix(options, previousOptions, request.Sort),
+		Categories:     compareSalesMix(rollUpCategories(items), rollUpCategories(previousItems), request.Sort),
+	}, nil
+}
+
+// SalesAnalytics is served from the Mongo reporting store, so it only sees
+// orders that have been projected.
+func (s *ReportService) SalesAnalytics(ctx context.Context, auth *model.Auth, request *model.SalesAnalyticsRequest) (*model.SalesAnalyticsResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid report filter", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(request.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := parsePeriod(request.From, request.To, s.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := request.Limit
+	if limit == 0 {
+		limit = 10
+	}
+
+	daily, err := s.Projections.DailySales(ctx, storeID, period.From, period.To, s.Location.String())
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.Projections.TopItems(ctx, storeID, period.From, period.To, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.Projections.PaymentMethods(ctx, storeID, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SalesAnalyticsResponse{
+		StoreID:        storeID,
+		Period:         model.ReportPeriod{From: period.From, To: period.To},
+		DailySales:     daily,
+		TopItems:       items,
+		PaymentMethods: payments,
+	}, nil
+}
+
+type reportPeriod struct {
+	From time.Time
+	To   time.Time
+}
+
+func (p reportPeriod) Previous() reportPeriod {
+	return reportPeriod{From: p.From.Add(-p.To.Sub(p.From)), To: p.From}
+}
+
+// appLocation is the configured business timezone, used to turn calendar days
+// into time ranges.
+func appLocation(viper *viper.Viper, log *logrus.Logger) *time.Location {
+	location, err := time.LoadLocation(viper.GetString("app.timezone"))
+	if err != nil {
+		log.Warnf("unknown app.timezone, falling back to server time: %v", err)
+		return time.Local
+	}
+	return location
+}
+
+// parsePeriod turns inclusive YYYY-MM-DD days into a half-open time range
+// starting at midnight in location.
+func parsePeriod(from, to string, location *time.Location) (reportPeriod, error) {
+	start, err := time.ParseInLocation(time.DateOnly, from, location)
+	if err != nil {
+		return reportPeriod{}, fiber.NewError(fiber.StatusBadRequest, "from must be a YYYY-MM-DD date")
+	}
+
+	end, err := time.ParseInLocation(time.DateOnly, to, location)
+	if err != nil {
+		return reportPeriod{}, fiber.NewError(fiber.StatusBadRequest, "to must be a YYYY-MM-DD date")
+	}
+
+	if end.Before(start) {
+		return reportPeriod{}, fiber.NewError(fiber.StatusBadRequest, "to must not be before from")
+	}
+
+	return reportPeriod{From: start, To: end.AddDate(0, 0, 1)}, nil
+}
+
+func rollUpCategories(rows []model.SalesMixRow) []model.SalesMixRow {
+	byCategory := map[int]*model.SalesMixRow{}
+	for _, row := range rows {
+		category, ok := byCategory[row.ParentID]
+		if !ok {
+			category = &model.SalesMixRow{ID: row.ParentID, Name: row.ParentName}
+			byCategory[row.ParentID] = category
+		}
+		category.Quantity += row.Quantity
+		category.Revenue += row.Revenue
+	}
+
+	out := make([]model.SalesMixRow, 0, len(byCategory))
+	for _, category := range byCategory {
+		out = append(out, *category)
+	}
+	// Map order is random; ties must come out the same on every call.
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Revenue != out[j].Revenue {
+			return out[i].Revenue > out[j].Revenue
+		}
+		return out[i].ID < out[j].ID
+	})
+
+	return out
+}
+
+// compareSalesMix joins the current and previous rows by id, keeping rows that
+// only sold in the previous period so dropped products stay visible.
+func compareSalesMix(current, previous []model.SalesMixRow, sortBy string) []model.SalesMixLine {
+	lines := make([]model.SalesMixLine, 0, len(current))
+	index := map[int]int{}
+
+	for _, row := range current {
+		index[row.ID] = len(lines)
+		lines = append(lines, model.SalesMixLine{
+			ID:         row.ID,
+			Name:       row.Name,
+			ParentID:   row.ParentID,
+			ParentName: row.ParentName,
+			Quantity:   row.Quantity,
+			Revenue:    row.Revenue,
+		})
+	}
+
+	for _, row := range previous {
+		i, ok := index[row.ID]
+		if !ok {
+			i = len(lines)
+			lines = append(lines, model.SalesMixLine{
+				ID:         row.ID,
+				Name:       row.Name,
+				ParentID:   row.ParentID,
+				ParentName: row.ParentName,
+			})
+		}
+		lines[i].PreviousQuantity = row.Quantity
+		lines[i].PreviousRevenue = row.Revenue
+	}
+
+	for i := range lines {
+		lines[i].QuantityChange = percentChange(lines[i].Quantity, lines[i].PreviousQuantity)
+		lines[i].RevenueChange = percentChange(lines[i].Revenue, lines[i].PreviousRevenue)
+	}
+
+	quantityRanks := competitionRanks(lines, func(line model.SalesMixLine) int64 { return line.Quantity })
+	revenueRanks := competitionRanks(lines, func(line model.SalesMixLine) int64 { return line.Revenue })
+	for i := range lines {
+		lines[i].QuantityRank = quantityRanks[i]
+		lines[i].RevenueRank = revenueRanks[i]
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		if sortBy == "quantity" {
+			return lines[i].QuantityRank < lines[j].QuantityRank
+		}
+		return lines[i].RevenueRank < lines[j].RevenueRank
+	})
+
+	return lines
+}
+
+// competitionRanks ranks lines by descending value, ties share a rank (1, 2, 2, 4).
+func competitionRanks(lines []model.SalesMixLine, value func(model.SalesMixLine) int64) []int {
+	order := make([]int, len(lines))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return value(lines[order[i]]) > value(lines[order[j]])
+	})
+
+	ranks := make([]int, len(lines))
+	for position, i := range order {
+		if position > 0 && value(lines[i]) == value(lines[order[position-1]]) {
+			ranks[i] = ranks[order[position-1]]
+			continue
+		}
+		ranks[i] = position + 1
+	}
+
+	return ranks
+}
+
+func percentChange(current, previous int64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+
+	change := math.Round(float64(current-previous)/float64(previous)*10000) / 100
+	return &change
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type ScheduleService struct {
+	Schedules model.ScheduleRepository
+	Entries   model.TimeEntryRepository
+	Users     model.UserRepository
+	Validate  *validator.Validate
+	Log       *logrus.Logger
+	Location  *time.Location
+}
+
+func NewScheduleService(schedules model.ScheduleRepository, entries model.TimeEntryRepository, users model.UserRepository, validate *validator.Validate, viper *viper.Viper, log *logrus.Logger) model.ScheduleService {
+	return &ScheduleService{
+		Schedules: schedules,
+		Entries:   entries,
+		Users:     users,
+		Validate:  validate,
+		Log:       log,
+		Location:  appLocation(viper, log),
+	}
+}
+
+func (s *ScheduleService) Create(ctx context.Context, auth *model.Auth, request *model.CreateScheduleRequest) (*entity.StaffSchedule, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid schedule", apperrors.GetValidateMessage(err))
+	}
+
+	user, err := s.Users.FindById(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := auth.ScopeStore(user.StoreID); err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, fiber.NewError(fiber.StatusConflict, "user is not active")
+	}
+
+	schedule := &entity.StaffSchedule{
+		StoreID:   user.StoreID,
+		UserID:    user.ID,
+		StartsAt:  request.StartsAt,
+		EndsAt:    request.EndsAt,
+		Note:      request.Note,
+		CreatedBy: nullableUser(auth.UserID()),
+	}
+
+	if err := s.Schedules.Store(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *ScheduleService) List(ctx context.Context, auth *model.Auth, request *model.ScheduleListRequest) ([]entity.StaffSchedule, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid schedule filter", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(request.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := parsePeriod(request.From, request.To, s.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Schedules.FindByStore(ctx, storeID, period.From, period.To)
+}
+
+func (s *ScheduleService) Remove(ctx context.Context, auth *model.Auth, id int) error {
+	schedule, err := s.Schedules.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := auth.ScopeStore(schedule.StoreID); err != nil {
+		return err
+	}
+
+	return s.Schedules.Remove(ctx, schedule.ID)
+}
+
+// Timesheet totals worked time per staff member for a pay period. Worked time
+// is clock-in to clock-out minus breaks; entries still open count up to now.
+func (s *ScheduleService) Timesheet(ctx context.Context, auth *model.Auth, request *model.TimesheetRequest) (*model.TimesheetResponse, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid timesheet filter", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(request.StoreID)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := parsePeriod(request.From, request.To, s.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Entries.Timesheet(ctx, storeID, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.Schedules.FindByStore(ctx, storeID, period.From, period.To)
+	if err != nil {
+		return nil, err
+	}
+
+	users := map[int]*model.TimesheetUser{}
+	for _, row := range rows {
+		end := time.Now()
+		if row.ClockOutAt != nil {
+			end = *row.ClockOutAt
+		}
+		row.BreakMinutes = row.BreakSeconds / 60
+		row.WorkedMinutes = max(int64(end.Sub(row.ClockInAt).Minutes())-row.BreakMinutes, 0)
+
+		user, ok := users[row.UserID]
+		if !ok {
+			user = &model.TimesheetUser{UserID: row.UserID, FullName: row.FullName}
+			users[row.UserID] = user
+		}
+		user.WorkedMinutes += row.WorkedMinutes
+		user.BreakMinutes += row.BreakMinutes
+		user.Entries = append(user.Entries, row)
+	}
+
+	for _, schedule := range schedules {
+		user, ok := users[schedule.UserID]
+		if !ok {
+			continue
+		}
+		user.ScheduledMinutes += int64(schedule.EndsAt.Sub(schedule.StartsAt).Minutes())
+	}
+
+	response := &model.TimesheetResponse{
+		StoreID: storeID,
+		Period:  model.ReportPeriod{From: period.From, To: period.To},
+		Users:   make([]model.TimesheetUser, 0, len(users)),
+	}
+	for _, user := range users {
+		response.Users = append(response.Users, *user)
+	}
+	sort.Slice(response.Users, func(i, j int) bool {
+		return response.Users[i].FullName < response.Users[j].FullName
+	})
+
+	return response, nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ShiftService struct {
+	Repo     model.ShiftRepository
+	Validate *validator.Validate
+	Log      *logrus.Logger
+}
+
+func NewShiftService(repo model.ShiftRepository, validate *validator.Validate, log *logrus.Logger) model.ShiftService {
+	return &ShiftService{
+		Repo:     repo,
+		Validate: validate,
+		Log:      log,
+	}
+}
+
+func (s *ShiftService) Open(ctx context.Context, auth *model.Auth, request *model.OpenShiftRequest) (*entity.CashShift, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid shift", apperrors.GetValidateMessage(err))
+	}
+
+	if auth.StoreID == 0 {
+		return nil, fiber.NewError(fiber.StatusForbidden, "only store staff can open a shift")
+	}
+
+	shift := &entity.CashShift{
+		StoreID:      auth.StoreID,
+		OpenedBy:     auth.UserID(),
+		Status:       entity.ShiftOpen,
+		OpeningFloat: request.OpeningFloat,
+		Note:         request.Note,
+	}
+
+	if err := s.Repo.Store(ctx, shift); err != nil {
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+func (s *ShiftService) Current(ctx context.Context, auth *model.Auth, storeID int) (*model.ShiftReport, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	shift, err := s.Repo.FindOpenByStore(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.report(ctx, shift)
+}
+
+func (s *ShiftService) AddMovement(ctx context.Context, auth *model.Auth, id int, request *model.CashMovementRequest) (*entity.CashMovement, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid cash movement", apperrors.GetValidateMessage(err))
+	}
+
+	shift, err := s.find(ctx, auth, id)
+	if err != nil {
+		return nil, err
+	}
+
+	movement := &entity.CashMovement{
+		ShiftID:   shift.ID,
+		Type:      request.Type,
+		Amount:    request.Amount,
+		Reason:    request.Reason,
+		CreatedBy: nullableUser(auth.UserID()),
+	}
+
+	if err := s.Repo.StoreMovement(ctx, movement); err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+func (s *ShiftService) Close(ctx context.Context, auth *model.Auth, id int, request *model.CloseShiftRequest) (*model.ShiftReport, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid shift count", apperrors.GetValidateMessage(err))
+	}
+
+	shift, err := s.find(ctx, auth, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if shift.Status != entity.ShiftOpen {
+		return nil, fiber.NewError(fiber.StatusConflict, "shift is already closed")
+	}
+
+	// The repository works out the expected cash under the shift lock.
+	shift.ClosedBy = nullableUser(auth.UserID())
+	shift.CountedAmount = request.CountedAmount
+	shift.Note = request.Note
+
+	if err := s.Repo.Close(ctx, shift); err != nil {
+		return nil, err
+	}
+
+	return s.report(ctx, shift)
+}
+
+func (s *ShiftService) Report(ctx context.Context, auth *model.Auth, id int) (*model.ShiftReport, error) {
+	shift, err := s.find(ctx, auth, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.report(ctx, shift)
+}
+
+func (s *ShiftService) find(ctx context.Context, auth *model.Auth, id int) (*entity.CashShift, error) {
+	shift, err := s.Repo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := auth.ScopeStore(shift.StoreID); err != nil {
+		return nil, err
+	}
+
+	return shift, nil
+}
+
+func (s *ShiftService) report(ctx context.Context, shift *entity.CashShift) (*model.ShiftReport, error) {
+	payments, err := s.Repo.PaymentTotals(ctx, shift.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := s.Repo.FindMovements(ctx, shift.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.ShiftReport{
+		Shift:     shift,
+		Payments:  payments,
+		Movements: movements,
+	}
+
+	for _, total := range payments {
+		if total.Method == entity.PaymentCash {
+			report.CashSales += total.Amount
+		}
+	}
+
+	for _, movement := range movements {
+		switch movement.Type {
+		case entity.CashPayIn:
+			report.PayIns += movement.Amount
+		case entity.CashPayOut:
+			report.PayOuts += movement.Amount
+		}
+	}
+
+	report.ExpectedCash = shift.OpeningFloat + report.CashSales + report.PayIns - report.PayOuts
+
+	// A closed shift reports what was expected at close time, not a
+	// recomputation, so late corrections do not rewrite history.
+	if shift.Status == entity.ShiftClosed && shift.ExpectedAmount != nil {
+		report.ExpectedCash = *shift.ExpectedAmount
+		report.CountedCash = shift.CountedAmount
+		report.Variance = shift.Variance
+
+		switch {
+		case shift.Variance == nil:
+		case *shift.Variance > 0:
+			report.Result = "over"
+		case *shift.Variance < 0:
+			report.Result = "short"
+		default:
+			report.Result = "balanced"
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+)
+
+// StampService runs the "buy N, get one free" cards. Every qualifying item of
+// a completed order is one stamp; a full card pays for one free item.
+type StampService struct {
+	Repo     model.StampRepository
+	Orders   model.OrderRepository
+	Audit    model.AuditService
+	Validate *validator.Validate
+	Log      *logrus.Logger
+}
+
+func NewStampService(repo model.StampRepository, orders model.OrderRepository, audit model.AuditService, validate *validator.Validate, log *logrus.Logger) model.StampService {
+	return &StampService{
+		Repo:     repo,
+		Orders:   orders,
+		Audit:    audit,
+		Validate: validate,
+		Log:      log,
+	}
+}
+
+func (s *StampService) CreateProgram(ctx context.Context, auth *model.Auth, request *model.CreateStampProgramRequest) (*entity.StampProgram, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid stamp program", apperrors.GetValidateMessage(err))
+	}
+
+	program := &entity.StampProgram{
+		Name:           request.Name,
+		StampsRequired: request.StampsRequired,
+	}
+	if request.CategoryID != 0 {
+		program.CategoryID = &request.CategoryID
+	} else {
+		program.MenuItemID = &request.MenuItemID
+	}
+
+	if err := s.Repo.StoreProgram(ctx, program); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, 0, "stamp_program", program.ID, nil, program)
+
+	return program, nil
+}
+
+// UpdateProgram changes the name, card size or active flag. Stamps already
+// collected stay on the cards when the size changes.
+func (s *StampService) UpdateProgram(ctx context.Context, auth *model.Auth, id int, request *model.UpdateStampProgramRequest) (*entity.StampProgram, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid stamp program", apperrors.GetValidateMessage(err))
+	}
+
+	program, err := s.Repo.FindProgram(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *program
+	if request.Name != "" {
+		program.Name = request.Name
+	}
+	if request.StampsRequired != 0 {
+		program.StampsRequired = request.StampsRequired
+	}
+	if request.IsActive != nil {
+		program.IsActive = *request.IsActive
+	}
+
+	if err := s.Repo.UpdateProgram(ctx, program); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, 0, "stamp_program", program.ID, &before, program)
+
+	return program, nil
+}
+
+func (s *StampService) ListPrograms(ctx context.Context) ([]entity.StampProgram, error) {
+	return s.Repo.FindPrograms(ctx)
+}
+
+func (s *StampService) Cards(ctx context.Context, customerID int) ([]model.StampCardResponse, error) {
+	cards, err := s.Repo.FindCards(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	programs, err := s.Repo.FindPrograms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[int]*entity.StampProgram{}
+	for i := range programs {
+		byID[programs[i].ID] = &programs[i]
+	}
+
+	response := make([]model.StampCardResponse, 0, len(cards))
+	for _, card := range cards {
+		program, ok := byID[card.ProgramID]
+		if !ok {
+			continue
+		}
+		response = append(response, model.StampCardResponse{
+			Program:          program,
+			Stamps:           card.Stamps,
+			RewardsRedeemed:  card.RewardsRedeemed,
+			RewardsAvailable: card.Stamps / program.StampsRequired,
+		})
+	}
+
+	return response, nil
+}
+
+// Redemption checks the reward item belongs to the program and returns the
+// hook that spends the card in the order's transaction.
+func (s *StampService) Redemption(ctx context.Context, customerID int, reward model.PlaceOrderReward) (model.TxHook, error) {
+	matches, err := s.Repo.FindMatches(ctx, []int{reward.MenuItemID})
+	if err != nil {
+		return nil, err
+	}
+
+	covered := false
+	for _, match := range matches {
+		if match.ProgramID == reward.ProgramID {
+			covered = true
+			break
+		}
+	}
+	if !covered {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("menu item %d is not a reward of stamp program %d", reward.MenuItemID, reward.ProgramID))
+	}
+
+	hook := func(ctx context.Context, tx *sqlx.Tx, order *entity.Order) error {
+		return s.Repo.RedeemTx(ctx, tx, reward.ProgramID, customerID, order.ID)
+	}
+
+	return hook, nil
+}
+
+// Earn stamps the customer's cards for a completed order. Free reward items
+// do not earn stamps.
+func (s *StampService) Earn(ctx context.Context, order *entity.Order) error {
+	if order.CustomerID == nil {
+		return nil
+	}
+
+	items, err := s.Orders.FindItems(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	quantities := map[int]int{}
+	ids := []int{}
+	for _, item := range items {
+		if item.StampProgramID != nil {
+			continue
+		}
+		if _, ok := quantities[item.MenuItemID]; !ok {
+			ids = append(ids, item.MenuItemID)
+		}
+		quantities[item.MenuItemID] += item.Quantity
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	matches, err := s.Repo.FindMatches(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	stamps := map[int]int{}
+	for _, match := range matches {
+		stamps[match.ProgramID] += quantities[match.MenuItemID]
+	}
+	if len(stamps) == 0 {
+		return nil
+	}
+
+	return s.Repo.Earn(ctx, *order.CustomerID, order.ID, stamps)
+}
+
+// Restore gives back stamps spent on an order that was cancelled.
+func (s *StampService) Restore(ctx context.Context, order *entity.Order) error {
+	if order.CustomerID == nil {
+		return nil
+	}
+
+	return s.Repo.Restore(ctx, order.ID)
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// StationService manages a store's stations, what each of them makes and
+// the tickets waiting on their screens.
+type StationService struct {
+	Repo     model.StationRepository
+	Orders   model.OrderRepository
+	Audit    model.AuditService
+	Validate *validator.Validate
+	Log      *logrus.Logger
+}
+
+func NewStationService(repo model.StationRepository, orders model.OrderRepository, audit model.AuditService, validate *validator.Validate, log *logrus.Logger) model.StationService {
+	return &StationService{
+		Repo:     repo,
+		Orders:   orders,
+		Audit:    audit,
+		Validate: validate,
+		Log:      log,
+	}
+}
+
+func (s *StationService) Stations(ctx context.Context, auth *model.Auth, storeID int) ([]entity.Station, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repo.FindStations(ctx, storeID)
+}
+
+func (s *StationService) CreateStation(ctx context.Context, auth *model.Auth, storeID int, request *model.CreateStationRequest) (*entity.Station, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid station", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	station := &entity.Station{
+		StoreID:   storeID,
+		Name:      request.Name,
+		IsDefault: request.IsDefault,
+		IsActive:  true,
+	}
+	if err := s.Repo.SaveStation(ctx, station); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, storeID, "station", station.ID, nil, station)
+
+	return station, nil
+}
+
+func (s *StationService) UpdateStation(ctx context.Context, auth *model.Auth, storeID int, id int, request *model.UpdateStationRequest) (*entity.Station, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid station", apperrors.GetValidateMessage(err))
+	}
+
+	station, err := s.storeStation(ctx, auth, storeID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *station
+	if request.Name != "" {
+		station.Name = request.Name
+	}
+	if request.IsDefault != nil {
+		station.IsDefault = *request.IsDefault
+	}
+	if request.IsActive != nil {
+		station.IsActive = *request.IsActive
+	}
+
+	if err := s.Repo.SaveStation(ctx, station); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, station.StoreID, "station", station.ID, &before, station)
+
+	return station, nil
+}
+
+func (s *StationService) Routes(ctx context.Context, auth *model.Auth, storeID int) ([]entity.StationRoute, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repo.FindRoutes(ctx, storeID)
+}
+
+func (s *StationService) AddRoute(ctx context.Context, auth *model.Auth, storeID int, request *model.CreateStationRouteRequest) (*entity.StationRoute, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid station route", apperrors.GetValidateMessage(err))
+	}
+
+	station, err := s.storeStation(ctx, auth, storeID, request.StationID)
+	if err != nil {
+		return nil, err
+	}
+
+	route := &entity.StationRoute{StoreID: station.StoreID, StationID: station.ID}
+	if request.MenuItemID != 0 {
+		route.MenuItemID = &request.MenuItemID
+	} else {
+		route.CategoryID = &request.CategoryID
+	}
+
+	if err := s.Repo.StoreRoute(ctx, route); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, route.StoreID, "station_route", route.ID, nil, route)
+
+	return route, nil
+}
+
+func (s *StationService) RemoveRoute(ctx context.Context, auth *model.Auth, storeID int, id int) error {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return err
+	}
+
+	route, err := s.Repo.FindRoute(ctx, id)
+	if err != nil {
+		return err
+	}
+	if route.StoreID != storeID {
+		return fiber.ErrNotFound
+	}
+
+	if err := s.Repo.RemoveRoute(ctx, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, auth, storeID, "station_route", route.ID, route, nil)
+
+	return nil
+}
+
+// Queue lists the open tickets of a station with only the items it makes.
+func (s *StationService) Queue(ctx context.Context, auth *model.Auth, stationID int) ([]model.StationTicket, error) {
+	station, err := s.Repo.FindStation(ctx, stationID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := auth.ScopeStore(station.StoreID); err != nil {
+		return nil, err
+	}
+
+	tickets, err := s.Repo.FindOpenTickets(ctx, station.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		return tickets, nil
+	}
+
+	ids := make([]int, 0, len(tickets))
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.OrderID)
+	}
+	items, err := s.Orders.FindItemsByOrders(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byOrder := map[int][]model.OrderItemDetail{}
+	for _, item := range items {
+		if item.StationID != nil && *item.StationID == station.ID {
+			byOrder[item.OrderID] = append(byOrder[item.OrderID], item)
+		}
+	}
+	for i := range tickets {
+		tickets[i].Items = byOrder[tickets[i].OrderID]
+		if tickets[i].Items == nil {
+			tickets[i].Items = []model.OrderItemDetail{}
+		}
+	}
+
+	return tickets, nil
+}
+
+func (s *StationService) OrderTickets(ctx context.Context, auth *model.Auth, orderID int) ([]entity.OrderTicket, error) {
+	order, err := s.Orders.FindById(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := auth.ScopeStore(order.StoreID); err != nil {
+		return nil, err
+	}
+
+	return s.Repo.FindTickets(ctx, order.ID)
+}
+
+// storeStation loads a station of the caller's store. Stations of other
+// stores look the same as unknown ones.
+func (s *StationService) storeStation(ctx context.Context, auth *model.Auth, storeID int, id int) (*entity.Station, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	station, err := s.Repo.FindStation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if station.StoreID != storeID {
+		return nil, fiber.ErrNotFound
+	}
+
+	return station, nil
+}
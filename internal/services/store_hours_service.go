@@ -0,0 +1,327 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// upcomingClosureDays is how far ahead the public store page lists closures.
+const upcomingClosureDays = 30
+
+// StoreHoursService answers "is the store open" from the weekly hours, the
+// store's own closures and the public holidays, all in the store's timezone.
+type StoreHoursService struct {
+	Stores   model.StoreRepository
+	Audit    model.AuditService
+	Validate *validator.Validate
+	Log      *logrus.Logger
+	Default  *time.Location
+}
+
+func NewStoreHoursService(stores model.StoreRepository, audit model.AuditService, validate *validator.Validate, viper *viper.Viper, log *logrus.Logger) model.StoreHoursService {
+	return &StoreHoursService{
+		Stores:   stores,
+		Audit:    audit,
+		Validate: validate,
+		Log:      log,
+		Default:  appLocation(viper, log),
+	}
+}
+
+// Location is the store's timezone, or the app timezone when it is unknown.
+func (s *StoreHoursService) Location(store *entity.Store) *time.Location {
+	if store.Timezone == "" {
+		return s.Default
+	}
+
+	location, err := time.LoadLocation(store.Timezone)
+	if err != nil {
+		s.Log.Warnf("store %d has unknown timezone %q: %v", store.ID, store.Timezone, err)
+		return s.Default
+	}
+
+	return location
+}
+
+// DayHours resolves the hours of one day. A closure of the store wins over a
+// public holiday, which wins over the weekly hours.
+func (s *StoreHoursService) DayHours(ctx context.Context, store *entity.Store, day time.Time) (*model.DayHours, error) {
+	date := day.Format(time.DateOnly)
+	hours := &model.DayHours{Date: date, Closed: true}
+
+	closures, err := s.Stores.FindClosures(ctx, store.ID, date, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(closures) > 0 {
+		closure := closures[0]
+		hours.Reason = closure.Reason
+		if closure.OpensAt != "" {
+			hours.OpensAt, hours.ClosesAt, hours.Closed = closure.OpensAt, closure.ClosesAt, false
+		}
+		return hours, nil
+	}
+
+	week, err := s.Stores.FindHours(ctx, store.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, weekday := range week {
+		if weekday.Weekday == int(day.Weekday()) {
+			hours.OpensAt, hours.ClosesAt, hours.Closed = weekday.OpensAt, weekday.ClosesAt, false
+		}
+	}
+
+	return hours, nil
+}
+
+func (s *StoreHoursService) IsOpen(ctx context.Context, store *entity.Store, at time.Time) (bool, error) {
+	if !store.IsActive {
+		return false, nil
+	}
+
+	local := at.In(s.Location(store))
+	hours, err := s.DayHours(ctx, store, local)
+	if err != nil {
+		return false, err
+	}
+	if hours.Closed {
+		return false, nil
+	}
+
+	opens, err := clockMinutes(hours.OpensAt)
+	if err != nil {
+		return false, err
+	}
+	closes, err := clockMinutes(hours.ClosesAt)
+	if err != nil {
+		return false, err
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	return minute >= opens && minute < closes, nil
+}
+
+func (s *StoreHoursService) Status(ctx context.Context, slug string) (*model.StoreStatusResponse, error) {
+	store, err := s.Stores.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !store.IsActive {
+		return nil, fiber.ErrNotFound
+	}
+
+	now := time.Now().In(s.Location(store))
+	open, err := s.IsOpen(ctx, store, now)
+	if err != nil {
+		return nil, err
+	}
+	today, err := s.DayHours(ctx, store, now)
+	if err != nil {
+		return nil, err
+	}
+	week, err := s.Stores.FindHours(ctx, store.ID)
+	if err != nil {
+		return nil, err
+	}
+	closures, err := s.Stores.FindClosures(ctx, store.ID, now.Format(time.DateOnly), now.AddDate(0, 0, upcomingClosureDays).Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StoreStatusResponse{
+		Store:     store,
+		IsOpenNow: open,
+		Today:     today,
+		Week:      week,
+		Closures:  closures,
+	}, nil
+}
+
+func (s *StoreHoursService) Hours(ctx context.Context, auth *model.Auth, storeID int) ([]entity.StoreOpeningHour, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Stores.FindHours(ctx, storeID)
+}
+
+func (s *StoreHoursService) UpdateHours(ctx context.Context, auth *model.Auth, storeID int, request *model.UpdateOpeningHoursRequest) ([]entity.StoreOpeningHour, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid opening hours", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	hours := make([]entity.StoreOpeningHour, 0, len(request.Hours))
+	for _, hour := range request.Hours {
+		if err := checkOpeningTimes(hour.OpensAt, hour.ClosesAt); err != nil {
+			return nil, err
+		}
+		hours = append(hours, entity.StoreOpeningHour{
+			StoreID:  storeID,
+			Weekday:  hour.Weekday,
+			OpensAt:  hour.OpensAt,
+			ClosesAt: hour.ClosesAt,
+		})
+	}
+
+	before, err := s.Stores.FindHours(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Stores.ReplaceHours(ctx, storeID, hours); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, storeID, "opening_hours", storeID, map[string]any{"hours": before}, map[string]any{"hours": hours})
+
+	return hours, nil
+}
+
+func (s *StoreHoursService) UpdateTimezone(ctx context.Context, auth *model.Auth, storeID int, request *model.UpdateTimezoneRequest) (*entity.Store, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid timezone", apperrors.GetValidateMessage(err))
+	}
+	if _, err := time.LoadLocation(request.Timezone); err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("unknown timezone %q", request.Timezone))
+	}
+
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.Stores.FindById(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Stores.UpdateTimezone(ctx, storeID, request.Timezone); err != nil {
+		return nil, err
+	}
+
+	store, err := s.Stores.FindById(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, storeID, "store", storeID, before, store)
+
+	return store, nil
+}
+
+func (s *StoreHoursService) Closures(ctx context.Context, auth *model.Auth, storeID int, request *model.ClosureListRequest) ([]entity.StoreClosure, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid closure filter", apperrors.GetValidateMessage(err))
+	}
+
+	// Holidays are listed through storeID 0, which only admins reach.
+	if storeID != 0 || auth.Role != entity.RoleAdmin {
+		scoped, err := auth.ScopeStore(storeID)
+		if err != nil {
+			return nil, err
+		}
+		storeID = scoped
+	}
+
+	return s.Stores.FindClosures(ctx, storeID, request.From, request.To)
+}
+
+// AddClosure closes a day for one store, or for every store when storeID is
+// 0 and the caller is an admin.
+func (s *StoreHoursService) AddClosure(ctx context.Context, auth *model.Auth, storeID int, request *model.CreateClosureRequest) (*entity.StoreClosure, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid closure", apperrors.GetValidateMessage(err))
+	}
+	if request.OpensAt != "" {
+		if err := checkOpeningTimes(request.OpensAt, request.ClosesAt); err != nil {
+			return nil, err
+		}
+	}
+
+	closure := &entity.StoreClosure{
+		ClosedOn:  request.ClosedOn,
+		OpensAt:   request.OpensAt,
+		ClosesAt:  request.ClosesAt,
+		Reason:    request.Reason,
+		CreatedBy: nullableUser(auth.UserID()),
+	}
+	if storeID != 0 || auth.Role != entity.RoleAdmin {
+		scoped, err := auth.ScopeStore(storeID)
+		if err != nil {
+			return nil, err
+		}
+		closure.StoreID = &scoped
+	}
+
+	if err := s.Stores.StoreClosure(ctx, closure); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, closureStore(closure), "closure", closure.ID, nil, closure)
+
+	return closure, nil
+}
+
+func (s *StoreHoursService) RemoveClosure(ctx context.Context, auth *model.Auth, storeID int, id int) error {
+	closure, err := s.Stores.FindClosure(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case closure.StoreID == nil:
+		if storeID != 0 || auth.Role != entity.RoleAdmin {
+			return fiber.ErrNotFound
+		}
+	default:
+		scoped, err := auth.ScopeStore(storeID)
+		if err != nil {
+			return err
+		}
+		if *closure.StoreID != scoped {
+			return fiber.ErrNotFound
+		}
+	}
+
+	if err := s.Stores.RemoveClosure(ctx, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, auth, closureStore(closure), "closure", closure.ID, closure, nil)
+
+	return nil
+}
+
+// closureStore is the store a closure belongs to, 0 for a holiday.
+func closureStore(closure *entity.StoreClosure) int {
+	if closure.StoreID == nil {
+		return 0
+	}
+	return *closure.StoreID
+}
+
+func checkOpeningTimes(opensAt, closesAt string) error {
+	opens, err := clockMinutes(opensAt)
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	closes, err := clockMinutes(closesAt)
+	if err != nil {
+		return fiber.ErrBadRequest
+	}
+	if closes <= opens {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s to %s closes before it opens", opensAt, closesAt))
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"coffee/internal/utils"
+	"context"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// TimeClockService backs the store tablet. Staff identify with their id and
+// PIN instead of a token.
+type TimeClockService struct {
+	Stores    model.StoreRepository
+	Users     model.UserRepository
+	Schedules model.ScheduleRepository
+	Entries   model.TimeEntryRepository
+	Validate  *validator.Validate
+	Log       *logrus.Logger
+}
+
+func NewTimeClockService(stores model.StoreRepository, users model.UserRepository, schedules model.ScheduleRepository, entries model.TimeEntryRepository, validate *validator.Validate, log *logrus.Logger) model.TimeClockService {
+	return &TimeClockService{
+		Stores:    stores,
+		Users:     users,
+		Schedules: schedules,
+		Entries:   entries,
+		Validate:  validate,
+		Log:       log,
+	}
+}
+
+func (s *TimeClockService) ClockIn(ctx context.Context, storeSlug string, request *model.ClockRequest) (*model.ClockResponse, error) {
+	user, err := s.authenticate(ctx, storeSlug, request)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &entity.TimeEntry{
+		StoreID: user.StoreID,
+		UserID:  user.ID,
+	}
+
+	schedule, err := s.Schedules.FindCurrent(ctx, user.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if schedule != nil {
+		entry.ScheduleID = &schedule.ID
+	}
+
+	if err := s.Entries.ClockIn(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &model.ClockResponse{Entry: entry}, nil
+}
+
+func (s *TimeClockService) ClockOut(ctx context.Context, storeSlug string, request *model.ClockRequest) (*model.ClockResponse, error) {
+	entry, err := s.openEntry(ctx, storeSlug, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Entries.ClockOut(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &model.ClockResponse{Entry: entry}, nil
+}
+
+func (s *TimeClockService) StartBreak(ctx context.Context, storeSlug string, request *model.ClockRequest) (*model.ClockResponse, error) {
+	entry, err := s.openEntry(ctx, storeSlug, request)
+	if err != nil {
+		return nil, err
+	}
+
+	brk, err := s.Entries.StartBreak(ctx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ClockResponse{Entry: entry, Break: brk}, nil
+}
+
+func (s *TimeClockService) EndBreak(ctx context.Context, storeSlug string, request *model.ClockRequest) (*model.ClockResponse, error) {
+	entry, err := s.openEntry(ctx, storeSlug, request)
+	if err != nil {
+		return nil, err
+	}
+
+	brk, err := s.Entries.EndBreak(ctx, entry.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ClockResponse{Entry: entry, Break: brk}, nil
+}
+
+func (s *TimeClockService) openEntry(ctx context.Context, storeSlug string, request *model.ClockRequest) (*entity.TimeEntry, error) {
+	user, err := s.authenticate(ctx, storeSlug, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Entries.FindOpen(ctx, user.ID)
+}
+
+// authenticate checks the PIN of a staff member of the store. Every failure
+// looks the same so the tablet can not be used to probe for valid ids.
+func (s *TimeClockService) authenticate(ctx context.Context, storeSlug string, request *model.ClockRequest) (*entity.User, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid clock request", apperrors.GetValidateMessage(err))
+	}
+
+	invalid := fiber.NewError(fiber.StatusUnauthorized, "invalid staff id or pin")
+
+	store, err := s.Stores.FindBySlug(ctx, storeSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.Users.FindById(ctx, request.UserID)
+	if err != nil {
+		return nil, invalid
+	}
+
+	if !user.IsActive || user.StoreID != store.ID || len(user.PinHash) == 0 {
+		return nil, invalid
+	}
+
+	if err := utils.ValidatePassword(request.Pin, string(user.PinHash)); err != nil {
+		return nil, invalid
+	}
+
+	return user, nil
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"coffee/internal/entity"
+	"coffee/internal/model"
+	"coffee/internal/model/apperrors"
+	"coffee/internal/utils"
+	"context"
+	"encoding/json"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultWebhookDeliveryLimit = 50
+
+// WebhookService manages a store's webhook subscriptions and records the
+// deliveries of its events. Sending is left to the webhook dispatcher.
+type WebhookService struct {
+	Repo     model.WebhookRepository
+	Audit    model.AuditService
+	Validate *validator.Validate
+	Log      *logrus.Logger
+}
+
+func NewWebhookService(repo model.WebhookRepository, audit model.AuditService, validate *validator.Validate, log *logrus.Logger) model.WebhookService {
+	return &WebhookService{
+		Repo:     repo,
+		Audit:    audit,
+		Validate: validate,
+		Log:      log,
+	}
+}
+
+func (s *WebhookService) Subscriptions(ctx context.Context, auth *model.Auth, storeID int) ([]entity.WebhookSubscription, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := s.Repo.FindSubscriptions(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+
+	return subscriptions, nil
+}
+
+// CreateSubscription returns the signing secret; it is not shown again.
+func (s *WebhookService) CreateSubscription(ctx context.Context, auth *model.Auth, storeID int, request *model.CreateWebhookRequest) (*entity.WebhookSubscription, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid webhook", apperrors.GetValidateMessage(err))
+	}
+
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.RandomToken(32)
+	if err != nil {
+		s.Log.Warn(err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	subscription := &entity.WebhookSubscription{
+		StoreID:  storeID,
+		URL:      request.URL,
+		Secret:   secret,
+		Events:   request.Events,
+		IsActive: true,
+	}
+	if err := s.Repo.SaveSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, storeID, "webhook", subscription.ID, nil, subscription)
+
+	return subscription, nil
+}
+
+// UpdateSubscription returns the new secret when it was rotated.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, auth *model.Auth, storeID int, id int, request *model.UpdateWebhookRequest) (*entity.WebhookSubscription, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid webhook", apperrors.GetValidateMessage(err))
+	}
+
+	subscription, err := s.storeSubscription(ctx, auth, storeID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *subscription
+	if request.URL != "" {
+		subscription.URL = request.URL
+	}
+	if len(request.Events) > 0 {
+		subscription.Events = request.Events
+	}
+	if request.IsActive != nil {
+		subscription.IsActive = *request.IsActive
+	}
+	if request.RotateSecret {
+		if subscription.Secret, err = utils.RandomToken(32); err != nil {
+			s.Log.Warn(err)
+			return nil, fiber.ErrInternalServerError
+		}
+	}
+
+	if err := s.Repo.SaveSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	s.Audit.Record(ctx, auth, subscription.StoreID, "webhook", subscription.ID, &before, subscription)
+	if !request.RotateSecret {
+		subscription.Secret = ""
+	}
+
+	return subscription, nil
+}
+
+func (s *WebhookService) RemoveSubscription(ctx context.Context, auth *model.Auth, storeID int, id int) error {
+	subscription, err := s.storeSubscription(ctx, auth, storeID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Repo.RemoveSubscription(ctx, id); err != nil {
+		return err
+	}
+	s.Audit.Record(ctx, auth, subscription.StoreID, "webhook", subscription.ID, subscription, nil)
+
+	return nil
+}
+
+func (s *WebhookService) Deliveries(ctx context.Context, auth *model.Auth, storeID int, id int, request *model.WebhookDeliveryListRequest) ([]entity.WebhookDelivery, error) {
+	if err := s.Validate.Struct(request); err != nil {
+		return nil, apperrors.NewBadRequest("invalid delivery filter", apperrors.GetValidateMessage(err))
+	}
+
+	if _, err := s.storeSubscription(ctx, auth, storeID, id); err != nil {
+		return nil, err
+	}
+
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultWebhookDeliveryLimit
+	}
+
+	return s.Repo.FindDeliveries(ctx, id, request.Status, limit)
+}
+
+// Replay sends a delivery's payload again as a new delivery, whatever became
+// of the original. The event id stays the same so receivers can dedupe.
+func (s *WebhookService) Replay(ctx context.Context, auth *model.Auth, deliveryID int) (*entity.WebhookDelivery, error) {
+	original, err := s.Repo.FindDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	subscription, err := s.Repo.FindSubscription(ctx, original.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := auth.ScopeStore(subscription.StoreID); err != nil {
+		return nil, err
+	}
+	if !subscription.IsActive {
+		return nil, fiber.NewError(fiber.StatusConflict, "webhook is not active")
+	}
+
+	deliveries := []entity.WebhookDelivery{{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		ReplayOf:       &original.ID,
+	}}
+	if err := s.Repo.StoreDeliveries(ctx, deliveries); err != nil {
+		return nil, err
+	}
+
+	return &deliveries[0], nil
+}
+
+// Publish records a delivery of the event for every subscriber of the store.
+// An event delivered to it again is not recorded twice.
+func (s *WebhookService) Publish(ctx context.Context, event *model.Event) error {
+	subscriptions, err := s.Repo.FindSubscribers(ctx, event.StoreID, event.Name())
+	if err != nil || len(subscriptions) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(model.WebhookEvent{
+		ID:        event.ID,
+		Event:     event.Name(),
+		StoreID:   event.StoreID,
+		CreatedAt: event.OccurredAt,
+		Data:      event.Data,
+	})
+	if err != nil {
+		s.Log.Warn(err)
+		return fiber.ErrInternalServerError
+	}
+
+	deliveries := make([]entity.WebhookDelivery, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			Event:          event.Name(),
+			Payload:        payload,
+		})
+	}
+
+	return s.Repo.StoreDeliveries(ctx, deliveries)
+}
+
+func (s *WebhookService) storeSubscription(ctx context.Context, auth *model.Auth, storeID int, id int) (*entity.WebhookSubscription, error) {
+	storeID, err := auth.ScopeStore(storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.Repo.FindSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.StoreID != storeID {
+		return nil, fiber.ErrNotFound
+	}
+
+	return subscription, nil
+}
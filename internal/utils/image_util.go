@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"image"
+	"image/draw"
+)
+
+// ResizeToWidth scales an image down to the given width, keeping its aspect
+// ratio. Every target pixel is the average of the source pixels it covers,
+// which keeps thumbnails of photos smooth. Images already that narrow come
+// back as they are.
+func ResizeToWidth(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	if width <= 0 || width >= bounds.Dx() {
+		return src
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	// Averaging needs premultiplied colors, or transparent pixels bleed.
+	in := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(in, in.Bounds(), src, bounds.Min, draw.Src)
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	sw, sh := in.Bounds().Dx(), in.Bounds().Dy()
+	for y := 0; y < height; y++ {
+		y0, y1 := y*sh/height, (y+1)*sh/height
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0, x1 := x*sw/width, (x+1)*sw/width
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := in.Pix[sy*in.Stride+x0*4 : sy*in.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += uint64(row[i])
+					g += uint64(row[i+1])
+					b += uint64(row[i+2])
+					a += uint64(row[i+3])
+					n++
+				}
+			}
+
+			at := y*out.Stride + x*4
+			out.Pix[at] = uint8(r / n)
+			out.Pix[at+1] = uint8(g / n)
+			out.Pix[at+2] = uint8(b / n)
+			out.Pix[at+3] = uint8(a / n)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,26 @@
+package utils
+
+import "strings"
+
+// NormalizePhone formats Indonesian phone numbers as +62..., so 0812-3456-789,
+// 62812 3456 789 and +62 812 3456 789 are the same customer.
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	number := digits.String()
+
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(phone), "+"):
+		return "+" + number
+	case strings.HasPrefix(number, "0"):
+		return "+62" + number[1:]
+	case strings.HasPrefix(number, "62"):
+		return "+" + number
+	default:
+		return "+62" + number
+	}
+}
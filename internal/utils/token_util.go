@@ -3,6 +3,8 @@ package utils
 import (
 	"coffee/internal/model"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -19,6 +21,7 @@ type TokenUtil struct {
 type TokenClaims struct {
 	Id string
 	Role string
+	StoreID int
 	jwt.RegisteredClaims
 }
 
@@ -33,6 +36,7 @@ func (t *TokenUtil) CreateToken(ctx context.Context, auth model.Auth) (string, e
 	claims := TokenClaims{
 		Id: auth.Id,
 		Role: auth.Role,
+		StoreID: auth.StoreID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour*24*30)),
 		},
@@ -70,7 +74,18 @@ func (t *TokenUtil) ParseToken(ctx context.Context, tokenString string) (*model.
 	return &model.Auth{
 		Id: claims.Id,
 		Role: claims.Role,
+		StoreID: claims.StoreID,
 	}, nil
 }
 
 
+
+// RandomToken returns n random bytes, hex encoded.
+func RandomToken(n int) (string, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,13 @@
+-- Order status transitions, one row per change. The initial 'pending' status
+-- is orders.created_at.
+CREATE TABLE IF NOT EXISTS order_status_histories (
+    id             SERIAL PRIMARY KEY,
+    order_id       INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status    VARCHAR(20) NOT NULL,
+    to_status      VARCHAR(20) NOT NULL,
+    changed_by     INT REFERENCES users(id) ON DELETE SET NULL,
+    changed_at     TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_histories_order ON order_status_histories(order_id, changed_at);
+CREATE INDEX IF NOT EXISTS idx_order_status_histories_user ON order_status_histories(changed_by, changed_at);
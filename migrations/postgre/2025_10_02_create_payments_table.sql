@@ -0,0 +1,14 @@
+-- Payments taken against an order. An order may be split over several payments.
+CREATE TABLE IF NOT EXISTS payments (
+    id             SERIAL PRIMARY KEY,
+    order_id       INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    method         VARCHAR(20) NOT NULL CHECK (method IN ('cash', 'card', 'qris')),
+    amount         DECIMAL(12,0) NOT NULL CHECK (amount > 0),
+    status         VARCHAR(20) NOT NULL DEFAULT 'captured'
+                 CHECK (status IN ('captured', 'refunded')),
+    reference      VARCHAR(100),
+    captured_at    TIMESTAMPTZ DEFAULT NOW(),
+    created_at     TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_order ON payments(order_id);
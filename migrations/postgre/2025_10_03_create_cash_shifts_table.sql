@@ -0,0 +1,38 @@
+-- Till shifts. A store has at most one open shift, every cash payment taken
+-- while it is open posts to it.
+CREATE TABLE IF NOT EXISTS cash_shifts (
+    id               SERIAL PRIMARY KEY,
+    store_id         INT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    opened_by        INT NOT NULL REFERENCES users(id),
+    closed_by        INT REFERENCES users(id),
+    status           VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    opening_float    DECIMAL(12,0) NOT NULL DEFAULT 0 CHECK (opening_float >= 0),
+    expected_amount  DECIMAL(12,0),
+    counted_amount   DECIMAL(12,0),
+    variance         DECIMAL(12,0),
+    note             TEXT,
+    opened_at        TIMESTAMPTZ DEFAULT NOW(),
+    closed_at        TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cash_shifts_open ON cash_shifts(store_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_cash_shifts_store ON cash_shifts(store_id, opened_at);
+
+-- Cash put into (pay_in) or taken out of (pay_out) the drawer outside of sales.
+CREATE TABLE IF NOT EXISTS cash_movements (
+    id           SERIAL PRIMARY KEY,
+    shift_id     INT NOT NULL REFERENCES cash_shifts(id) ON DELETE CASCADE,
+    type         VARCHAR(10) NOT NULL CHECK (type IN ('pay_in', 'pay_out')),
+    amount       DECIMAL(12,0) NOT NULL CHECK (amount > 0),
+    reason       VARCHAR(255) NOT NULL,
+    created_by   INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cash_movements_shift ON cash_movements(shift_id);
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS shift_id INT REFERENCES cash_shifts(id);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS received_by INT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS tendered DECIMAL(12,0);
+
+CREATE INDEX IF NOT EXISTS idx_payments_shift ON payments(shift_id);
@@ -0,0 +1,39 @@
+-- Planned working hours per staff member.
+CREATE TABLE IF NOT EXISTS staff_schedules (
+    id           SERIAL PRIMARY KEY,
+    store_id     INT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    user_id      INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at    TIMESTAMPTZ NOT NULL,
+    ends_at      TIMESTAMPTZ NOT NULL,
+    note         TEXT,
+    created_by   INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_schedules_store ON staff_schedules(store_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_staff_schedules_user ON staff_schedules(user_id, starts_at);
+
+-- Actual clock-in/clock-out, made with the staff PIN on the store tablet.
+CREATE TABLE IF NOT EXISTS time_entries (
+    id             SERIAL PRIMARY KEY,
+    store_id       INT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    user_id        INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    schedule_id    INT REFERENCES staff_schedules(id) ON DELETE SET NULL,
+    clock_in_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    clock_out_at   TIMESTAMPTZ,
+    CHECK (clock_out_at IS NULL OR clock_out_at >= clock_in_at)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_time_entries_open ON time_entries(user_id) WHERE clock_out_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_time_entries_store ON time_entries(store_id, clock_in_at);
+
+CREATE TABLE IF NOT EXISTS time_entry_breaks (
+    id              SERIAL PRIMARY KEY,
+    time_entry_id   INT NOT NULL REFERENCES time_entries(id) ON DELETE CASCADE,
+    started_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ended_at        TIMESTAMPTZ,
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_time_entry_breaks_open ON time_entry_breaks(time_entry_id) WHERE ended_at IS NULL;
@@ -0,0 +1,40 @@
+-- Customers are identified by phone number (stored as +62...).
+CREATE TABLE IF NOT EXISTS customers (
+    id               SERIAL PRIMARY KEY,
+    phone            VARCHAR(20) NOT NULL UNIQUE,
+    full_name        VARCHAR(100),
+    email            VARCHAR(100),
+    tier             VARCHAR(20) NOT NULL DEFAULT 'bronze',
+    points_balance   INT NOT NULL DEFAULT 0 CHECK (points_balance >= 0),
+    lifetime_spend   DECIMAL(14,0) NOT NULL DEFAULT 0,
+    created_at       TIMESTAMPTZ DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Every point movement. Earned rows keep what is left of them in remaining so
+-- redemptions and expiry consume the oldest points first.
+CREATE TABLE IF NOT EXISTS loyalty_ledger (
+    id            SERIAL PRIMARY KEY,
+    customer_id   INT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    order_id      INT REFERENCES orders(id) ON DELETE SET NULL,
+    type          VARCHAR(10) NOT NULL CHECK (type IN ('earn', 'redeem', 'expire', 'refund')),
+    points        INT NOT NULL,
+    remaining     INT NOT NULL DEFAULT 0 CHECK (remaining >= 0),
+    expires_at    TIMESTAMPTZ,
+    note          VARCHAR(255),
+    created_at    TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_customer ON loyalty_ledger(customer_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_open ON loyalty_ledger(customer_id, expires_at) WHERE remaining > 0;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loyalty_ledger_earn ON loyalty_ledger(order_id) WHERE type = 'earn';
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_id INT REFERENCES customers(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_name VARCHAR(100);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal DECIMAL(12,0) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_total DECIMAL(12,0) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS points_redeemed INT NOT NULL DEFAULT 0;
+
+UPDATE orders SET subtotal = total WHERE subtotal = 0;
+
+CREATE INDEX IF NOT EXISTS idx_orders_customer ON orders(customer_id);
@@ -0,0 +1,42 @@
+-- "Buy N, get one free" programs, on either a whole category or one menu item.
+CREATE TABLE IF NOT EXISTS stamp_programs (
+    id                SERIAL PRIMARY KEY,
+    name              VARCHAR(100) NOT NULL,
+    category_id       INT REFERENCES categories(id) ON DELETE CASCADE,
+    menu_item_id      INT REFERENCES menu_items(id) ON DELETE CASCADE,
+    stamps_required   INT NOT NULL CHECK (stamps_required > 0),
+    is_active         BOOLEAN NOT NULL DEFAULT true,
+    created_at        TIMESTAMPTZ DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ DEFAULT NOW(),
+    CHECK ((category_id IS NULL) <> (menu_item_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_stamp_programs_category ON stamp_programs(category_id) WHERE is_active;
+CREATE INDEX IF NOT EXISTS idx_stamp_programs_menu_item ON stamp_programs(menu_item_id) WHERE is_active;
+
+CREATE TABLE IF NOT EXISTS stamp_cards (
+    program_id         INT NOT NULL REFERENCES stamp_programs(id) ON DELETE CASCADE,
+    customer_id        INT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    stamps             INT NOT NULL DEFAULT 0 CHECK (stamps >= 0),
+    rewards_redeemed   INT NOT NULL DEFAULT 0,
+    updated_at         TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (program_id, customer_id)
+);
+
+-- Every stamp movement. One of each type per program and order keeps
+-- crediting and refunding idempotent.
+CREATE TABLE IF NOT EXISTS stamp_events (
+    id            SERIAL PRIMARY KEY,
+    program_id    INT NOT NULL REFERENCES stamp_programs(id) ON DELETE CASCADE,
+    customer_id   INT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    order_id      INT REFERENCES orders(id) ON DELETE SET NULL,
+    type          VARCHAR(10) NOT NULL CHECK (type IN ('earn', 'redeem', 'refund')),
+    stamps        INT NOT NULL,
+    created_at    TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (program_id, order_id, type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stamp_events_customer ON stamp_events(customer_id, created_at);
+
+-- Reward lines are zero-priced and point at the program that paid for them.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS stamp_program_id INT REFERENCES stamp_programs(id) ON DELETE SET NULL;
@@ -0,0 +1,13 @@
+-- Tables that carry a QR code. The code encodes the store slug and the
+-- table number, e.g. /order/kopi-senopati/12.
+CREATE TABLE IF NOT EXISTS store_tables (
+    id             SERIAL PRIMARY KEY,
+    store_id       INT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    table_number   VARCHAR(10) NOT NULL,
+    label          VARCHAR(50),
+    is_active      BOOLEAN NOT NULL DEFAULT true,
+    created_at     TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (store_id, table_number)
+);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS table_number VARCHAR(10);
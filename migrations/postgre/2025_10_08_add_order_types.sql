@@ -0,0 +1,12 @@
+-- How an order is handed over. Each type has its own required field:
+-- dine_in a table, pickup a pickup time, delivery an address.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS order_type VARCHAR(20) NOT NULL DEFAULT 'takeaway'
+    CHECK (order_type IN ('dine_in', 'takeaway', 'delivery', 'pickup'));
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS pickup_at TIMESTAMPTZ;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_address TEXT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS fee_total DECIMAL(12,0) NOT NULL DEFAULT 0;
+
+UPDATE orders SET order_type = 'dine_in' WHERE table_number IS NOT NULL AND order_type = 'takeaway';
+
+CREATE INDEX IF NOT EXISTS idx_orders_queue ON orders(store_id, order_type, created_at)
+    WHERE status IN ('pending', 'preparing', 'ready');
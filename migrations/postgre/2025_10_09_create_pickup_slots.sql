@@ -0,0 +1,16 @@
+-- Pre-order pickup slots. Stores without a row use the defaults in config.
+CREATE TABLE IF NOT EXISTS store_pickup_settings (
+    store_id            INT PRIMARY KEY REFERENCES stores(id) ON DELETE CASCADE,
+    slot_minutes        INT NOT NULL CHECK (slot_minutes BETWEEN 5 AND 120),
+    capacity_per_slot   INT NOT NULL CHECK (capacity_per_slot >= 0),
+    release_minutes     INT NOT NULL CHECK (release_minutes >= 0),
+    updated_at          TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Pre-orders wait as scheduled until they are released to the barista queue.
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('scheduled', 'pending', 'preparing', 'ready', 'completed', 'cancelled'));
+
+CREATE INDEX IF NOT EXISTS idx_orders_pickup ON orders(store_id, pickup_at) WHERE pickup_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_orders_scheduled ON orders(pickup_at) WHERE status = 'scheduled';
@@ -0,0 +1,32 @@
+-- Opening hours and closures are in the store's own timezone.
+ALTER TABLE stores ADD COLUMN IF NOT EXISTS timezone VARCHAR(50) NOT NULL DEFAULT 'Asia/Jakarta';
+
+-- Weekly opening hours in store-local time. weekday follows Go and
+-- Postgres: 0 is Sunday.
+CREATE TABLE IF NOT EXISTS store_opening_hours (
+    id          SERIAL PRIMARY KEY,
+    store_id    INT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    weekday     SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    opens_at    TIME NOT NULL,
+    closes_at   TIME NOT NULL,
+    UNIQUE (store_id, weekday),
+    CHECK (closes_at > opens_at)
+);
+
+-- One-off days that differ from the weekly hours: closed all day when the
+-- times are empty, special hours otherwise. Rows without a store are public
+-- holidays for every store; a store's own row wins over them.
+CREATE TABLE IF NOT EXISTS store_closures (
+    id           SERIAL PRIMARY KEY,
+    store_id     INT REFERENCES stores(id) ON DELETE CASCADE,
+    closed_on    DATE NOT NULL,
+    opens_at     TIME,
+    closes_at    TIME,
+    reason       VARCHAR(100) NOT NULL,
+    created_by   INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ DEFAULT NOW(),
+    CHECK ((opens_at IS NULL) = (closes_at IS NULL)),
+    CHECK (closes_at IS NULL OR closes_at > opens_at)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_store_closures_day ON store_closures(COALESCE(store_id, 0), closed_on);
@@ -0,0 +1,22 @@
+-- When a store sells an item or a whole category, e.g. breakfast until
+-- 11:00 or a seasonal drink in December. Items and categories without
+-- windows are always on; with windows they are on while any window matches.
+-- Times and dates are in the store's timezone.
+CREATE TABLE IF NOT EXISTS menu_availability_windows (
+    id             SERIAL PRIMARY KEY,
+    store_id       INT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    menu_item_id   INT REFERENCES menu_items(id) ON DELETE CASCADE,
+    category_id    INT REFERENCES categories(id) ON DELETE CASCADE,
+    weekday_mask   SMALLINT NOT NULL DEFAULT 0 CHECK (weekday_mask BETWEEN 0 AND 127), -- bit 0 is Sunday, 0 is every day
+    starts_at      TIME,
+    ends_at        TIME,
+    valid_from     DATE,
+    valid_until    DATE,
+    created_at     TIMESTAMPTZ DEFAULT NOW(),
+    CHECK ((menu_item_id IS NULL) <> (category_id IS NULL)),
+    CHECK ((starts_at IS NULL) = (ends_at IS NULL)),
+    CHECK (ends_at IS NULL OR ends_at > starts_at),
+    CHECK (valid_until IS NULL OR valid_from IS NULL OR valid_until >= valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_menu_availability_store ON menu_availability_windows(store_id);
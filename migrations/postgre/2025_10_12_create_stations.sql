@@ -0,0 +1,45 @@
+-- Where items are made in a store, e.g. the espresso bar and the food counter.
+CREATE TABLE IF NOT EXISTS stations (
+    id           SERIAL PRIMARY KEY,
+    store_id     INT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    name         VARCHAR(50) NOT NULL,
+    is_default   BOOLEAN NOT NULL DEFAULT false, -- takes items nothing routes elsewhere
+    is_active    BOOLEAN NOT NULL DEFAULT true,
+    created_at   TIMESTAMPTZ DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (store_id, name)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stations_default ON stations(store_id) WHERE is_default;
+
+-- Sends a menu item or a whole category to a station. A route for the item
+-- wins over a route for its category.
+CREATE TABLE IF NOT EXISTS station_routes (
+    id             SERIAL PRIMARY KEY,
+    store_id       INT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    station_id     INT NOT NULL REFERENCES stations(id) ON DELETE CASCADE,
+    menu_item_id   INT REFERENCES menu_items(id) ON DELETE CASCADE,
+    category_id    INT REFERENCES categories(id) ON DELETE CASCADE,
+    created_at     TIMESTAMPTZ DEFAULT NOW(),
+    CHECK ((menu_item_id IS NULL) <> (category_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_station_routes_item ON station_routes(store_id, menu_item_id) WHERE menu_item_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_station_routes_category ON station_routes(store_id, category_id) WHERE category_id IS NOT NULL;
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS station_id INT REFERENCES stations(id) ON DELETE SET NULL;
+
+-- One ticket per station an order has items at. The order is ready once
+-- every ticket is done.
+CREATE TABLE IF NOT EXISTS order_tickets (
+    id           SERIAL PRIMARY KEY,
+    order_id     INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    station_id   INT NOT NULL REFERENCES stations(id) ON DELETE CASCADE,
+    status       VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'done')),
+    bumped_by    INT REFERENCES users(id) ON DELETE SET NULL,
+    bumped_at    TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (order_id, station_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_tickets_station ON order_tickets(station_id) WHERE status = 'open';
@@ -0,0 +1,35 @@
+-- Network thermal printers, reached over raw TCP (usually port 9100). A
+-- printer prints the tickets of its station, customer receipts, or both.
+CREATE TABLE IF NOT EXISTS printers (
+    id                SERIAL PRIMARY KEY,
+    store_id          INT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    name              VARCHAR(50) NOT NULL,
+    host              VARCHAR(255) NOT NULL,
+    port              INT NOT NULL DEFAULT 9100 CHECK (port BETWEEN 1 AND 65535),
+    paper_width       SMALLINT NOT NULL DEFAULT 80 CHECK (paper_width IN (58, 80)), -- mm
+    station_id        INT REFERENCES stations(id) ON DELETE SET NULL,
+    prints_receipts   BOOLEAN NOT NULL DEFAULT false,
+    is_active         BOOLEAN NOT NULL DEFAULT true,
+    created_at        TIMESTAMPTZ DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (store_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS print_jobs (
+    id            SERIAL PRIMARY KEY,
+    printer_id    INT NOT NULL REFERENCES printers(id) ON DELETE CASCADE,
+    order_id      INT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    ticket_id     INT REFERENCES order_tickets(id) ON DELETE CASCADE, -- ticket jobs only
+    kind          VARCHAR(10) NOT NULL CHECK (kind IN ('receipt', 'ticket')),
+    status        VARCHAR(10) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'printing', 'printed', 'failed')),
+    attempts      INT NOT NULL DEFAULT 0,
+    last_error    TEXT,
+    created_by    INT REFERENCES users(id) ON DELETE SET NULL, -- reprints
+    created_at    TIMESTAMPTZ DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ DEFAULT NOW(),
+    printed_at    TIMESTAMPTZ,
+    CHECK ((kind = 'ticket') = (ticket_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_print_jobs_printer ON print_jobs(printer_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_print_jobs_order ON print_jobs(order_id);
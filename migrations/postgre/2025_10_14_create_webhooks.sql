@@ -0,0 +1,37 @@
+-- Partners that want to hear about a store's orders, e.g. delivery or
+-- accounting. Payloads are signed with the subscription's secret.
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id           SERIAL PRIMARY KEY,
+    store_id     INT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    url          VARCHAR(500) NOT NULL,
+    secret       VARCHAR(100) NOT NULL,
+    events       TEXT[] NOT NULL,
+    is_active    BOOLEAN NOT NULL DEFAULT true,
+    created_at   TIMESTAMPTZ DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_store ON webhook_subscriptions(store_id) WHERE is_active;
+
+-- One row per event per subscription, doubling as the delivery log. Pending
+-- rows are retried until next_attempt_at passes and attempts run out.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id                SERIAL PRIMARY KEY,
+    subscription_id   INT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id          VARCHAR(64) NOT NULL,
+    event             VARCHAR(50) NOT NULL,
+    payload           JSONB NOT NULL,
+    status            VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts          INT NOT NULL DEFAULT 0,
+    response_status   INT,
+    response_body     TEXT,
+    last_error        TEXT,
+    next_attempt_at   TIMESTAMPTZ DEFAULT NOW(),
+    replay_of         INT REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+    created_at        TIMESTAMPTZ DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ DEFAULT NOW(),
+    delivered_at      TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at);
@@ -0,0 +1,17 @@
+-- Events written in the same transaction as the change they describe, so a
+-- crash after the commit can not lose them. The relay copies them to a Redis
+-- stream in id order and stamps sent_at.
+CREATE TABLE IF NOT EXISTS outbox (
+    id           BIGSERIAL PRIMARY KEY,
+    event        VARCHAR(50) NOT NULL,
+    store_id     INT NOT NULL,
+    order_id     INT NOT NULL,
+    payload      JSONB NOT NULL,
+    attempts     INT NOT NULL DEFAULT 0,
+    last_error   TEXT,
+    created_at   TIMESTAMPTZ DEFAULT NOW(),
+    sent_at      TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(id) WHERE sent_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_sent ON outbox(sent_at) WHERE sent_at IS NOT NULL;
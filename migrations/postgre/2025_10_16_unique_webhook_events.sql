@@ -0,0 +1,4 @@
+-- Events reach the webhook service at least once; a repeat must not be sent
+-- to the subscriber twice. Replays are deliberate repeats.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_event
+    ON webhook_deliveries(subscription_id, event_id) WHERE replay_of IS NULL;
@@ -0,0 +1,30 @@
+-- Price history of menu items. From effective_from on, an item costs price
+-- at one store, or with no store_id at every store without an override of
+-- its own. A store row without a price ends the store's override. Rows in
+-- the future are scheduled changes; menu_items.base_price and
+-- store_menu.price_override follow the history as the rows come due.
+CREATE TABLE IF NOT EXISTS menu_item_prices (
+    id              SERIAL PRIMARY KEY,
+    menu_item_id    INT NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    store_id        INT REFERENCES stores(id) ON DELETE CASCADE,
+    price           DECIMAL(12,0) CHECK (price >= 0),
+    effective_from  TIMESTAMPTZ NOT NULL,
+    created_by      INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ DEFAULT NOW(),
+    CHECK (store_id IS NOT NULL OR price IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_menu_item_prices_unique
+    ON menu_item_prices(menu_item_id, COALESCE(store_id, 0), effective_from);
+CREATE INDEX IF NOT EXISTS idx_menu_item_prices_lookup
+    ON menu_item_prices(menu_item_id, store_id, effective_from DESC);
+
+-- What we know of the past: today's prices, since the rows were created.
+INSERT INTO menu_item_prices (menu_item_id, price, effective_from)
+SELECT id, base_price, COALESCE(created_at, NOW()) FROM menu_items
+ON CONFLICT DO NOTHING;
+
+INSERT INTO menu_item_prices (menu_item_id, store_id, price, effective_from)
+SELECT menu_item_id, store_id, price_override, COALESCE(created_at, NOW()) FROM store_menu
+WHERE price_override IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,25 @@
+-- Categories nest: Coffee > Espresso Based > Milk. A category with
+-- subcategories cannot be deleted.
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES categories(id);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
+
+-- Categories are told apart by name among their siblings, also by the
+-- catalog import, so Hot > Tea and Iced > Tea may both exist. Siblings that
+-- already share a name keep the oldest one as is; the others get their id
+-- appended. Names used to be unique across the whole tree.
+DROP INDEX IF EXISTS idx_categories_name;
+
+UPDATE categories c SET name = LEFT(c.name, 40) || ' (' || c.id || ')'
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY COALESCE(parent_id, 0), LOWER(name) ORDER BY id) AS n
+    FROM categories
+) siblings
+WHERE siblings.id = c.id AND siblings.n > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_sibling_name ON categories(COALESCE(parent_id, 0), LOWER(name));
+
+-- Free-form tags such as vegan or new, and the allergens a menu item
+-- contains, for filtering the menu.
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE menu_items ADD COLUMN IF NOT EXISTS allergens TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,6 @@
+-- Order events reach the printer service at least once; a repeat must not
+-- print the same receipt or ticket twice. Reprints are deliberate repeats.
+ALTER TABLE print_jobs ADD COLUMN IF NOT EXISTS reprint_of INT REFERENCES print_jobs(id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_print_jobs_once
+    ON print_jobs(printer_id, order_id, kind, COALESCE(ticket_id, 0)) WHERE reprint_of IS NULL;
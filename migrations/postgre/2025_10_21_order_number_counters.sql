@@ -0,0 +1,17 @@
+-- Order numbers count up per store per day, the day taken in the store's
+-- timezone. The counter row is locked by the order that takes a number, so
+-- two orders never draw the same one.
+CREATE TABLE IF NOT EXISTS store_order_counters (
+    store_id     INT NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    day          DATE NOT NULL,
+    last_number  INT NOT NULL,
+    PRIMARY KEY (store_id, day)
+);
+
+-- Carry on from the numbers already handed out.
+INSERT INTO store_order_counters (store_id, day, last_number)
+SELECT store_id, to_date(split_part(order_number, '-', 2), 'YYMMDD'), MAX(split_part(order_number, '-', 3)::int)
+FROM orders
+WHERE order_number ~ '^S[0-9]+-[0-9]{6}-[0-9]+$'
+GROUP BY 1, 2
+ON CONFLICT (store_id, day) DO UPDATE SET last_number = GREATEST(store_order_counters.last_number, EXCLUDED.last_number);